@@ -2,35 +2,100 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"flag"
 	"fmt"
 	"job-portal-api/internal/auth"
-	"job-portal-api/internal/database"
+	"job-portal-api/internal/config"
 	"job-portal-api/internal/handlers"
-
+	"job-portal-api/internal/health"
+	"job-portal-api/internal/mail"
+	"job-portal-api/internal/metrics"
+	"job-portal-api/internal/migrate"
+	"job-portal-api/internal/oidc"
+	"job-portal-api/internal/repository"
 	"job-portal-api/internal/services"
+	"job-portal-api/internal/tracing"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"time"
 )
 
 func main() {
-	err := startApp()
+	err := run()
 	if err != nil {
 		log.Panic().Err(err).Send()
 	}
 	log.Info().Msg("hello this is our app")
 }
-func startApp() error {
+
+// run loads the configuration and runs the command given on the command line. Without a command it starts the
+// API server.
+func run() error {
+	configFile := flag.String("config", os.Getenv(config.EnvPrefix+"CONFIG_FILE"), "path to a YAML or TOML config file")
+	flag.Usage = usage
+	flag.Parse()
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		return err
+	}
+	zerolog.SetGlobalLevel(cfg.LogLevel())
+
+	args := flag.Args()
+	if len(args) == 0 {
+		return startApp(cfg)
+	}
+	switch args[0] {
+	case "migrate":
+		return runMigrate(cfg, args[1:])
+	default:
+		flag.Usage()
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] [command]\n\n", filepath.Base(os.Args[0]))
+	fmt.Fprintln(out, "Commands:")
+	fmt.Fprintln(out, "  (none)             start the API server")
+	fmt.Fprintln(out, "  migrate up         apply all pending migrations")
+	fmt.Fprintln(out, "  migrate down [n]   revert the last n migrations, 1 by default")
+	fmt.Fprintln(out, "  migrate status     list migrations and when they were applied")
+	fmt.Fprintln(out, "\nFlags:")
+	flag.PrintDefaults()
+}
+
+func startApp(cfg config.Config) error {
+
+	// =========================================================================
+	// Initialize tracing support
+	log.Info().Str("exporter", cfg.Tracing.Exporter).Msg("main : Started : Initializing tracing support")
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("setting up tracing %w", err)
+	}
+	defer func() {
+		// Flush the spans still buffered, without holding up the shutdown for long
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := shutdownTracing(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("main : shutting down tracing")
+		}
+	}()
 
 	// =========================================================================
 	// Initialize authentication support
 	log.Info().Msg("main : Started : Initializing authentication support")
-	privatePEM, err := os.ReadFile("private.pem")
+	privatePEM, err := os.ReadFile(cfg.Auth.PrivateKeyFile)
 	if err != nil {
 		return fmt.Errorf("reading auth private key %w", err)
 	}
@@ -39,7 +104,7 @@ func startApp() error {
 		return fmt.Errorf("parsing auth private key %w", err)
 	}
 
-	publicPEM, err := os.ReadFile("pubkey.pem")
+	publicPEM, err := os.ReadFile(cfg.Auth.PublicKeyFile)
 	if err != nil {
 		return fmt.Errorf("reading auth public key %w", err)
 	}
@@ -49,7 +114,15 @@ func startApp() error {
 		return fmt.Errorf("parsing auth public key %w", err)
 	}
 
-	a, err := auth.NewAuth(privateKey, publicKey)
+	// The public keys of previous signing keys are kept in the verification keys directory so that
+	// tokens they signed stay valid after a rotation. Removing a key from the directory retires it.
+	active := auth.SigningKey{ID: auth.KeyID(publicKey), PrivateKey: privateKey, PublicKey: publicKey}
+	previous, err := loadVerificationKeys(cfg.Auth.VerificationKeysDir, active.ID)
+	if err != nil {
+		return fmt.Errorf("loading verification keys %w", err)
+	}
+
+	a, err := auth.NewAuthWithKeys(active.ID, append([]auth.SigningKey{active}, previous...)...)
 	if err != nil {
 		return fmt.Errorf("constructing auth %w", err)
 	}
@@ -57,40 +130,65 @@ func startApp() error {
 	// =========================================================================
 	// Start Database
 	log.Info().Msg("main : Started : Initializing db support")
-	db, err := database.Open()
+	db, err := openDB(cfg.DB)
 	if err != nil {
-		return fmt.Errorf("connecting to db %w", err)
+		return err
 	}
-	pg, err := db.DB()
+	sqlDB, err := db.DB()
 	if err != nil {
-		return fmt.Errorf("Failed to get database instance: %w ", err)
+		return fmt.Errorf("getting database instance %w", err)
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-	defer cancel()
-
-	err = pg.PingContext(ctx)
+	err = metrics.RegisterDB(sqlDB, cfg.DB.Driver)
 	if err != nil {
-		return fmt.Errorf("Database is not connected: %w ", err)
+		return fmt.Errorf("registering db metrics %w", err)
+	}
+	migrator, err := newMigrator(db, cfg.DB.Driver)
+	if err != nil {
+		return err
+	}
+	if cfg.DB.MigrateOnStart {
+		err = migrateUp(migrator)
+		if err != nil {
+			return err
+		}
 	}
 
 	// =========================================================================
 	//Initialize Conn layer support
-	ms, err := services.NewService(db)
+	repo, err := repository.NewRepository(db)
 	if err != nil {
 		return err
 	}
-	err = ms.AutoMigrate()
+	accounts, err := newAccounts(cfg)
 	if err != nil {
 		return err
 	}
+	ms, err := services.NewStore(repo, accounts)
+	if err != nil {
+		return err
+	}
+	ms = services.WithTracing(ms)
+	// Reject access tokens that were revoked on logout
+	a.SetRevocationList(repo)
+
+	// Expire published jobs once their expiry date has passed, until the service shuts down
+	sweeperCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+	go services.RunJobSweeper(sweeperCtx, ms, time.Minute)
+
+	// The API is ready while the database answers, its schema is up to date and tokens can be signed
+	hc := health.New(cfg.HTTP.ReadyTimeout.Duration)
+	hc.Add("database", sqlDB.PingContext)
+	hc.Add("migrations", migrationsApplied(migrator))
+	hc.Add("keys", func(context.Context) error { return a.CheckKeys() })
 
 	// Initialize http service
 	api := http.Server{
-		Addr:         ":8081",
-		ReadTimeout:  8000 * time.Second,
-		WriteTimeout: 800 * time.Second,
-		IdleTimeout:  800 * time.Second,
-		Handler:      handlers.API(a, ms),
+		Addr:         cfg.HTTP.Addr,
+		ReadTimeout:  cfg.HTTP.ReadTimeout.Duration,
+		WriteTimeout: cfg.HTTP.WriteTimeout.Duration,
+		IdleTimeout:  cfg.HTTP.IdleTimeout.Duration,
+		Handler:      handlers.API(a, ms, cfg.HTTP, hc),
 	}
 
 	// channel to store any errors while setting up the service
@@ -107,7 +205,9 @@ func startApp() error {
 		return fmt.Errorf("server error %w", err)
 	case sig := <-shutdown:
 		log.Info().Msgf("main: Start shutdown %s", sig)
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		// Report not ready while the requests in flight finish
+		hc.ShutDown()
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout.Duration)
 		defer cancel()
 		//Shutdown gracefully shuts down the server without interrupting any active connections.
 		//Shutdown works by first closing all open listeners, then closing all idle connections,
@@ -123,3 +223,100 @@ func startApp() error {
 	return nil
 
 }
+
+// newAccounts sets up the mailer and token signer of the email verification, password reset and two-factor login
+// flows.
+func newAccounts(cfg config.Config) (services.Accounts, error) {
+	mailer, err := mail.New(cfg.Mail)
+	if err != nil {
+		return services.Accounts{}, fmt.Errorf("setting up mail %w", err)
+	}
+
+	secret := []byte(cfg.Auth.TokenSecret)
+	if len(secret) == 0 {
+		log.Warn().Msg("main : auth.token_secret is not set, emailed links stop working on restart")
+		secret = make([]byte, config.MinTokenSecretLen)
+		_, err = rand.Read(secret)
+		if err != nil {
+			return services.Accounts{}, fmt.Errorf("generating token secret %w", err)
+		}
+	}
+	tokens, err := services.NewTokenSigner(secret)
+	if err != nil {
+		return services.Accounts{}, err
+	}
+
+	var sso services.SSO
+	if cfg.OIDC.Enabled {
+		// The provider is discovered on the first login, so that it being down doesn't keep the API from starting
+		sso = services.SSO{
+			Provider:       oidc.New(cfg.OIDC, &http.Client{Timeout: 10 * time.Second}),
+			DefaultRole:    cfg.OIDC.DefaultRole,
+			AllowedDomains: cfg.OIDC.AllowedDomains,
+		}
+	}
+
+	return services.Accounts{
+		Mailer:           mailer,
+		Tokens:           tokens,
+		VerifyEmailURL:   cfg.Mail.VerifyEmailURL,
+		ResetPasswordURL: cfg.Mail.ResetPasswordURL,
+		VerifyEmailTTL:   cfg.Auth.VerifyEmailTTL.Duration,
+		ResetPasswordTTL: cfg.Auth.ResetPasswordTTL.Duration,
+		TOTPIssuer:       cfg.Auth.TOTPIssuer,
+		MFAChallengeTTL:  cfg.Auth.MFAChallengeTTL.Duration,
+		SSO:              sso,
+	}, nil
+}
+
+// migrationsApplied returns a readiness check that fails while migrations are pending, which happens when the
+// binary was deployed before `migrate up` was run.
+func migrationsApplied(m *migrate.Migrator) health.Check {
+	return func(ctx context.Context) error {
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		pending := 0
+		for _, s := range statuses {
+			if s.AppliedAt == nil {
+				pending++
+			}
+		}
+		if pending > 0 {
+			return fmt.Errorf("%d pending migrations", pending)
+		}
+		return nil
+	}
+}
+
+// loadVerificationKeys reads every PEM encoded RSA public key in dir. The keys are only used to verify tokens,
+// never to sign them. An empty dir or a missing directory is not an error, and the key with activeKID is skipped.
+func loadVerificationKeys(dir string, activeKID string) ([]auth.SigningKey, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]auth.SigningKey, 0, len(files))
+	for _, f := range files {
+		pem, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s %w", f, err)
+		}
+		pub, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s %w", f, err)
+		}
+		kid := auth.KeyID(pub)
+		if kid == activeKID {
+			continue
+		}
+		log.Info().Str("kid", kid).Str("file", f).Msg("main : loaded verification key")
+		keys = append(keys, auth.SigningKey{ID: kid, PublicKey: pub})
+	}
+	return keys, nil
+}
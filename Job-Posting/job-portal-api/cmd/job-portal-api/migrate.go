@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"job-portal-api/internal/config"
+	"job-portal-api/internal/database"
+	"job-portal-api/internal/migrate"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// runMigrate implements the migrate command: migrate up, migrate down [n] and migrate status.
+func runMigrate(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("migrate needs a subcommand: up, down or status")
+	}
+
+	db, err := openDB(cfg.DB)
+	if err != nil {
+		return err
+	}
+	m, err := newMigrator(db, cfg.DB.Driver)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		return migrateUp(m)
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			n, err = strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("migrate down: %q is not a positive number of migrations", args[1])
+			}
+		}
+		reverted, err := m.Down(ctx, n)
+		for _, mig := range reverted {
+			log.Info().Int64("version", mig.Version).Str("name", mig.Name).Msg("migrate : reverted")
+		}
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			log.Info().Msg("migrate : nothing to revert")
+		}
+		return nil
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Local().Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown migrate subcommand %q, use up, down or status", args[0])
+	}
+}
+
+// migrateUp applies every pending migration.
+func migrateUp(m *migrate.Migrator) error {
+	applied, err := m.Up(context.Background())
+	for _, mig := range applied {
+		log.Info().Int64("version", mig.Version).Str("name", mig.Name).Msg("migrate : applied")
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		log.Info().Msg("migrate : schema is up to date")
+	}
+	return nil
+}
+
+func newMigrator(db *gorm.DB, driver string) (*migrate.Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get database instance: %w ", err)
+	}
+	return migrate.New(sqlDB, driver)
+}
+
+// openDB connects to the database and checks that it is reachable.
+func openDB(cfg config.DB) (*gorm.DB, error) {
+	db, err := database.Open(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to db %w", err)
+	}
+	pg, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get database instance: %w ", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	err = pg.PingContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Database is not connected: %w ", err)
+	}
+	return db, nil
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"crypto/rsa"
 	"errors"
 	"fmt"
@@ -11,11 +12,18 @@ type ctxKey int
 
 const Key ctxKey = 1
 
-// Auth is a type that deals with authentication-related activities. It contains two fields, privateKey and publicKey which are
-// used for token generation and verification respectively.
+// Auth is a type that deals with authentication-related activities. It holds a set of keys identified by kid:
+// the active key is used for token generation, and any key that isn't retired is accepted for verification.
 type Auth struct {
-	privateKey *rsa.PrivateKey // privateKey is used to sign the JWT token.
-	publicKey  *rsa.PublicKey  // publicKey is used to validate the JWT token.
+	keys      map[string]SigningKey // keys holds every known key by its kid.
+	keyOrder  []string              // keyOrder keeps the order the keys were given in, for publishing.
+	activeKID string                // activeKID is the kid of the key used to sign the JWT token.
+	revoked   RevocationList        // revoked is consulted to reject tokens that were revoked before expiry.
+}
+
+// RevocationList reports whether the token with the given ID (jti) has been revoked.
+type RevocationList interface {
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
 }
 
 // NewAuth is a constructor function for Auth struct. It accepts privateKey and publicKey as parameters and returns
@@ -24,21 +32,52 @@ func NewAuth(privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) (*Auth, error
 	if privateKey == nil || publicKey == nil {
 		return nil, errors.New("private/public key cannot be nil")
 	}
-	return &Auth{
-		privateKey: privateKey,
-		publicKey:  publicKey,
-	}, nil
+	kid := KeyID(publicKey)
+	return NewAuthWithKeys(kid, SigningKey{ID: kid, PrivateKey: privateKey, PublicKey: publicKey})
+}
+
+// NewAuthWithKeys builds an Auth that knows several keys, which allows rotating the signing key without
+// invalidating tokens signed by the previous one. Tokens are signed with the key identified by activeKID, which
+// must have a private key and must not be retired.
+func NewAuthWithKeys(activeKID string, keys ...SigningKey) (*Auth, error) {
+	a := Auth{keys: make(map[string]SigningKey, len(keys)), activeKID: activeKID}
+	for _, k := range keys {
+		if k.ID == "" || k.PublicKey == nil {
+			return nil, errors.New("key id and public key cannot be empty")
+		}
+		if _, ok := a.keys[k.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", k.ID)
+		}
+		a.keys[k.ID] = k
+		a.keyOrder = append(a.keyOrder, k.ID)
+	}
+
+	active, ok := a.keys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("active key %q not found", activeKID)
+	}
+	if active.PrivateKey == nil || active.Retired {
+		return nil, fmt.Errorf("active key %q must have a private key and must not be retired", activeKID)
+	}
+	return &a, nil
+}
+
+// SetRevocationList makes ValidateToken reject tokens whose ID is present in rl.
+func (a *Auth) SetRevocationList(rl RevocationList) {
+	a.revoked = rl
 }
 
 // GenerateToken is a method for Auth struct. It generates a new JWT token using the provided claims and
-// signs it using the privateKey of the Auth struct it's called upon. If there is an error during signing,
+// signs it using the active key of the Auth struct it's called upon. If there is an error during signing,
 // it returns an error.
-func (a *Auth) GenerateToken(claims jwt.RegisteredClaims) (string, error) {
+func (a *Auth) GenerateToken(claims Claims) (string, error) {
 	//NewWithClaims creates a new Token with the specified signing method and claims.
 	tkn := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	// The kid header tells verifiers which key the token was signed with.
+	tkn.Header["kid"] = a.activeKID
 
 	// Signing our token with our private key.
-	tokenStr, err := tkn.SignedString(a.privateKey)
+	tokenStr, err := tkn.SignedString(a.keys[a.activeKID].PrivateKey)
 	if err != nil {
 		return "", fmt.Errorf("signing token %w", err)
 	}
@@ -46,21 +85,43 @@ func (a *Auth) GenerateToken(claims jwt.RegisteredClaims) (string, error) {
 	return tokenStr, nil
 }
 
-// ValidateToken is a method for Auth struct. It verifies the provided JWT token using the publicKey of the Auth struct
-// it's called upon and returns the parsed claims if the JWT token is valid. If the JWT token is invalid or
-// there is an error during parsing, it returns an error.
-func (a *Auth) ValidateToken(token string) (jwt.RegisteredClaims, error) {
-	var c jwt.RegisteredClaims
-	// Parse the token with the registered claims.
-	tkn, err := jwt.ParseWithClaims(token, &c, func(token *jwt.Token) (interface{}, error) {
-		return a.publicKey, nil
-	})
+// ValidateToken is a method for Auth struct. It verifies the provided JWT token using the key named by its kid header
+// (or the active key for tokens without one) and returns the parsed claims if the JWT token is valid. If the JWT token is invalid or
+// there is an error during parsing, it returns an error. Tokens present in the revocation list are rejected as well.
+func (a *Auth) ValidateToken(ctx context.Context, token string) (Claims, error) {
+	var c Claims
+	// Parse the token with our custom claims.
+	tkn, err := jwt.ParseWithClaims(token, &c, a.verificationKey, jwt.WithValidMethods([]string{"RS256"}))
 	if err != nil {
-		return jwt.RegisteredClaims{}, fmt.Errorf("parsing token %w", err)
+		return Claims{}, fmt.Errorf("parsing token %w", err)
 	}
 	// Check if the parsed token is valid.
 	if !tkn.Valid {
-		return jwt.RegisteredClaims{}, errors.New("invalid token")
+		return Claims{}, errors.New("invalid token")
+	}
+	// Check if the token was revoked, e.g. because the user logged out.
+	if a.revoked != nil {
+		revoked, err := a.revoked.IsTokenRevoked(ctx, c.ID)
+		if err != nil {
+			return Claims{}, fmt.Errorf("checking token revocation %w", err)
+		}
+		if revoked {
+			return Claims{}, errors.New("token has been revoked")
+		}
 	}
 	return c, nil
-}
\ No newline at end of file
+}
+
+// verificationKey picks the public key a token has to be verified with, based on its kid header.
+// Tokens issued before keys had IDs carry no kid and are checked against the active key.
+func (a *Auth) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = a.activeKID
+	}
+	k, ok := a.keys[kid]
+	if !ok || k.Retired {
+		return nil, fmt.Errorf("unknown or retired key %q", kid)
+	}
+	return k.PublicKey, nil
+}
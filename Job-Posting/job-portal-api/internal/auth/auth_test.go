@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func generateKey(t *testing.T) SigningKey {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return SigningKey{ID: KeyID(&privateKey.PublicKey), PrivateKey: privateKey, PublicKey: &privateKey.PublicKey}
+}
+
+func TestKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	oldKey := generateKey(t)
+	nextKey := generateKey(t)
+
+	// Sign a token before the rotation.
+	before, err := NewAuthWithKeys(oldKey.ID, oldKey)
+	require.NoError(t, err)
+	oldToken, err := before.GenerateToken(NewClaims("1"))
+	require.NoError(t, err)
+
+	// After the rotation the old key is only kept for verification.
+	verifyOnly := SigningKey{ID: oldKey.ID, PublicKey: oldKey.PublicKey}
+	after, err := NewAuthWithKeys(nextKey.ID, nextKey, verifyOnly)
+	require.NoError(t, err)
+
+	newToken, err := after.GenerateToken(NewClaims("2"))
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &Claims{})
+	require.NoError(t, err)
+	require.Equal(t, nextKey.ID, parsed.Header["kid"])
+
+	// Tokens signed by either key are accepted.
+	_, err = after.ValidateToken(ctx, oldToken)
+	require.NoError(t, err)
+	_, err = after.ValidateToken(ctx, newToken)
+	require.NoError(t, err)
+	require.Len(t, after.JWKS().Keys, 2)
+
+	// Once the old key is retired its tokens are rejected and it is no longer published.
+	verifyOnly.Retired = true
+	retired, err := NewAuthWithKeys(nextKey.ID, nextKey, verifyOnly)
+	require.NoError(t, err)
+	_, err = retired.ValidateToken(ctx, oldToken)
+	require.Error(t, err)
+	_, err = retired.ValidateToken(ctx, newToken)
+	require.NoError(t, err)
+	require.Len(t, retired.JWKS().Keys, 1)
+	require.Equal(t, nextKey.ID, retired.JWKS().Keys[0].Kid)
+}
+
+func TestNewAuthWithKeys(t *testing.T) {
+	k := generateKey(t)
+
+	_, err := NewAuthWithKeys("missing", k)
+	require.Error(t, err)
+
+	_, err = NewAuthWithKeys(k.ID, SigningKey{ID: k.ID, PublicKey: k.PublicKey})
+	require.Error(t, err, "active key without a private key")
+
+	_, err = NewAuthWithKeys(k.ID, k, k)
+	require.Error(t, err, "duplicate key id")
+}
+
+func TestCheckKeys(t *testing.T) {
+	k := generateKey(t)
+	a, err := NewAuthWithKeys(k.ID, k)
+	require.NoError(t, err)
+	require.NoError(t, a.CheckKeys())
+
+	require.Error(t, (&Auth{}).CheckKeys(), "no key loaded")
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// AccessTokenTTL is how long an access token issued by the portal stays valid.
+const AccessTokenTTL = time.Hour
+
+// APIKeyPrefix starts every API key, which tells them apart from access tokens in the Authorization header and
+// lets secret scanners recognise leaked keys.
+const APIKeyPrefix = "jpk_"
+
+// Claims is the set of claims the portal puts into its tokens. It embeds the
+// registered claims and adds the roles of the user the token was issued to.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+
+	// APIKeyID, CompanyID and Scopes are only set when the request was authenticated with an API key. Such a
+	// request may only act on CompanyID, on the routes accepting one of Scopes. They are never put into tokens.
+	APIKeyID  uint     `json:"-"`
+	CompanyID uint     `json:"-"`
+	Scopes    []string `json:"-"`
+}
+
+// NewClaims builds the claims for a fresh access token issued to subject. Every token gets a unique ID (jti)
+// so that it can be revoked before it expires.
+func NewClaims(subject string, roles ...string) Claims {
+	now := time.Now()
+	return Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "jobportal project",
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{"companies"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+		Roles: roles,
+	}
+}
+
+// HasRole reports whether the claims carry at least one of the given roles.
+func (c Claims) HasRole(roles ...string) bool {
+	for _, have := range c.Roles {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasScope reports whether the claims carry at least one of the given scopes. Claims of access tokens have no
+// scopes, they are checked with HasRole instead.
+func (c Claims) HasScope(scopes ...string) bool {
+	for _, have := range c.Scopes {
+		for _, want := range scopes {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
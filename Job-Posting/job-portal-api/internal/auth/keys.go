@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// SigningKey is one RSA key pair known to Auth, identified by ID which is put in the "kid" header of the tokens
+// it signs. Keys that are only kept around to verify tokens issued before a rotation may leave PrivateKey nil.
+// A retired key is no longer accepted for verification.
+type SigningKey struct {
+	ID         string
+	PrivateKey *rsa.PrivateKey
+	PublicKey  *rsa.PublicKey
+	Retired    bool
+}
+
+// JWK is the JSON Web Key (RFC 7517) representation of an RSA public key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS is a JSON Web Key Set as served from /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeyID derives a stable key ID from a public key using its RFC 7638 JWK thumbprint, so the same key always gets
+// the same kid without having to configure one.
+func KeyID(pub *rsa.PublicKey) string {
+	// The thumbprint is the hash of the required members in lexicographic order, without whitespace.
+	b, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{
+		E:   encodeInt(big.NewInt(int64(pub.E))),
+		Kty: "RSA",
+		N:   encodeInt(pub.N),
+	})
+	sum := sha256.Sum256(b)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// CheckKeys reports whether tokens can be signed, that is whether the active key is loaded with its private key.
+func (a *Auth) CheckKeys() error {
+	active, ok := a.keys[a.activeKID]
+	if !ok || active.PrivateKey == nil {
+		return fmt.Errorf("signing key %q is not loaded", a.activeKID)
+	}
+	if active.Retired {
+		return fmt.Errorf("signing key %q is retired", a.activeKID)
+	}
+	return nil
+}
+
+// JWKS returns the public keys that tokens may currently be verified with. Retired keys are left out.
+func (a *Auth) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(a.keys))}
+	for _, id := range a.keyOrder {
+		k := a.keys[id]
+		if k.Retired {
+			continue
+		}
+		set.Keys = append(set.Keys, JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: "RS256",
+			Kid: k.ID,
+			N:   encodeInt(k.PublicKey.N),
+			E:   encodeInt(big.NewInt(int64(k.PublicKey.E))),
+		})
+	}
+	return set
+}
+
+func encodeInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
@@ -0,0 +1,602 @@
+// Package config loads the settings of the job portal API. Values come from built-in defaults, then an optional
+// YAML or TOML file, then environment variables prefixed with JOBPORTAL_, each overriding the previous one.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is prepended to the name of every environment variable read by Load.
+const EnvPrefix = "JOBPORTAL_"
+
+type Config struct {
+	HTTP    HTTP    `yaml:"http" toml:"http"`
+	DB      DB      `yaml:"db" toml:"db"`
+	Auth    Auth    `yaml:"auth" toml:"auth"`
+	Log     Log     `yaml:"log" toml:"log"`
+	Tracing Tracing `yaml:"tracing" toml:"tracing"`
+	Mail    Mail    `yaml:"mail" toml:"mail"`
+	OIDC    OIDC    `yaml:"oidc" toml:"oidc"`
+}
+
+// HTTP configures the API server.
+type HTTP struct {
+	Addr            string   `yaml:"addr" toml:"addr"`
+	ReadTimeout     Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout    Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout     Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	// RequestTimeout is how long a handler may run before the request is cancelled and answered with
+	// 504 Gateway Timeout. SearchTimeout replaces it on the job listing and search routes, which run the
+	// heaviest queries. Both must be shorter than WriteTimeout, or the client never sees the 504.
+	RequestTimeout Duration `yaml:"request_timeout" toml:"request_timeout"`
+	SearchTimeout  Duration `yaml:"search_timeout" toml:"search_timeout"`
+	// ReadyTimeout bounds the checks of the readiness probe, /readyz answers 503 when they take longer.
+	ReadyTimeout Duration `yaml:"ready_timeout" toml:"ready_timeout"`
+	// TrustedProxies lists the addresses or CIDR ranges of the proxies allowed to set X-Forwarded-For. The client
+	// IP used for rate limiting is only taken from that header when the request comes through one of them.
+	TrustedProxies []string  `yaml:"trusted_proxies" toml:"trusted_proxies"`
+	RateLimit      RateLimit `yaml:"rate_limit" toml:"rate_limit"`
+}
+
+// RateLimit configures the token bucket rate limiting of the API. Every client IP, and every user once
+// authenticated, gets a bucket per route listed in Routes, and one shared bucket for the other routes limited by
+// Default. Routes are keyed by their pattern, such as /api/login or /api/jobs/:jobID.
+type RateLimit struct {
+	Enabled bool             `yaml:"enabled" toml:"enabled"`
+	Default Limit            `yaml:"default" toml:"default"`
+	Routes  map[string]Limit `yaml:"routes" toml:"routes"`
+}
+
+// Limit allows Requests requests every Per, in bursts of up to Requests.
+type Limit struct {
+	Requests int      `yaml:"requests" toml:"requests"`
+	Per      Duration `yaml:"per" toml:"per"`
+}
+
+// Database drivers supported by database.Open.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// DB configures the database connection and its pool. With the postgres driver the password can be left out of
+// the DSN and given in PGPASSWORD instead. With the sqlite driver the DSN is a file path, or ":memory:" for a
+// database that lives as long as the process, and the pool settings are ignored.
+type DB struct {
+	Driver          string   `yaml:"driver" toml:"driver"`
+	DSN             string   `yaml:"dsn" toml:"dsn"`
+	MaxOpenConns    int      `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	ConnMaxIdleTime Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time"`
+	// MigrateOnStart applies pending migrations when the server starts. Turn it off to run them only with the
+	// migrate command.
+	MigrateOnStart bool `yaml:"migrate_on_start" toml:"migrate_on_start"`
+}
+
+// Auth holds the paths of the keys used to sign and verify access tokens, and the settings of the tokens emailed
+// to verify an address or reset a password.
+type Auth struct {
+	PrivateKeyFile string `yaml:"private_key_file" toml:"private_key_file"`
+	PublicKeyFile  string `yaml:"public_key_file" toml:"public_key_file"`
+	// VerificationKeysDir holds the public keys of previous signing keys, see auth.NewAuthWithKeys.
+	VerificationKeysDir string `yaml:"verification_keys_dir" toml:"verification_keys_dir"`
+	// TokenSecret signs the emailed tokens and must be at least 32 bytes long. When it is empty a random secret is
+	// generated at startup, so links sent before a restart stop working and replicas reject each other's links.
+	TokenSecret      string   `yaml:"token_secret" toml:"token_secret"`
+	VerifyEmailTTL   Duration `yaml:"verify_email_ttl" toml:"verify_email_ttl"`
+	ResetPasswordTTL Duration `yaml:"reset_password_ttl" toml:"reset_password_ttl"`
+	// TOTPIssuer names the API in the authenticator apps of users with two-factor authentication.
+	// MFAChallengeTTL is how long they have to enter a code after their password was accepted.
+	TOTPIssuer      string   `yaml:"totp_issuer" toml:"totp_issuer"`
+	MFAChallengeTTL Duration `yaml:"mfa_challenge_ttl" toml:"mfa_challenge_ttl"`
+}
+
+// MinTokenSecretLen is the shortest Auth.TokenSecret accepted, the size of a SHA-256 key.
+const MinTokenSecretLen = 32
+
+type Log struct {
+	Level string `yaml:"level" toml:"level"`
+}
+
+// Span exporters supported by tracing.Setup.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Tracing configures OpenTelemetry tracing. With the none exporter spans are still created, so trace IDs are
+// still read from traceparent headers and written to the logs, but they aren't sent anywhere. The stdout exporter
+// prints them for local runs and the otlp exporter sends them over OTLP/HTTP to Endpoint, a host:port such as
+// localhost:4318. SampleRatio is the share of new traces that are recorded; incoming sampled traces are always kept.
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter"`
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint"`
+	Insecure    bool    `yaml:"insecure" toml:"insecure"`
+	ServiceName string  `yaml:"service_name" toml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+}
+
+// Mail transports supported by mail.New.
+const (
+	MailSMTP = "smtp"
+	MailFile = "file"
+)
+
+// Mail configures how emails are sent. The smtp transport delivers them through SMTP, upgrading the connection with
+// STARTTLS when the server offers it. The file transport writes every email to Dir instead, for local runs.
+// VerifyEmailURL and ResetPasswordURL are the links put in the emails, {token} is replaced by the token.
+type Mail struct {
+	Transport        string `yaml:"transport" toml:"transport"`
+	From             string `yaml:"from" toml:"from"`
+	Dir              string `yaml:"dir" toml:"dir"`
+	SMTP             SMTP   `yaml:"smtp" toml:"smtp"`
+	VerifyEmailURL   string `yaml:"verify_email_url" toml:"verify_email_url"`
+	ResetPasswordURL string `yaml:"reset_password_url" toml:"reset_password_url"`
+}
+
+// TokenPlaceholder is replaced by the token in Mail.VerifyEmailURL and Mail.ResetPasswordURL.
+const TokenPlaceholder = "{token}"
+
+// SMTP holds the address of the SMTP server and, when it requires authentication, the credentials to use.
+type SMTP struct {
+	Host     string `yaml:"host" toml:"host"`
+	Port     int    `yaml:"port" toml:"port"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+}
+
+// OIDC configures single sign-on with an OpenID Connect provider, through the authorization code flow with PKCE.
+// RedirectURL must lead to /api/oidc/callback and be registered with the provider. Users are matched to accounts
+// by their verified email address, and those without one get an account with DefaultRole. When AllowedDomains is
+// set, only addresses in one of those domains may log in this way.
+type OIDC struct {
+	Enabled        bool     `yaml:"enabled" toml:"enabled"`
+	Issuer         string   `yaml:"issuer" toml:"issuer"`
+	ClientID       string   `yaml:"client_id" toml:"client_id"`
+	ClientSecret   string   `yaml:"client_secret" toml:"client_secret"`
+	RedirectURL    string   `yaml:"redirect_url" toml:"redirect_url"`
+	Scopes         []string `yaml:"scopes" toml:"scopes"`
+	DefaultRole    string   `yaml:"default_role" toml:"default_role"`
+	AllowedDomains []string `yaml:"allowed_domains" toml:"allowed_domains"`
+}
+
+// Duration is a time.Duration written as a string such as "10s" or "5m" in config files and environment variables.
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Default returns the configuration used when nothing else is set. It is meant for local development.
+func Default() Config {
+	return Config{
+		HTTP: HTTP{
+			Addr:            ":8081",
+			ReadTimeout:     Duration{10 * time.Second},
+			WriteTimeout:    Duration{30 * time.Second},
+			IdleTimeout:     Duration{2 * time.Minute},
+			ShutdownTimeout: Duration{10 * time.Second},
+			RequestTimeout:  Duration{5 * time.Second},
+			SearchTimeout:   Duration{15 * time.Second},
+			ReadyTimeout:    Duration{2 * time.Second},
+			RateLimit: RateLimit{
+				Enabled: true,
+				Default: Limit{Requests: 300, Per: Duration{time.Minute}},
+				Routes: map[string]Limit{
+					"/api/login":         {Requests: 10, Per: Duration{time.Minute}},
+					"/api/login/mfa":     {Requests: 10, Per: Duration{time.Minute}},
+					"/api/oidc/callback": {Requests: 10, Per: Duration{time.Minute}},
+					"/api/register":      {Requests: 10, Per: Duration{time.Hour}},
+					// Every request sends an email
+					"/api/password-reset":      {Requests: 5, Per: Duration{time.Hour}},
+					"/api/verify-email/resend": {Requests: 5, Per: Duration{time.Hour}},
+				},
+			},
+		},
+		DB: DB{
+			Driver:          DriverPostgres,
+			DSN:             "host=localhost user=postgres dbname=postgres port=5432 sslmode=disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: Duration{30 * time.Minute},
+			ConnMaxIdleTime: Duration{5 * time.Minute},
+			MigrateOnStart:  true,
+		},
+		Auth: Auth{
+			PrivateKeyFile:      "private.pem",
+			PublicKeyFile:       "pubkey.pem",
+			VerificationKeysDir: "keys",
+			VerifyEmailTTL:      Duration{48 * time.Hour},
+			ResetPasswordTTL:    Duration{time.Hour},
+			TOTPIssuer:          "Job Portal",
+			MFAChallengeTTL:     Duration{5 * time.Minute},
+		},
+		Log: Log{
+			Level: "info",
+		},
+		Tracing: Tracing{
+			Exporter:    ExporterNone,
+			Endpoint:    "localhost:4318",
+			ServiceName: "job-portal-api",
+			SampleRatio: 1,
+		},
+		Mail: Mail{
+			Transport:        MailFile,
+			From:             "Job Portal <no-reply@localhost>",
+			Dir:              "mail",
+			SMTP:             SMTP{Host: "localhost", Port: 587},
+			VerifyEmailURL:   "http://localhost:8081/api/verify-email?token={token}",
+			ResetPasswordURL: "http://localhost:8081/reset-password?token={token}",
+		},
+		OIDC: OIDC{
+			RedirectURL: "http://localhost:8081/api/oidc/callback",
+			Scopes:      []string{"openid", "email", "profile"},
+			DefaultRole: "candidate",
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the file at path if path isn't empty, and the environment.
+// The file format is picked from its extension: .yaml, .yml or .toml. Unknown keys in the file are an error.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		err := cfg.readFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("reading config file %s: %w", path, err)
+		}
+	}
+
+	err := cfg.readEnv(os.LookupEnv)
+	if err != nil {
+		return Config{}, fmt.Errorf("reading config from environment: %w", err)
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(c)
+		if errors.Is(err, io.EOF) {
+			// An empty file keeps the defaults.
+			return nil
+		}
+		return err
+	case ".toml":
+		dec := toml.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		return dec.Decode(c)
+	default:
+		return fmt.Errorf("unsupported config file extension %q, use .yaml, .yml or .toml", filepath.Ext(path))
+	}
+}
+
+func (c *Config) readEnv(lookup func(string) (string, bool)) error {
+	var errs []error
+	str := func(name string, dst *string) {
+		if v, ok := lookup(EnvPrefix + name); ok {
+			*dst = v
+		}
+	}
+	// list reads a comma separated list, an empty variable clears it
+	list := func(name string, dst *[]string) {
+		if v, ok := lookup(EnvPrefix + name); ok {
+			*dst = nil
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					*dst = append(*dst, item)
+				}
+			}
+		}
+	}
+	integer := func(name string, dst *int) {
+		if v, ok := lookup(EnvPrefix + name); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s%s: %w", EnvPrefix, name, err))
+				return
+			}
+			*dst = n
+		}
+	}
+	boolean := func(name string, dst *bool) {
+		if v, ok := lookup(EnvPrefix + name); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s%s: %w", EnvPrefix, name, err))
+				return
+			}
+			*dst = b
+		}
+	}
+	float := func(name string, dst *float64) {
+		if v, ok := lookup(EnvPrefix + name); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s%s: %w", EnvPrefix, name, err))
+				return
+			}
+			*dst = f
+		}
+	}
+	duration := func(name string, dst *Duration) {
+		if v, ok := lookup(EnvPrefix + name); ok {
+			err := dst.UnmarshalText([]byte(v))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s%s: %w", EnvPrefix, name, err))
+			}
+		}
+	}
+
+	str("HTTP_ADDR", &c.HTTP.Addr)
+	duration("HTTP_READ_TIMEOUT", &c.HTTP.ReadTimeout)
+	duration("HTTP_WRITE_TIMEOUT", &c.HTTP.WriteTimeout)
+	duration("HTTP_IDLE_TIMEOUT", &c.HTTP.IdleTimeout)
+	duration("HTTP_SHUTDOWN_TIMEOUT", &c.HTTP.ShutdownTimeout)
+	duration("HTTP_REQUEST_TIMEOUT", &c.HTTP.RequestTimeout)
+	duration("HTTP_SEARCH_TIMEOUT", &c.HTTP.SearchTimeout)
+	duration("HTTP_READY_TIMEOUT", &c.HTTP.ReadyTimeout)
+	list("HTTP_TRUSTED_PROXIES", &c.HTTP.TrustedProxies)
+	boolean("HTTP_RATE_LIMIT_ENABLED", &c.HTTP.RateLimit.Enabled)
+	integer("HTTP_RATE_LIMIT_REQUESTS", &c.HTTP.RateLimit.Default.Requests)
+	duration("HTTP_RATE_LIMIT_PER", &c.HTTP.RateLimit.Default.Per)
+
+	str("DB_DRIVER", &c.DB.Driver)
+	str("DB_DSN", &c.DB.DSN)
+	integer("DB_MAX_OPEN_CONNS", &c.DB.MaxOpenConns)
+	integer("DB_MAX_IDLE_CONNS", &c.DB.MaxIdleConns)
+	duration("DB_CONN_MAX_LIFETIME", &c.DB.ConnMaxLifetime)
+	duration("DB_CONN_MAX_IDLE_TIME", &c.DB.ConnMaxIdleTime)
+	boolean("DB_MIGRATE_ON_START", &c.DB.MigrateOnStart)
+
+	str("AUTH_PRIVATE_KEY_FILE", &c.Auth.PrivateKeyFile)
+	str("AUTH_PUBLIC_KEY_FILE", &c.Auth.PublicKeyFile)
+	str("AUTH_VERIFICATION_KEYS_DIR", &c.Auth.VerificationKeysDir)
+	str("AUTH_TOKEN_SECRET", &c.Auth.TokenSecret)
+	duration("AUTH_VERIFY_EMAIL_TTL", &c.Auth.VerifyEmailTTL)
+	duration("AUTH_RESET_PASSWORD_TTL", &c.Auth.ResetPasswordTTL)
+	str("AUTH_TOTP_ISSUER", &c.Auth.TOTPIssuer)
+	duration("AUTH_MFA_CHALLENGE_TTL", &c.Auth.MFAChallengeTTL)
+
+	str("LOG_LEVEL", &c.Log.Level)
+
+	str("TRACING_EXPORTER", &c.Tracing.Exporter)
+	str("TRACING_ENDPOINT", &c.Tracing.Endpoint)
+	boolean("TRACING_INSECURE", &c.Tracing.Insecure)
+	str("TRACING_SERVICE_NAME", &c.Tracing.ServiceName)
+	float("TRACING_SAMPLE_RATIO", &c.Tracing.SampleRatio)
+
+	str("MAIL_TRANSPORT", &c.Mail.Transport)
+	str("MAIL_FROM", &c.Mail.From)
+	str("MAIL_DIR", &c.Mail.Dir)
+	str("MAIL_SMTP_HOST", &c.Mail.SMTP.Host)
+	integer("MAIL_SMTP_PORT", &c.Mail.SMTP.Port)
+	str("MAIL_SMTP_USERNAME", &c.Mail.SMTP.Username)
+	str("MAIL_SMTP_PASSWORD", &c.Mail.SMTP.Password)
+	str("MAIL_VERIFY_EMAIL_URL", &c.Mail.VerifyEmailURL)
+	str("MAIL_RESET_PASSWORD_URL", &c.Mail.ResetPasswordURL)
+
+	boolean("OIDC_ENABLED", &c.OIDC.Enabled)
+	str("OIDC_ISSUER", &c.OIDC.Issuer)
+	str("OIDC_CLIENT_ID", &c.OIDC.ClientID)
+	str("OIDC_CLIENT_SECRET", &c.OIDC.ClientSecret)
+	str("OIDC_REDIRECT_URL", &c.OIDC.RedirectURL)
+	list("OIDC_SCOPES", &c.OIDC.Scopes)
+	str("OIDC_DEFAULT_ROLE", &c.OIDC.DefaultRole)
+	list("OIDC_ALLOWED_DOMAINS", &c.OIDC.AllowedDomains)
+
+	return errors.Join(errs...)
+}
+
+// Validate reports every setting that is missing or out of range.
+func (c Config) Validate() error {
+	var errs []error
+	if c.HTTP.Addr == "" {
+		errs = append(errs, errors.New("http.addr is required"))
+	}
+	timeouts := []struct {
+		name string
+		d    Duration
+	}{
+		{"http.read_timeout", c.HTTP.ReadTimeout},
+		{"http.write_timeout", c.HTTP.WriteTimeout},
+		{"http.idle_timeout", c.HTTP.IdleTimeout},
+		{"http.shutdown_timeout", c.HTTP.ShutdownTimeout},
+		{"http.request_timeout", c.HTTP.RequestTimeout},
+		{"http.search_timeout", c.HTTP.SearchTimeout},
+		{"http.ready_timeout", c.HTTP.ReadyTimeout},
+	}
+	for _, t := range timeouts {
+		if t.d.Duration <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", t.name))
+		}
+	}
+	if c.HTTP.RequestTimeout.Duration >= c.HTTP.WriteTimeout.Duration || c.HTTP.SearchTimeout.Duration >= c.HTTP.WriteTimeout.Duration {
+		errs = append(errs, errors.New("http.request_timeout and http.search_timeout must be shorter than http.write_timeout"))
+	}
+
+	for _, p := range c.HTTP.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(p)
+		if net.ParseIP(p) == nil && cidrErr != nil {
+			errs = append(errs, fmt.Errorf("http.trusted_proxies: %q is not an IP address or CIDR range", p))
+		}
+	}
+	if c.HTTP.RateLimit.Enabled {
+		errs = append(errs, c.HTTP.RateLimit.Default.validate("http.rate_limit.default"))
+		for route, l := range c.HTTP.RateLimit.Routes {
+			if !strings.HasPrefix(route, "/") {
+				errs = append(errs, fmt.Errorf("http.rate_limit.routes: route %q must start with /", route))
+			}
+			errs = append(errs, l.validate("http.rate_limit.routes."+route))
+		}
+	}
+
+	if c.DB.Driver != DriverPostgres && c.DB.Driver != DriverSQLite {
+		errs = append(errs, fmt.Errorf("db.driver must be %s or %s, not %q", DriverPostgres, DriverSQLite, c.DB.Driver))
+	}
+	if c.DB.DSN == "" {
+		errs = append(errs, errors.New("db.dsn is required"))
+	}
+	if c.DB.MaxOpenConns < 0 {
+		errs = append(errs, errors.New("db.max_open_conns must not be negative"))
+	}
+	if c.DB.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("db.max_idle_conns must not be negative"))
+	}
+	if c.DB.MaxOpenConns > 0 && c.DB.MaxIdleConns > c.DB.MaxOpenConns {
+		errs = append(errs, errors.New("db.max_idle_conns must not be greater than db.max_open_conns"))
+	}
+	if c.DB.ConnMaxLifetime.Duration < 0 || c.DB.ConnMaxIdleTime.Duration < 0 {
+		errs = append(errs, errors.New("db connection lifetimes must not be negative"))
+	}
+
+	if c.Auth.PrivateKeyFile == "" {
+		errs = append(errs, errors.New("auth.private_key_file is required"))
+	}
+	if c.Auth.PublicKeyFile == "" {
+		errs = append(errs, errors.New("auth.public_key_file is required"))
+	}
+	if c.Auth.TokenSecret != "" && len(c.Auth.TokenSecret) < MinTokenSecretLen {
+		errs = append(errs, fmt.Errorf("auth.token_secret must be at least %d bytes long", MinTokenSecretLen))
+	}
+	if c.Auth.VerifyEmailTTL.Duration <= 0 || c.Auth.ResetPasswordTTL.Duration <= 0 {
+		errs = append(errs, errors.New("auth.verify_email_ttl and auth.reset_password_ttl must be positive"))
+	}
+	// The issuer is the prefix of the account in the provisioning URI, which ends at the first colon
+	if c.Auth.TOTPIssuer == "" || strings.Contains(c.Auth.TOTPIssuer, ":") {
+		errs = append(errs, errors.New("auth.totp_issuer is required and must not contain a colon"))
+	}
+	if c.Auth.MFAChallengeTTL.Duration <= 0 {
+		errs = append(errs, errors.New("auth.mfa_challenge_ttl must be positive"))
+	}
+
+	_, err := zerolog.ParseLevel(c.Log.Level)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %w", err))
+	}
+
+	switch c.Tracing.Exporter {
+	case ExporterNone, ExporterStdout:
+	case ExporterOTLP:
+		if c.Tracing.Endpoint == "" {
+			errs = append(errs, errors.New("tracing.endpoint is required with the otlp exporter"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("tracing.exporter must be %s, %s or %s, not %q",
+			ExporterNone, ExporterStdout, ExporterOTLP, c.Tracing.Exporter))
+	}
+	if c.Tracing.ServiceName == "" {
+		errs = append(errs, errors.New("tracing.service_name is required"))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("tracing.sample_ratio must be between 0 and 1"))
+	}
+
+	switch c.Mail.Transport {
+	case MailSMTP:
+		if c.Mail.SMTP.Host == "" {
+			errs = append(errs, errors.New("mail.smtp.host is required with the smtp transport"))
+		}
+		if c.Mail.SMTP.Port <= 0 || c.Mail.SMTP.Port > 65535 {
+			errs = append(errs, fmt.Errorf("mail.smtp.port must be between 1 and 65535, not %d", c.Mail.SMTP.Port))
+		}
+	case MailFile:
+		if c.Mail.Dir == "" {
+			errs = append(errs, errors.New("mail.dir is required with the file transport"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("mail.transport must be %s or %s, not %q", MailSMTP, MailFile, c.Mail.Transport))
+	}
+	_, err = mail.ParseAddress(c.Mail.From)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("mail.from: %w", err))
+	}
+	if !strings.Contains(c.Mail.VerifyEmailURL, TokenPlaceholder) {
+		errs = append(errs, fmt.Errorf("mail.verify_email_url must contain %s", TokenPlaceholder))
+	}
+	if !strings.Contains(c.Mail.ResetPasswordURL, TokenPlaceholder) {
+		errs = append(errs, fmt.Errorf("mail.reset_password_url must contain %s", TokenPlaceholder))
+	}
+	if c.OIDC.Enabled {
+		errs = append(errs, c.OIDC.validate()...)
+	}
+	return errors.Join(errs...)
+}
+
+func (o OIDC) validate() []error {
+	var errs []error
+	for _, u := range []struct{ name, url string }{{"oidc.issuer", o.Issuer}, {"oidc.redirect_url", o.RedirectURL}} {
+		parsed, err := url.Parse(u.url)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			errs = append(errs, fmt.Errorf("%s must be an http or https URL, not %q", u.name, u.url))
+		}
+	}
+	if o.ClientID == "" {
+		errs = append(errs, errors.New("oidc.client_id is required"))
+	}
+	if !slices.Contains(o.Scopes, "openid") {
+		errs = append(errs, errors.New("oidc.scopes must include openid"))
+	}
+	// Admins are never created this way
+	if o.DefaultRole != "recruiter" && o.DefaultRole != "candidate" {
+		errs = append(errs, fmt.Errorf("oidc.default_role must be recruiter or candidate, not %q", o.DefaultRole))
+	}
+	return errs
+}
+
+func (l Limit) validate(name string) error {
+	if l.Requests <= 0 || l.Per.Duration <= 0 {
+		return fmt.Errorf("%s must allow a positive number of requests per positive duration", name)
+	}
+	return nil
+}
+
+// LogLevel returns the parsed log level. The config must have been validated.
+func (c Config) LogLevel() zerolog.Level {
+	level, _ := zerolog.ParseLevel(c.Log.Level)
+	return level
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load("")
+	require.NoError(t, err)
+	require.Equal(t, Default(), cfg)
+}
+
+func TestLoadFile(t *testing.T) {
+	tt := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: `
+http:
+  addr: ":9090"
+  read_timeout: 5s
+db:
+  dsn: "host=db"
+  max_open_conns: 50
+log:
+  level: debug
+`,
+		},
+		{
+			name: "toml",
+			file: "config.toml",
+			content: `
+[http]
+addr = ":9090"
+read_timeout = "5s"
+
+[db]
+dsn = "host=db"
+max_open_conns = 50
+
+[log]
+level = "debug"
+`,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := Load(writeFile(t, tc.file, tc.content))
+			require.NoError(t, err)
+
+			want := Default()
+			want.HTTP.Addr = ":9090"
+			want.HTTP.ReadTimeout = Duration{5 * time.Second}
+			want.DB.DSN = "host=db"
+			want.DB.MaxOpenConns = 50
+			want.Log.Level = "debug"
+			require.Equal(t, want, cfg)
+		})
+	}
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	path := writeFile(t, "config.yaml", "http:\n  addr: \":9090\"\n")
+	t.Setenv("JOBPORTAL_HTTP_ADDR", ":7070")
+	t.Setenv("JOBPORTAL_HTTP_WRITE_TIMEOUT", "1m")
+	t.Setenv("JOBPORTAL_DB_MAX_IDLE_CONNS", "5")
+	t.Setenv("JOBPORTAL_DB_MIGRATE_ON_START", "false")
+	t.Setenv("JOBPORTAL_TRACING_EXPORTER", "stdout")
+	t.Setenv("JOBPORTAL_TRACING_SAMPLE_RATIO", "0.25")
+	t.Setenv("JOBPORTAL_MAIL_TRANSPORT", "smtp")
+	t.Setenv("JOBPORTAL_MAIL_SMTP_PORT", "2525")
+	t.Setenv("JOBPORTAL_OIDC_ENABLED", "true")
+	t.Setenv("JOBPORTAL_OIDC_ISSUER", "https://login.example.com")
+	t.Setenv("JOBPORTAL_OIDC_CLIENT_ID", "job-portal")
+	t.Setenv("JOBPORTAL_OIDC_ALLOWED_DOMAINS", "example.com, example.org")
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	require.Equal(t, ":7070", cfg.HTTP.Addr)
+	require.Equal(t, time.Minute, cfg.HTTP.WriteTimeout.Duration)
+	require.Equal(t, 5, cfg.DB.MaxIdleConns)
+	require.False(t, cfg.DB.MigrateOnStart)
+	require.Equal(t, ExporterStdout, cfg.Tracing.Exporter)
+	require.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+	require.Equal(t, MailSMTP, cfg.Mail.Transport)
+	require.Equal(t, 2525, cfg.Mail.SMTP.Port)
+	require.True(t, cfg.OIDC.Enabled)
+	require.Equal(t, "https://login.example.com", cfg.OIDC.Issuer)
+	require.Equal(t, "job-portal", cfg.OIDC.ClientID)
+	require.Equal(t, []string{"example.com", "example.org"}, cfg.OIDC.AllowedDomains)
+	require.Equal(t, []string{"openid", "email", "profile"}, cfg.OIDC.Scopes)
+}
+
+func TestLoadErrors(t *testing.T) {
+	tt := []struct {
+		name string
+		file string
+		env  map[string]string
+	}{
+		{name: "unknown key", file: writeFile(t, "config.yaml", "http:\n  adress: \":9090\"\n")},
+		{name: "unsupported extension", file: writeFile(t, "config.json", "{}")},
+		{name: "missing file", file: filepath.Join(t.TempDir(), "missing.yaml")},
+		{name: "bad duration", env: map[string]string{"JOBPORTAL_HTTP_READ_TIMEOUT": "soon"}},
+		{name: "bad bool", env: map[string]string{"JOBPORTAL_DB_MIGRATE_ON_START": "sometimes"}},
+		{name: "bad number", env: map[string]string{"JOBPORTAL_DB_MAX_OPEN_CONNS": "many"}},
+		{name: "zero timeout", env: map[string]string{"JOBPORTAL_HTTP_IDLE_TIMEOUT": "0s"}},
+		{name: "request timeout past write timeout", env: map[string]string{"JOBPORTAL_HTTP_SEARCH_TIMEOUT": "30s"}},
+		{name: "more idle than open", env: map[string]string{"JOBPORTAL_DB_MAX_OPEN_CONNS": "2", "JOBPORTAL_DB_MAX_IDLE_CONNS": "3"}},
+		{name: "unknown driver", env: map[string]string{"JOBPORTAL_DB_DRIVER": "mysql"}},
+		{name: "empty dsn", env: map[string]string{"JOBPORTAL_DB_DSN": ""}},
+		{name: "bad log level", env: map[string]string{"JOBPORTAL_LOG_LEVEL": "loud"}},
+		{name: "unknown exporter", env: map[string]string{"JOBPORTAL_TRACING_EXPORTER": "jaeger"}},
+		{name: "otlp without endpoint", env: map[string]string{"JOBPORTAL_TRACING_EXPORTER": "otlp", "JOBPORTAL_TRACING_ENDPOINT": ""}},
+		{name: "bad sample ratio", env: map[string]string{"JOBPORTAL_TRACING_SAMPLE_RATIO": "half"}},
+		{name: "sample ratio above one", env: map[string]string{"JOBPORTAL_TRACING_SAMPLE_RATIO": "1.5"}},
+		{name: "short token secret", env: map[string]string{"JOBPORTAL_AUTH_TOKEN_SECRET": "secret"}},
+		{name: "zero reset ttl", env: map[string]string{"JOBPORTAL_AUTH_RESET_PASSWORD_TTL": "0s"}},
+		{name: "totp issuer with colon", env: map[string]string{"JOBPORTAL_AUTH_TOTP_ISSUER": "Job:Portal"}},
+		{name: "zero mfa challenge ttl", env: map[string]string{"JOBPORTAL_AUTH_MFA_CHALLENGE_TTL": "0s"}},
+		{name: "unknown mail transport", env: map[string]string{"JOBPORTAL_MAIL_TRANSPORT": "pigeon"}},
+		{name: "smtp without host", env: map[string]string{"JOBPORTAL_MAIL_TRANSPORT": "smtp", "JOBPORTAL_MAIL_SMTP_HOST": ""}},
+		{name: "bad smtp port", env: map[string]string{"JOBPORTAL_MAIL_TRANSPORT": "smtp", "JOBPORTAL_MAIL_SMTP_PORT": "70000"}},
+		{name: "bad from address", env: map[string]string{"JOBPORTAL_MAIL_FROM": "nobody"}},
+		{name: "link without token", env: map[string]string{"JOBPORTAL_MAIL_VERIFY_EMAIL_URL": "http://localhost/verify"}},
+		{name: "oidc without issuer", env: map[string]string{"JOBPORTAL_OIDC_ENABLED": "true", "JOBPORTAL_OIDC_CLIENT_ID": "job-portal"}},
+		{name: "oidc without client id", env: map[string]string{"JOBPORTAL_OIDC_ENABLED": "true", "JOBPORTAL_OIDC_ISSUER": "https://login.example.com"}},
+		{name: "oidc issuer not a url", env: map[string]string{"JOBPORTAL_OIDC_ENABLED": "true", "JOBPORTAL_OIDC_ISSUER": "login.example.com", "JOBPORTAL_OIDC_CLIENT_ID": "job-portal"}},
+		{name: "oidc without openid scope", env: map[string]string{"JOBPORTAL_OIDC_ENABLED": "true", "JOBPORTAL_OIDC_ISSUER": "https://login.example.com", "JOBPORTAL_OIDC_CLIENT_ID": "job-portal", "JOBPORTAL_OIDC_SCOPES": "email"}},
+		{name: "oidc provisions admins", env: map[string]string{"JOBPORTAL_OIDC_ENABLED": "true", "JOBPORTAL_OIDC_ISSUER": "https://login.example.com", "JOBPORTAL_OIDC_CLIENT_ID": "job-portal", "JOBPORTAL_OIDC_DEFAULT_ROLE": "admin"}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			_, err := Load(tc.file)
+			require.Error(t, err)
+		})
+	}
+}
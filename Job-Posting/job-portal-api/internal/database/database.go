@@ -1,15 +1,79 @@
 package database
 
 import (
+	"fmt"
+	"job-portal-api/internal/config"
+	"job-portal-api/internal/tracing"
+	"strings"
+
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func Open() (*gorm.DB, error) {
-	dsn := "host=localhost user=postgres password=admin dbname=postgres port=5432 sslmode=disable TimeZone=Asia/Shanghai"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// Open connects to the database selected by cfg.Driver and sizes the connection pool from cfg.
+// Driver errors are translated, so a unique violation is reported as gorm.ErrDuplicatedKey whatever the driver.
+// Every statement is traced as a child of the span in its context.
+func Open(cfg config.DB) (*gorm.DB, error) {
+	switch cfg.Driver {
+	case config.DriverSQLite:
+		return openSQLite(cfg)
+	case config.DriverPostgres, "":
+		return openPostgres(cfg)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+	}
+}
+
+func openPostgres(cfg config.DB) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(cfg.DSN), &gorm.Config{TranslateError: true})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Use(tracing.GormPlugin{})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
 	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime.Duration)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime.Duration)
 	return db, nil
 }
+
+// openSQLite opens a pure Go SQLite database, meant for local development and tests. Foreign keys are enforced
+// like on Postgres. The pool is limited to a single connection that is never closed: SQLite allows only one
+// writer at a time anyway, and an in-memory database disappears with its last connection.
+func openSQLite(cfg config.DB) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(sqliteDSN(cfg.DSN)), &gorm.Config{TranslateError: true})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Use(tracing.GormPlugin{})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetMaxIdleConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+	sqlDB.SetConnMaxIdleTime(0)
+	return db, nil
+}
+
+func sqliteDSN(dsn string) string {
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	return dsn + sep + "_pragma=foreign_keys(1)"
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"job-portal-api/internal/auth"
+	middlewares "job-portal-api/internal/middleware"
+	"job-portal-api/internal/problem"
+	"job-portal-api/internal/validate"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// verifyEmailPage is shown when the emailed link is opened. The form posts to the same URL, token included, so the
+// token is only used when the user presses the button and not when a mail scanner follows the link.
+const verifyEmailPage = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="robots" content="noindex"><title>Verify your email address</title></head>
+<body>
+<form method="post"><button type="submit">Verify my email address</button></form>
+</body>
+</html>
+`
+
+// emailVerifiedPage is shown to the browser once the email address is verified.
+const emailVerifiedPage = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Email address verified</title></head>
+<body><p>Your email address is verified, you can close this page.</p></body>
+</html>
+`
+
+// ShowVerifyEmail answers the link emailed on registration with a page asking to confirm, without using its token
+func (h *handler) ShowVerifyEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	var req struct {
+		Token string `form:"token" validate:"required"`
+	}
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "please provide token")
+		return
+	}
+	err = validate.Struct(req)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(verifyEmailPage))
+}
+
+// VerifyEmail redeems the token of the link emailed on registration and marks the email address as verified. Browsers
+// submitting the page of ShowVerifyEmail are told so with a page, other clients get no content.
+func (h *handler) VerifyEmail(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	// The token stays in the query string of the emailed link, which the page of ShowVerifyEmail posts back to
+	var req struct {
+		Token string `form:"token" validate:"required"`
+	}
+	err := c.ShouldBindQuery(&req)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "please provide token")
+		return
+	}
+	err = validate.Struct(req)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	err = h.s.VerifyEmail(ctx, req.Token)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(emailVerifiedPage))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ResendVerification emails a new verification link to the authenticated user
+func (h *handler) ResendVerification(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceId).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
+		return
+	}
+
+	err := h.s.SendVerificationEmail(ctx, claims.Subject)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// RequestPasswordReset emails a password reset link. The response is the same whether the email is registered or
+// not, so the endpoint can't be used to find out who has an account.
+func (h *handler) RequestPasswordReset(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	var req struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+	err := json.NewDecoder(c.Request.Body).Decode(&req)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "please provide email")
+		return
+	}
+	err = validate.Struct(req)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	err = h.s.RequestPasswordReset(ctx, req.Email)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// ResetPassword redeems the token of a password reset link and sets the new password
+func (h *handler) ResetPassword(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	var req struct {
+		Token    string `json:"token" validate:"required"`
+		Password string `json:"password" validate:"required,password"`
+	}
+	err := json.NewDecoder(c.Request.Body).Decode(&req)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "please provide token and password")
+		return
+	}
+	err = validate.Struct(req)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	err = h.s.ResetPassword(ctx, req.Token, req.Password)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"job-portal-api/internal/auth"
+	middlewares "job-portal-api/internal/middleware"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccountHandlers(t *testing.T) {
+	// Sets the Gin router mode to test.
+	gin.SetMode(gin.TestMode)
+
+	claims := auth.NewClaims("7", models.RoleRecruiter)
+
+	// Define the list of test cases
+	testCases := []struct {
+		name             string                        // Name of the test case
+		method           string                        // Method of the request
+		url              string                        // URL of the request
+		body             string                        // Body to send to request
+		accept           string                        // Accept header of the request
+		claims           *auth.Claims                  // Claims of the caller, nil when not logged in
+		expectedStatus   int                           // Expected status of the response
+		expectedResponse string                        // Expected response body
+		mockService      func(m *services.MockService) // Mock service function
+	}{
+		{
+			name:             "ShowVerifyEmail_OK",
+			method:           http.MethodGet,
+			url:              "/api/verify-email?token=signed-token",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: verifyEmailPage,
+			mockService: func(m *services.MockService) {
+				// Mail scanners follow links, so opening it must not use the token
+				m.EXPECT().VerifyEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "ShowVerifyEmail_Fail_NoToken",
+			method:           http.MethodGet,
+			url:              "/api/verify-email",
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/verify-email","trace_id":"fake-trace-id","errors":[{"field":"token","rule":"required","message":"is required"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().VerifyEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:           "VerifyEmail_OK",
+			method:         http.MethodPost,
+			url:            "/api/verify-email?token=signed-token",
+			expectedStatus: http.StatusNoContent,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().VerifyEmail(gomock.Any(), gomock.Eq("signed-token")).Times(1).Return(nil)
+			},
+		},
+		{
+			name:             "VerifyEmail_OK_Browser",
+			method:           http.MethodPost,
+			url:              "/api/verify-email?token=signed-token",
+			accept:           "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: emailVerifiedPage,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().VerifyEmail(gomock.Any(), gomock.Eq("signed-token")).Times(1).Return(nil)
+			},
+		},
+		{
+			name:             "VerifyEmail_Fail_InvalidToken",
+			method:           http.MethodPost,
+			url:              "/api/verify-email?token=used-token",
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid or expired link, ask for a new one","instance":"/api/verify-email","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().VerifyEmail(gomock.Any(), gomock.Any()).Times(1).Return(services.ErrInvalidEmailToken)
+			},
+		},
+		{
+			name:             "VerifyEmail_Fail_NoToken",
+			method:           http.MethodPost,
+			url:              "/api/verify-email",
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/verify-email","trace_id":"fake-trace-id","errors":[{"field":"token","rule":"required","message":"is required"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().VerifyEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:           "ResendVerification_OK",
+			method:         http.MethodPost,
+			url:            "/api/verify-email/resend",
+			claims:         &claims,
+			expectedStatus: http.StatusAccepted,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().SendVerificationEmail(gomock.Any(), gomock.Eq("7")).Times(1).Return(nil)
+			},
+		},
+		{
+			name:             "ResendVerification_Fail_NotLoggedIn",
+			method:           http.MethodPost,
+			url:              "/api/verify-email/resend",
+			expectedStatus:   http.StatusUnauthorized,
+			expectedResponse: `{"type":"about:blank","title":"Unauthorized","status":401,"instance":"/api/verify-email/resend","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().SendVerificationEmail(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:           "RequestPasswordReset_OK",
+			method:         http.MethodPost,
+			url:            "/api/password-reset",
+			body:           `{"email":"stym@email.com"}`,
+			expectedStatus: http.StatusAccepted,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().RequestPasswordReset(gomock.Any(), gomock.Eq("stym@email.com")).Times(1).Return(nil)
+			},
+		},
+		{
+			name:             "RequestPasswordReset_Fail_InvalidEmail",
+			method:           http.MethodPost,
+			url:              "/api/password-reset",
+			body:             `{"email":"stym"}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/password-reset","trace_id":"fake-trace-id","errors":[{"field":"email","rule":"email","message":"must be a valid email address"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().RequestPasswordReset(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "RequestPasswordReset_Fail_MailerDown",
+			method:           http.MethodPost,
+			url:              "/api/password-reset",
+			body:             `{"email":"stym@email.com"}`,
+			expectedStatus:   http.StatusInternalServerError,
+			expectedResponse: `{"type":"about:blank","title":"Internal Server Error","status":500,"instance":"/api/password-reset","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().RequestPasswordReset(gomock.Any(), gomock.Any()).Times(1).Return(errors.New("connection refused"))
+			},
+		},
+		{
+			name:           "ResetPassword_OK",
+			method:         http.MethodPost,
+			url:            "/api/password-reset/confirm",
+			body:           `{"token":"signed-token","password":"N3wPassword"}`,
+			expectedStatus: http.StatusNoContent,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().ResetPassword(gomock.Any(), gomock.Eq("signed-token"), gomock.Eq("N3wPassword")).Times(1).Return(nil)
+			},
+		},
+		{
+			name:             "ResetPassword_Fail_WeakPassword",
+			method:           http.MethodPost,
+			url:              "/api/password-reset/confirm",
+			body:             `{"token":"signed-token","password":"password"}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/password-reset/confirm","trace_id":"fake-trace-id","errors":[{"field":"password","rule":"password","message":"must be 8 to 72 characters long and contain an upper case letter, a lower case letter and a digit"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().ResetPassword(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "ResetPassword_Fail_InvalidToken",
+			method:           http.MethodPost,
+			url:              "/api/password-reset/confirm",
+			body:             `{"token":"used-token","password":"N3wPassword"}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid or expired link, ask for a new one","instance":"/api/password-reset/confirm","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().ResetPassword(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(services.ErrInvalidEmailToken)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Gomock controller.
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+
+			// Apply the mock to the user service.
+			tc.mockService(mockS)
+
+			// Insert the TraceId, and the claims of a logged in caller, into the context.
+			ctx := context.WithValue(context.Background(), middlewares.TraceIdKey, "fake-trace-id")
+			if tc.claims != nil {
+				ctx = context.WithValue(ctx, auth.Key, *tc.claims)
+			}
+
+			router := gin.New()
+			h := handler{s: mockS}
+			router.GET("/api/verify-email", h.ShowVerifyEmail)
+			router.POST("/api/verify-email", h.VerifyEmail)
+			router.POST("/api/verify-email/resend", h.ResendVerification)
+			router.POST("/api/password-reset", h.RequestPasswordReset)
+			router.POST("/api/password-reset/confirm", h.ResetPassword)
+
+			req, err := http.NewRequestWithContext(ctx, tc.method, tc.url, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			// Assert the returned HTTP status code and body are as expected.
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			require.Equal(t, tc.expectedResponse, resp.Body.String())
+		})
+	}
+}
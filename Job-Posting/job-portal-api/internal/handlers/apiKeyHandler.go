@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"job-portal-api/internal/auth"
+	middlewares "job-portal-api/internal/middleware"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/problem"
+	"job-portal-api/internal/validate"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// CreateAPIKey creates an API key for an integration to act on the company. The key is only shown in this response
+func (h *handler) CreateAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceId).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
+		return
+	}
+
+	companyID, err := strconv.ParseUint(c.Param("companyID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid company ID")
+		return
+	}
+
+	var nk models.NewAPIKey
+	err = json.NewDecoder(c.Request.Body).Decode(&nk)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Msg("failed to parse request body")
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid request body")
+		return
+	}
+	err = validate.Struct(nk)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	key, err := h.s.CreateAPIKey(ctx, uint(companyID), nk, claims.Subject)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// ListAPIKeys lists the API keys of the company, without the keys themselves
+func (h *handler) ListAPIKeys(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceId).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
+		return
+	}
+
+	companyID, err := strconv.ParseUint(c.Param("companyID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid company ID")
+		return
+	}
+
+	keys, err := h.s.ListAPIKeys(ctx, uint(companyID), claims.Subject)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey revokes an API key of the company
+func (h *handler) RevokeAPIKey(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceId).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
+		return
+	}
+
+	companyID, err := strconv.ParseUint(c.Param("companyID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid company ID")
+		return
+	}
+	keyID, err := strconv.ParseUint(c.Param("keyID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid key ID")
+		return
+	}
+
+	err = h.s.RevokeAPIKey(ctx, uint(companyID), uint(keyID), claims.Subject)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"job-portal-api/internal/auth"
+	middlewares "job-portal-api/internal/middleware"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyHandlers(t *testing.T) {
+	// Sets the Gin router mode to test.
+	gin.SetMode(gin.TestMode)
+
+	claims := auth.NewClaims("7", models.RoleRecruiter)
+	createdAt := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	key := models.APIKey{
+		ID:        4,
+		CreatedAt: createdAt,
+		CompanyID: 3,
+		CreatedBy: 7,
+		Name:      "ats",
+		Prefix:    "jpk_abcdefgh",
+		KeyHash:   "hash",
+		Scopes:    []string{models.ScopeApplicationsRead},
+	}
+
+	// Define the list of test cases
+	testCases := []struct {
+		name             string                        // Name of the test case
+		method           string                        // Method of the request
+		url              string                        // URL of the request
+		body             string                        // Body to send to request
+		expectedStatus   int                           // Expected status of the response
+		expectedResponse string                        // Expected response body
+		mockService      func(m *services.MockService) // Mock service function
+	}{
+		{
+			name:             "CreateAPIKey_OK",
+			method:           http.MethodPost,
+			url:              "/api/companies/3/api-keys",
+			body:             `{"name":"ats","scopes":["applications:read"]}`,
+			expectedStatus:   http.StatusCreated,
+			expectedResponse: `{"id":4,"created_at":"2026-10-01T09:00:00Z","company_id":3,"created_by":7,"name":"ats","prefix":"jpk_abcdefgh","scopes":["applications:read"],"key":"jpk_abcdefghijkl"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CreateAPIKey(gomock.Any(), gomock.Eq(uint(3)), gomock.Eq(models.NewAPIKey{Name: "ats", Scopes: []string{models.ScopeApplicationsRead}}), gomock.Eq("7")).
+					Times(1).Return(models.CreatedAPIKey{APIKey: key, Key: "jpk_abcdefghijkl"}, nil)
+			},
+		},
+		{
+			name:             "CreateAPIKey_Fail_UnknownScope",
+			method:           http.MethodPost,
+			url:              "/api/companies/3/api-keys",
+			body:             `{"name":"ats","scopes":["companies:write"]}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/companies/3/api-keys","trace_id":"fake-trace-id","errors":[{"field":"scopes[0]","rule":"oneof","message":"must be one of jobs:read, jobs:write, applications:read, applications:write"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "CreateAPIKey_Fail_NotOwner",
+			method:           http.MethodPost,
+			url:              "/api/companies/3/api-keys",
+			body:             `{"name":"ats","scopes":["jobs:read"]}`,
+			expectedStatus:   http.StatusForbidden,
+			expectedResponse: `{"type":"about:blank","title":"Forbidden","status":403,"detail":"you do not own this company","instance":"/api/companies/3/api-keys","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(models.CreatedAPIKey{}, &services.Error{Kind: services.ErrForbidden, Detail: "you do not own this company"})
+			},
+		},
+		{
+			name:             "ListAPIKeys_OK",
+			method:           http.MethodGet,
+			url:              "/api/companies/3/api-keys",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `[{"id":4,"created_at":"2026-10-01T09:00:00Z","company_id":3,"created_by":7,"name":"ats","prefix":"jpk_abcdefgh","scopes":["applications:read"]}]`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().ListAPIKeys(gomock.Any(), gomock.Eq(uint(3)), gomock.Eq("7")).Times(1).Return([]models.APIKey{key}, nil)
+			},
+		},
+		{
+			name:           "RevokeAPIKey_OK",
+			method:         http.MethodDelete,
+			url:            "/api/companies/3/api-keys/4",
+			expectedStatus: http.StatusNoContent,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().RevokeAPIKey(gomock.Any(), gomock.Eq(uint(3)), gomock.Eq(uint(4)), gomock.Eq("7")).Times(1).Return(nil)
+			},
+		},
+		{
+			name:             "RevokeAPIKey_Fail_NotFound",
+			method:           http.MethodDelete,
+			url:              "/api/companies/3/api-keys/5",
+			expectedStatus:   http.StatusNotFound,
+			expectedResponse: `{"type":"about:blank","title":"Not Found","status":404,"detail":"api key not found","instance":"/api/companies/3/api-keys/5","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().RevokeAPIKey(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(&services.Error{Kind: services.ErrNotFound, Detail: "api key not found"})
+			},
+		},
+		{
+			name:             "RevokeAPIKey_Fail_InvalidKeyID",
+			method:           http.MethodDelete,
+			url:              "/api/companies/3/api-keys/abc",
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"Invalid key ID","instance":"/api/companies/3/api-keys/abc","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().RevokeAPIKey(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Gomock controller.
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+
+			// Apply the mock to the user service.
+			tc.mockService(mockS)
+
+			// Insert the TraceId and the claims of the logged in recruiter into the context.
+			ctx := context.WithValue(context.Background(), middlewares.TraceIdKey, "fake-trace-id")
+			ctx = context.WithValue(ctx, auth.Key, claims)
+
+			router := gin.New()
+			h := handler{s: mockS}
+			router.POST("/api/companies/:companyID/api-keys", h.CreateAPIKey)
+			router.GET("/api/companies/:companyID/api-keys", h.ListAPIKeys)
+			router.DELETE("/api/companies/:companyID/api-keys/:keyID", h.RevokeAPIKey)
+
+			req, err := http.NewRequestWithContext(ctx, tc.method, tc.url, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			// Assert the returned HTTP status code and body are as expected.
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			require.Equal(t, tc.expectedResponse, resp.Body.String())
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"job-portal-api/internal/auth"
+	middlewares "job-portal-api/internal/middleware"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/problem"
+	"job-portal-api/internal/validate"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Apply submits the logged-in candidate's application to the job in the URL
+func (h *handler) Apply(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceId).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
+		return
+	}
+
+	jobID, err := strconv.ParseUint(c.Param("jobID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid job ID")
+		return
+	}
+
+	var na models.NewApplication
+	err = json.NewDecoder(c.Request.Body).Decode(&na)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Msg("failed to parse request body")
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid request body")
+		return
+	}
+	err = validate.Struct(na)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	application, err := h.s.Apply(ctx, jobID, na, claims.Subject)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, application)
+}
+
+// MyApplications lists the applications of the logged-in candidate
+func (h *handler) MyApplications(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceId).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
+		return
+	}
+
+	applications, err := h.s.MyApplications(ctx, claims.Subject)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, applications)
+}
+
+// JobApplications lists the applications to a job for the owner of the company that posted it
+func (h *handler) JobApplications(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceId).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
+		return
+	}
+
+	jobID, err := strconv.ParseUint(c.Param("jobID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid job ID")
+		return
+	}
+
+	applications, err := h.s.JobApplications(ctx, jobID, claims.Subject)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, applications)
+}
+
+// TransitionApplication moves an application to another stage of the hiring pipeline
+func (h *handler) TransitionApplication(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceId).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
+		return
+	}
+
+	applicationID, err := strconv.ParseUint(c.Param("applicationID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid application ID")
+		return
+	}
+
+	var nc models.NewStatusChange
+	err = json.NewDecoder(c.Request.Body).Decode(&nc)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Msg("failed to parse request body")
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid request body")
+		return
+	}
+	err = validate.Struct(nc)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	application, err := h.s.TransitionApplication(ctx, applicationID, nc, claims.Subject)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, application)
+}
+
+// ApplicationTimeline returns the history of stage changes of an application
+func (h *handler) ApplicationTimeline(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceId).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
+		return
+	}
+
+	applicationID, err := strconv.ParseUint(c.Param("applicationID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid application ID")
+		return
+	}
+
+	timeline, err := h.s.ApplicationTimeline(ctx, applicationID, claims.Subject)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
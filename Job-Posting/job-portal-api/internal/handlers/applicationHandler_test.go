@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"job-portal-api/internal/auth"
+	middlewares "job-portal-api/internal/middleware"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestApply(t *testing.T) {
+	// Sets the Gin router mode to test.
+	gin.SetMode(gin.TestMode)
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "2",
+		},
+		Roles: []string{models.RoleCandidate},
+	}
+
+	na := models.NewApplication{
+		CoverLetter: "hire me",
+		ResumeURL:   "https://example.com/resume.pdf",
+	}
+	mockApplication := models.Application{
+		Model: gorm.Model{
+			ID:        1,
+			CreatedAt: time.Date(2006, 1, 1, 1, 1, 1, 1, time.UTC),
+			UpdatedAt: time.Date(2006, 1, 1, 1, 1, 1, 1, time.UTC),
+		},
+		JobID:       1,
+		UserID:      2,
+		CoverLetter: na.CoverLetter,
+		ResumeURL:   na.ResumeURL,
+		Status:      models.ApplicationStatusApplied,
+	}
+
+	// Define the list of test cases
+	testCases := []struct {
+		name             string                        // Name of the test case
+		body             string                        // Body to send to request
+		expectedStatus   int                           // Expected status of the response
+		expectedResponse string                        // Expected response body
+		mockService      func(m *services.MockService) // Mock service function
+	}{
+		{
+			name:             "OK",
+			body:             `{"cover_letter":"hire me","resume_url":"https://example.com/resume.pdf"}`,
+			expectedStatus:   http.StatusCreated,
+			expectedResponse: `{"ID":1,"CreatedAt":"2006-01-01T01:01:01.000000001Z","UpdatedAt":"2006-01-01T01:01:01.000000001Z","DeletedAt":null,"job_id":1,"user_id":2,"cover_letter":"hire me","resume_url":"https://example.com/resume.pdf","status":"applied"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().Apply(gomock.Any(), gomock.Eq(uint64(1)), gomock.Eq(na), gomock.Eq("2")).Times(1).
+					Return(mockApplication, nil)
+			},
+		},
+		{
+			name:             "Fail_InvalidResumeURL",
+			body:             `{"resume_url":"not a url"}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/jobs/1","trace_id":"fake-trace-id","errors":[{"field":"resume_url","rule":"httpurl","message":"must be an absolute http or https URL"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().Apply(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_JobNotFound",
+			body:             `{"resume_url":"https://example.com/resume.pdf"}`,
+			expectedStatus:   http.StatusNotFound,
+			expectedResponse: `{"type":"about:blank","title":"Not Found","status":404,"detail":"job not found","instance":"/api/jobs/1","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().Apply(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(models.Application{}, &services.Error{Kind: services.ErrNotFound, Detail: "job not found"})
+			},
+		},
+		{
+			name:             "Fail_AlreadyApplied",
+			body:             `{"resume_url":"https://example.com/resume.pdf"}`,
+			expectedStatus:   http.StatusConflict,
+			expectedResponse: `{"type":"about:blank","title":"Conflict","status":409,"detail":"already applied to this job","instance":"/api/jobs/1","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().Apply(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(models.Application{}, services.ErrAlreadyApplied)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Gomock controller.
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+
+			// Apply the mock to the user service.
+			tc.mockService(mockS)
+
+			// Insert the claims and TraceId into the context.
+			ctx := context.WithValue(context.Background(), auth.Key, fakeClaims)
+			ctx = context.WithValue(ctx, middlewares.TraceIdKey, "fake-trace-id")
+
+			router := gin.New()
+			h := handler{s: mockS}
+			router.POST("/api/jobs/:jobID", h.Apply)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/api/jobs/1", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			// Assert the returned HTTP status code and body are as expected.
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			require.Equal(t, tc.expectedResponse, resp.Body.String())
+		})
+	}
+}
+
+func TestJobApplications(t *testing.T) {
+	// Sets the Gin router mode to test.
+	gin.SetMode(gin.TestMode)
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleRecruiter},
+	}
+
+	// Define the list of test cases
+	testCases := []struct {
+		name             string                        // Name of the test case
+		expectedStatus   int                           // Expected status of the response
+		expectedResponse string                        // Expected response body
+		mockService      func(m *services.MockService) // Mock service function
+	}{
+		{
+			name:             "OK",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `[{"ID":1,"CreatedAt":"0001-01-01T00:00:00Z","UpdatedAt":"0001-01-01T00:00:00Z","DeletedAt":null,"job_id":1,"user_id":2,"cover_letter":"","resume_url":"https://example.com/resume.pdf","status":"applied"}]`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().JobApplications(gomock.Any(), gomock.Eq(uint64(1)), gomock.Eq("1")).Times(1).
+					Return([]models.Application{{
+						Model:     gorm.Model{ID: 1},
+						JobID:     1,
+						UserID:    2,
+						ResumeURL: "https://example.com/resume.pdf",
+						Status:    models.ApplicationStatusApplied,
+					}}, nil)
+			},
+		},
+		{
+			name:             "Forbidden - Not Company Owner",
+			expectedStatus:   http.StatusForbidden,
+			expectedResponse: `{"type":"about:blank","title":"Forbidden","status":403,"detail":"you do not own this company","instance":"/api/jobs/1/applications","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().JobApplications(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, &services.Error{Kind: services.ErrForbidden, Detail: "you do not own this company"})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Gomock controller.
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+
+			// Apply the mock to the user service.
+			tc.mockService(mockS)
+
+			// Insert the claims and TraceId into the context.
+			ctx := context.WithValue(context.Background(), auth.Key, fakeClaims)
+			ctx = context.WithValue(ctx, middlewares.TraceIdKey, "fake-trace-id")
+
+			router := gin.New()
+			h := handler{s: mockS}
+			router.GET("/api/jobs/:jobID/applications", h.JobApplications)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/api/jobs/1/applications", nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			// Assert the returned HTTP status code and body are as expected.
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			require.Equal(t, tc.expectedResponse, resp.Body.String())
+		})
+	}
+}
+
+func TestTransitionApplication(t *testing.T) {
+	// Sets the Gin router mode to test.
+	gin.SetMode(gin.TestMode)
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleRecruiter},
+	}
+
+	// Define the list of test cases
+	testCases := []struct {
+		name             string                        // Name of the test case
+		body             string                        // Body to send to request
+		expectedStatus   int                           // Expected status of the response
+		expectedResponse string                        // Expected response body
+		mockService      func(m *services.MockService) // Mock service function
+	}{
+		{
+			name:             "OK",
+			body:             `{"status":"screening","note":"strong resume"}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"ID":3,"CreatedAt":"0001-01-01T00:00:00Z","UpdatedAt":"0001-01-01T00:00:00Z","DeletedAt":null,"job_id":1,"user_id":2,"cover_letter":"","resume_url":"","status":"screening"}`,
+			mockService: func(m *services.MockService) {
+				nc := models.NewStatusChange{Status: models.ApplicationStatusScreening, Note: "strong resume"}
+				m.EXPECT().TransitionApplication(gomock.Any(), gomock.Eq(uint64(3)), gomock.Eq(nc), gomock.Eq("1")).Times(1).
+					Return(models.Application{Model: gorm.Model{ID: 3}, JobID: 1, UserID: 2, Status: models.ApplicationStatusScreening}, nil)
+			},
+		},
+		{
+			name:             "Fail_InvalidTransition",
+			body:             `{"status":"hired"}`,
+			expectedStatus:   http.StatusUnprocessableEntity,
+			expectedResponse: `{"type":"about:blank","title":"Unprocessable Entity","status":422,"detail":"an application can't be moved from applied to hired","instance":"/api/applications/3/transitions","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().TransitionApplication(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(models.Application{}, &services.Error{Kind: services.ErrInvalidTransition, Detail: "an application can't be moved from applied to hired"})
+			},
+		},
+		{
+			name:             "Fail_NoStatus",
+			body:             `{}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/applications/3/transitions","trace_id":"fake-trace-id","errors":[{"field":"status","rule":"required","message":"is required"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().TransitionApplication(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Gomock controller.
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+
+			// Apply the mock to the user service.
+			tc.mockService(mockS)
+
+			// Insert the claims and TraceId into the context.
+			ctx := context.WithValue(context.Background(), auth.Key, fakeClaims)
+			ctx = context.WithValue(ctx, middlewares.TraceIdKey, "fake-trace-id")
+
+			router := gin.New()
+			h := handler{s: mockS}
+			router.POST("/api/applications/:applicationID/transitions", h.TransitionApplication)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/api/applications/3/transitions", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			// Assert the returned HTTP status code and body are as expected.
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			require.Equal(t, tc.expectedResponse, resp.Body.String())
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"job-portal-api/internal/problem"
+	"job-portal-api/internal/services"
+	"job-portal-api/internal/validate"
+)
+
+// abortWithError logs err and ends the request with the problem details matching it. Domain errors from the
+// services get the status of their kind and their own detail, validation errors are a 400 listing the failing
+// fields. Any other error is a 500 whose cause is only logged.
+func abortWithError(c *gin.Context, traceId string, err error) {
+	var ve validate.Errors
+	if errors.As(err, &ve) {
+		log.Error().Err(err).Str("Trace Id", traceId).Int("Status", http.StatusBadRequest).Send()
+		problem.AbortInvalid(c, traceId, ve)
+		return
+	}
+
+	status := errorStatus(c.Request.Context(), err)
+	log.Error().Err(err).Str("Trace Id", traceId).Int("Status", status).Send()
+
+	detail := ""
+	var de *services.Error
+	switch {
+	case errors.As(err, &de):
+		detail = de.Detail
+		if de.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(de.RetryAfter.Seconds())))
+		}
+	case status < http.StatusInternalServerError:
+		detail = err.Error()
+	}
+	problem.Abort(c, status, traceId, detail)
+}
+
+func errorStatus(ctx context.Context, err error) int {
+	switch {
+	case timedOut(ctx, err):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, services.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInvalidTransition):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrTooManyRequests):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// timedOut reports whether err comes from the request running past the deadline set by middlewares.Timeout.
+func timedOut(ctx context.Context, err error) bool {
+	return err != nil && (errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded))
+}
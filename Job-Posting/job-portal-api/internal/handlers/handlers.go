@@ -5,8 +5,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 	"job-portal-api/internal/auth"
+	"job-portal-api/internal/config"
+	"job-portal-api/internal/health"
+	"job-portal-api/internal/metrics"
 	"job-portal-api/internal/middleware"
-	"job-portal-api/internal/repository"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/problem"
+	"job-portal-api/internal/ratelimit"
 	"job-portal-api/internal/services"
 
 	"time"
@@ -15,20 +20,15 @@ import (
 )
 
 // Define a function called API that takes an argument a of type *auth.Auth
-// and returns a pointer to a gin.Engine
+// and returns a pointer to a gin.Engine. Every route is bounded by one of the request timeouts in cfg.
+// The readiness probe reports the checks registered on hc.
 
-func API(a *auth.Auth, c *repository.Repo) *gin.Engine {
+func API(a *auth.Auth, s services.Service, cfg config.HTTP, hc *health.Checker) *gin.Engine {
 
 	// Create a new Gin engine; Gin is a HTTP web framework written in Go
 	r := gin.New()
 
 	m, err := middlewares.NewMid(a)
-	ms, err := services.NewStore(c)
-
-	h := handler{
-		s: ms,
-		a: a,
-	}
 
 	// If there is an error in setting up the middleware, panic and stop the application
 	// then log the error message
@@ -36,22 +36,88 @@ func API(a *auth.Auth, c *repository.Repo) *gin.Engine {
 		log.Panic().Msg("middlewares not set up")
 	}
 
-	// Attach middleware's Log function and Gin's Recovery middleware to our application
+	h := handler{
+		s:  s,
+		a:  a,
+		hc: hc,
+	}
+
+	// The client IP is only read from X-Forwarded-For when the request comes through one of the trusted proxies
+	err = r.SetTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		log.Panic().Err(err).Msg("trusted proxies not set up")
+	}
+	if cfg.RateLimit.Enabled {
+		m.SetRateLimiter(ratelimit.New(cfg.RateLimit))
+	}
+	// Integrations authenticate with the API keys of their company, on the routes that list scopes
+	m.SetAPIKeys(s)
+
+	// Attach middleware's Log and Metrics functions and Gin's Recovery middleware to our application
 	// The Recovery middleware recovers from any panics and writes a 500 HTTP response if there was one.
-	r.Use(m.Log(), gin.Recovery())
+	// It runs after Metrics so that requests which panicked are counted with their 500.
+	// Rate limiting runs after Metrics so that refused requests are counted with their 429.
+	r.Use(m.Log(), m.Metrics(), gin.CustomRecovery(recovered), m.RateLimit())
+	r.NoRoute(func(c *gin.Context) {
+		traceId, _ := c.Request.Context().Value(middlewares.TraceIdKey).(string)
+		problem.Abort(c, http.StatusNotFound, traceId, "")
+	})
+
+	// The job listing and search routes run the heaviest queries and get a longer deadline than the rest
+	timeout, search := cfg.RequestTimeout.Duration, cfg.SearchTimeout.Duration
 
 	// Define a route at path "/check"
 	// If it receives a GET request, it will use the m.Authenticate(check) function.
 	r.GET("api/check", m.Authenticate(check))
-	r.POST("api/register", h.Register)
-	r.POST("api/login", h.Login)
-	r.POST("/api/companies", m.Authenticate(h.AddCompanies))
-	r.GET("/api/view", m.Authenticate(h.ViewCompanies))
-	r.GET("/api/companies/:companyID", m.Authenticate(h.ViewCompaniesById))
-	r.POST("/companies/:companyID/jobs", m.Authenticate(h.CreateJob))
-	r.GET("api/companies/:companyID/list-jobs", m.Authenticate(h.ListJobs))
-	r.GET("api/jobs", m.Authenticate(h.AllJobs))
-	r.GET("/api/jobs/:jobID", m.Authenticate(h.JobsByID))
+	// Metrics are scraped by Prometheus and are not behind authentication, keep the port off the public internet
+	// or filter /metrics in the proxy in front of the API.
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	// The probes are not behind authentication either, and the readiness checks bound themselves
+	r.GET("/healthz", h.Healthz)
+	r.GET("/readyz", h.Readyz)
+	r.GET("/.well-known/jwks.json", m.Timeout(h.JWKS, timeout))
+	r.POST("api/register", m.Timeout(h.Register, timeout))
+	r.POST("api/login", m.Timeout(h.Login, timeout))
+	r.PUT("/api/users/:userID/role", m.Timeout(m.Authenticate(m.Authorize(h.SetUserRole, models.RoleAdmin)), timeout))
+	r.POST("/api/login/mfa", m.Timeout(h.LoginMFA, timeout))
+	r.GET("/api/oidc/login", m.Timeout(h.OIDCLogin, timeout))
+	r.GET("/api/oidc/callback", m.Timeout(h.OIDCCallback, timeout))
+	r.POST("/api/token/refresh", m.Timeout(h.Refresh, timeout))
+	r.POST("/api/logout", m.Timeout(m.Authenticate(h.Logout), timeout))
+	// Opening the emailed link only shows a page, the token is used when it is posted back
+	r.GET("/api/verify-email", m.Timeout(h.ShowVerifyEmail, timeout))
+	r.POST("/api/verify-email", m.Timeout(h.VerifyEmail, timeout))
+	r.POST("/api/verify-email/resend", m.Timeout(m.Authenticate(h.ResendVerification), timeout))
+	r.POST("/api/password-reset", m.Timeout(h.RequestPasswordReset, timeout))
+	r.POST("/api/password-reset/confirm", m.Timeout(h.ResetPassword, timeout))
+	r.POST("/api/mfa/totp", m.Timeout(m.Authenticate(m.Authorize(h.BeginTOTPEnrollment, models.RoleRecruiter, models.RoleAdmin)), timeout))
+	r.POST("/api/mfa/totp/confirm", m.Timeout(m.Authenticate(m.Authorize(h.ConfirmTOTPEnrollment, models.RoleRecruiter, models.RoleAdmin)), timeout))
+	r.POST("/api/mfa/totp/disable", m.Timeout(m.Authenticate(m.Authorize(h.DisableTOTP, models.RoleRecruiter, models.RoleAdmin)), timeout))
+	r.POST("/api/companies", m.Timeout(m.Authenticate(m.Authorize(h.AddCompanies, models.RoleRecruiter, models.RoleAdmin)), timeout))
+	r.GET("/api/view", m.Timeout(m.Authenticate(h.ViewCompanies), timeout))
+	r.GET("/api/companies/:companyID", m.Timeout(m.Authenticate(h.ViewCompaniesById), timeout))
+	r.PATCH("/api/companies/:companyID", m.Timeout(m.Authenticate(m.Authorize(h.UpdateCompany, models.RoleRecruiter, models.RoleAdmin)), timeout))
+	r.DELETE("/api/companies/:companyID", m.Timeout(m.Authenticate(m.Authorize(h.DeleteCompany, models.RoleRecruiter, models.RoleAdmin)), timeout))
+	r.POST("/api/companies/:companyID/restore", m.Timeout(m.Authenticate(m.Authorize(h.RestoreCompany, models.RoleAdmin)), timeout))
+	r.POST("/api/companies/:companyID/api-keys", m.Timeout(m.Authenticate(m.Authorize(h.CreateAPIKey, models.RoleRecruiter, models.RoleAdmin)), timeout))
+	r.GET("/api/companies/:companyID/api-keys", m.Timeout(m.Authenticate(m.Authorize(h.ListAPIKeys, models.RoleRecruiter, models.RoleAdmin)), timeout))
+	r.DELETE("/api/companies/:companyID/api-keys/:keyID", m.Timeout(m.Authenticate(m.Authorize(h.RevokeAPIKey, models.RoleRecruiter, models.RoleAdmin)), timeout))
+	// The routes below that list scopes can also be called with an API key granted one of them
+	r.POST("/companies/:companyID/jobs", m.Timeout(m.Authenticate(m.Authorize(h.CreateJob, models.RoleRecruiter, models.RoleAdmin), models.ScopeJobsWrite), timeout))
+	r.GET("api/companies/:companyID/list-jobs", m.Timeout(m.Authenticate(h.ListJobs, models.ScopeJobsRead), search))
+	r.GET("api/jobs", m.Timeout(m.Authenticate(h.AllJobs, models.ScopeJobsRead), search))
+	r.GET("/api/search", m.Timeout(m.Authenticate(h.Search, models.ScopeJobsRead), search))
+	r.GET("/api/jobs/:jobID", m.Timeout(m.Authenticate(h.JobsByID, models.ScopeJobsRead), timeout))
+	r.POST("/api/jobs/:jobID", m.Timeout(m.Authenticate(m.Authorize(h.Apply, models.RoleCandidate)), timeout))
+	r.PATCH("/api/jobs/:jobID", m.Timeout(m.Authenticate(m.Authorize(h.UpdateJob, models.RoleRecruiter, models.RoleAdmin), models.ScopeJobsWrite), timeout))
+	r.DELETE("/api/jobs/:jobID", m.Timeout(m.Authenticate(m.Authorize(h.DeleteJob, models.RoleRecruiter, models.RoleAdmin), models.ScopeJobsWrite), timeout))
+	r.POST("/api/jobs/:jobID/restore", m.Timeout(m.Authenticate(m.Authorize(h.RestoreJob, models.RoleAdmin)), timeout))
+	r.POST("/api/jobs/:jobID/publish", m.Timeout(m.Authenticate(m.Authorize(h.PublishJob, models.RoleRecruiter, models.RoleAdmin), models.ScopeJobsWrite), timeout))
+	r.POST("/api/jobs/:jobID/close", m.Timeout(m.Authenticate(m.Authorize(h.CloseJob, models.RoleRecruiter, models.RoleAdmin), models.ScopeJobsWrite), timeout))
+	r.GET("/api/jobs/:jobID/applications", m.Timeout(m.Authenticate(m.Authorize(h.JobApplications, models.RoleRecruiter, models.RoleAdmin), models.ScopeApplicationsRead), timeout))
+	r.GET("/api/applications", m.Timeout(m.Authenticate(m.Authorize(h.MyApplications, models.RoleCandidate)), timeout))
+	r.POST("/api/applications/:applicationID/transitions", m.Timeout(m.Authenticate(m.Authorize(h.TransitionApplication, models.RoleRecruiter, models.RoleAdmin), models.ScopeApplicationsWrite), timeout))
+	r.GET("/api/applications/:applicationID/timeline", m.Timeout(m.Authenticate(h.ApplicationTimeline, models.ScopeApplicationsRead), timeout))
 
 	return r
 }
@@ -69,3 +135,9 @@ func check(c *gin.Context) {
 	}
 
 }
+
+// recovered answers a request whose handler panicked. The panic itself is logged by gin.
+func recovered(c *gin.Context, err any) {
+	traceId, _ := c.Request.Context().Value(middlewares.TraceIdKey).(string)
+	problem.Abort(c, http.StatusInternalServerError, traceId, "")
+}
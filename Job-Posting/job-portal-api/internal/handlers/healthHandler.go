@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Healthz is the liveness probe. It answers as long as the process can serve requests at all and doesn't look at
+// any dependency, so that a database outage doesn't get the API restarted.
+func (h *handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz is the readiness probe. It answers 503 Service Unavailable while a dependency check fails or once the
+// server has started shutting down, and lists the outcome of every check.
+func (h *handler) Readyz(c *gin.Context) {
+	report, ready := h.hc.Ready(c.Request.Context())
+	if !ready {
+		log.Warn().Str("Status", report.Status).Interface("Checks", report.Checks).Msg("not ready")
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"job-portal-api/internal/health"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := handler{}
+	router.GET("/healthz", h.Healthz)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Equal(t, `{"status":"ok"}`, resp.Body.String())
+}
+
+func TestReadyz(t *testing.T) {
+	tt := []struct {
+		name             string
+		dbErr            error
+		shutDown         bool
+		expectedStatus   int
+		expectedResponse string
+	}{
+		{
+			name:             "OK",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"status":"ready","checks":{"database":"ok","migrations":"ok"}}`,
+		},
+		{
+			name:             "Fail_Database",
+			dbErr:            errors.New("connection refused"),
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedResponse: `{"status":"not ready","checks":{"database":"connection refused","migrations":"ok"}}`,
+		},
+		{
+			name:             "Fail_ShuttingDown",
+			shutDown:         true,
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedResponse: `{"status":"shutting down"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			hc := health.New(time.Second)
+			hc.Add("database", func(context.Context) error { return tc.dbErr })
+			hc.Add("migrations", func(context.Context) error { return nil })
+			if tc.shutDown {
+				hc.ShutDown()
+			}
+
+			router := gin.New()
+			h := handler{hc: hc}
+			router.GET("/readyz", h.Readyz)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			require.Equal(t, tc.expectedResponse, resp.Body.String())
+		})
+	}
+}
@@ -1,17 +1,20 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"job-portal-api/internal/auth"
+	"job-portal-api/internal/metrics"
 	middlewares "job-portal-api/internal/middleware"
 	"job-portal-api/internal/models"
+	"job-portal-api/internal/problem"
+	"job-portal-api/internal/validate"
 	"net/http"
 
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/rs/zerolog/log"
 )
 
@@ -20,46 +23,41 @@ func (h *handler) AddCompanies(c *gin.Context) {
 	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
 	if !ok {
 		log.Error().Msg("traceId missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": http.StatusText(http.StatusInternalServerError)})
+		problem.Abort(c, http.StatusInternalServerError, "", "")
 		return
 	}
 
-	claims, ok := ctx.Value(auth.Key).(jwt.RegisteredClaims)
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
 	if !ok {
 		log.Error().Str("Trace Id", traceId).Msg("login first")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
 		return
 	}
 
 	var newCom models.NewComapanies
 	err := json.NewDecoder(c.Request.Body).Decode(&newCom)
 	if err != nil {
-		log.Error().Str("Trace Id", traceId).Msg("login first")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+		log.Error().Err(err).Str("Trace Id", traceId).Msg("failed to parse request body")
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid request body")
 		return
 	}
-	validate := validator.New()
 	err = validate.Struct(newCom)
-
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceId).Send()
-		c.AbortWithStatusJSON(http.StatusBadRequest,
-			gin.H{"msg": "please provide all deatails"})
+		abortWithError(c, traceId, err)
 		return
 	}
 	uid, err := strconv.ParseUint(claims.Subject, 10, 64)
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceId)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": http.StatusText(http.
-			StatusInternalServerError)})
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusInternalServerError, traceId, "")
 		return
 	}
 	comp, err := h.s.CreatCompanies(ctx, newCom, uint(uid))
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceId)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": "Company creation failed"})
+		abortWithError(c, traceId, err)
 		return
 	}
+	metrics.CompanyCreated()
 
 	c.JSON(http.StatusOK, comp)
 
@@ -70,21 +68,19 @@ func (h *handler) ViewCompanies(c *gin.Context) {
 	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
 	if !ok {
 		log.Error().Msg("traceId missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": http.StatusText(http.StatusInternalServerError)})
+		problem.Abort(c, http.StatusInternalServerError, "", "")
 		return
 	}
 
-	claims, ok := ctx.Value(auth.Key).(jwt.RegisteredClaims)
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
 	if !ok {
 		log.Error().Str("Trace Id", traceId).Msg("login first")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
 		return
 	}
 	companyList, err := h.s.ViewCompanies(ctx, claims.Subject)
-
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceId)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "problem in viewing company"})
+		abortWithError(c, traceId, err)
 		return
 	}
 	m := gin.H{"companies list": companyList}
@@ -95,14 +91,14 @@ func (h *handler) ViewCompaniesById(c *gin.Context) {
 	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
 	if !ok {
 		log.Error().Msg("traceId missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": http.StatusText(http.StatusInternalServerError)})
+		problem.Abort(c, http.StatusInternalServerError, "", "")
 		return
 	}
 
-	claims, ok := ctx.Value(auth.Key).(jwt.RegisteredClaims)
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
 	if !ok {
 		log.Error().Str("Trace Id", traceId).Msg("login first")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
 		return
 	}
 
@@ -110,15 +106,14 @@ func (h *handler) ViewCompaniesById(c *gin.Context) {
 	companyIDs := c.Param("companyID")
 	companyID, err := strconv.ParseUint(companyIDs, 10, 64)
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceId)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid company ID"})
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid company ID")
 		return
 	}
 
 	company, err := h.s.ViewCompaniesById(ctx, uint(companyID), claims.Subject)
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceId)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "problem in fetching company details"})
+		abortWithError(c, traceId, err)
 		return
 	}
 
@@ -129,43 +124,53 @@ func (h *handler) CreateJob(c *gin.Context) {
 	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
 	if !ok {
 		log.Error().Msg("traceId missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": http.StatusText(http.StatusInternalServerError)})
+		problem.Abort(c, http.StatusInternalServerError, "", "")
 		return
 	}
 
-	claims, ok := ctx.Value(auth.Key).(jwt.RegisteredClaims)
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
 	if !ok {
 		log.Error().Str("Trace Id", traceId).Msg("login first")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
 		return
 	}
 
 	// Parse the request body to get the job details
-	var newJob models.Job
-	err := c.BindJSON(&newJob)
+	var newJob models.NewJob
+	err := json.NewDecoder(c.Request.Body).Decode(&newJob)
 	if err != nil {
 		log.Error().Err(err).Str("Trace Id", traceId).Msg("failed to parse request body")
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid request body")
+		return
+	}
+
+	err = validate.Struct(newJob)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+	if newJob.ApplicationDeadline != nil && newJob.ApplicationDeadline.Before(time.Now()) {
+		log.Error().Str("Trace Id", traceId).Msg("application deadline in the past")
+		problem.Abort(c, http.StatusBadRequest, traceId, "application_deadline must be in the future")
 		return
 	}
 
-	// Set the CompanyID from the URL parameter
+	// Take the CompanyID from the URL parameter
 	companyIDStr := c.Param("companyID")
 	companyID, err := strconv.ParseUint(companyIDStr, 10, 64)
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceId)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid company ID"})
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid company ID")
 		return
 	}
-	newJob.CompanyID = uint(companyID)
 
 	// Create the job
-	createdJob, err := h.s.CreateJob(ctx, newJob, claims.Subject)
+	createdJob, err := h.s.CreateJob(ctx, newJob, uint(companyID), claims.Subject)
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceId)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to create job"})
+		abortWithError(c, traceId, err)
 		return
 	}
+	metrics.JobPosted()
 
 	c.JSON(http.StatusCreated, createdJob)
 }
@@ -174,28 +179,27 @@ func (h *handler) ListJobs(c *gin.Context) {
 	traceID, ok := ctx.Value(middlewares.TraceIdKey).(string)
 	if !ok {
 		log.Error().Msg("traceId missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": http.StatusText(http.StatusInternalServerError)})
+		problem.Abort(c, http.StatusInternalServerError, "", "")
 		return
 	}
-	claims, ok := ctx.Value(auth.Key).(jwt.RegisteredClaims)
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
 	if !ok {
 		log.Error().Str("Trace Id", traceID).Msg("login first")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+		problem.Abort(c, http.StatusUnauthorized, traceID, "")
 		return
 	}
 
 	companyIDStr := c.Param("companyID")
 	companyID, err := strconv.ParseUint(companyIDStr, 10, 64)
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceID)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid company ID"})
+		log.Error().Err(err).Str("Trace Id", traceID).Send()
+		problem.Abort(c, http.StatusBadRequest, traceID, "Invalid company ID")
 		return
 	}
 
 	jobs, err := h.s.ListJobs(ctx, uint(companyID), claims.Subject)
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceID)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch jobs"})
+		abortWithError(c, traceID, err)
 		return
 	}
 
@@ -206,24 +210,37 @@ func (h *handler) AllJobs(c *gin.Context) {
 	traceID, ok := ctx.Value(middlewares.TraceIdKey).(string)
 	if !ok {
 		log.Error().Msg("traceId missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": http.StatusText(http.StatusInternalServerError)})
+		problem.Abort(c, http.StatusInternalServerError, "", "")
 		return
 	}
-	claims, ok := ctx.Value(auth.Key).(jwt.RegisteredClaims)
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
 	if !ok {
 		log.Error().Str("Trace Id", traceID).Msg("login first")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+		problem.Abort(c, http.StatusUnauthorized, traceID, "")
 		return
 	}
 
-	jobs, err := h.s.AllJob(ctx, claims.Subject)
+	// Read the filters, sort order and page from the query string
+	var f models.JobFilter
+	err := c.ShouldBindQuery(&f)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceID).Send()
+		problem.Abort(c, http.StatusBadRequest, traceID, "Invalid query parameters")
+		return
+	}
+	err = validate.Struct(f)
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceID)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch jobs"})
+		abortWithError(c, traceID, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, jobs)
+	page, err := h.s.AllJob(ctx, f, claims.Subject)
+	if err != nil {
+		abortWithError(c, traceID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
 }
 
 func (h *handler) JobsByID(c *gin.Context) {
@@ -231,28 +248,274 @@ func (h *handler) JobsByID(c *gin.Context) {
 	traceID, ok := ctx.Value(middlewares.TraceIdKey).(string)
 	if !ok {
 		log.Error().Msg("traceId missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": http.StatusText(http.StatusInternalServerError)})
+		problem.Abort(c, http.StatusInternalServerError, "", "")
 		return
 	}
-	claims, ok := ctx.Value(auth.Key).(jwt.RegisteredClaims)
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
 	if !ok {
 		log.Error().Str("Trace Id", traceID).Msg("login first")
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+		problem.Abort(c, http.StatusUnauthorized, traceID, "")
 		return
 	}
 
 	jobIDStr := c.Param("jobID")
 	jobID, err := strconv.ParseUint(jobIDStr, 10, 64)
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceID)
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		log.Error().Err(err).Str("Trace Id", traceID).Send()
+		problem.Abort(c, http.StatusBadRequest, traceID, "Invalid job ID")
+		return
+	}
+
+	job, err := h.s.JobsByID(ctx, jobID, claims.Subject)
+	if err != nil {
+		abortWithError(c, traceID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// PublishJob makes a draft job visible to candidates
+func (h *handler) PublishJob(c *gin.Context) {
+	h.transitionJob(c, h.s.PublishJob)
+}
+
+// CloseJob stops a published job from accepting applications
+func (h *handler) CloseJob(c *gin.Context) {
+	h.transitionJob(c, h.s.CloseJob)
+}
+
+func (h *handler) transitionJob(c *gin.Context, transition func(ctx context.Context, jobID uint64, userId string) (models.Job, error)) {
+	ctx := c.Request.Context()
+	traceID, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceID).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceID, "")
+		return
+	}
+
+	jobID, err := strconv.ParseUint(c.Param("jobID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceID).Send()
+		problem.Abort(c, http.StatusBadRequest, traceID, "Invalid job ID")
+		return
+	}
+
+	job, err := transition(ctx, jobID, claims.Subject)
+	if err != nil {
+		abortWithError(c, traceID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// UpdateCompany changes the fields of a company present in the request body
+func (h *handler) UpdateCompany(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceId).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
+		return
+	}
+
+	companyID, err := strconv.ParseUint(c.Param("companyID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid company ID")
+		return
+	}
+
+	var uc models.UpdateCompany
+	err = json.NewDecoder(c.Request.Body).Decode(&uc)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Msg("failed to parse request body")
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid request body")
+		return
+	}
+	err = validate.Struct(uc)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	company, err := h.s.UpdateCompany(ctx, uint(companyID), uc, claims.Subject)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, company)
+}
+
+// DeleteCompany soft deletes a company and its jobs
+func (h *handler) DeleteCompany(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceId).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
+		return
+	}
+
+	companyID, err := strconv.ParseUint(c.Param("companyID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid company ID")
+		return
+	}
+
+	err = h.s.DeleteCompany(ctx, uint(companyID), claims.Subject)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreCompany brings back a deleted company and the jobs deleted with it
+func (h *handler) RestoreCompany(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	companyID, err := strconv.ParseUint(c.Param("companyID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid company ID")
+		return
+	}
+
+	company, err := h.s.RestoreCompany(ctx, uint(companyID))
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, company)
+}
+
+// UpdateJob changes the fields of a job present in the request body
+func (h *handler) UpdateJob(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceID, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceID).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceID, "")
+		return
+	}
+
+	jobID, err := strconv.ParseUint(c.Param("jobID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceID).Send()
+		problem.Abort(c, http.StatusBadRequest, traceID, "Invalid job ID")
+		return
+	}
+
+	var uj models.UpdateJob
+	err = json.NewDecoder(c.Request.Body).Decode(&uj)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceID).Msg("failed to parse request body")
+		problem.Abort(c, http.StatusBadRequest, traceID, "Invalid request body")
+		return
+	}
+	err = validate.Struct(uj)
+	if err != nil {
+		abortWithError(c, traceID, err)
+		return
+	}
+
+	job, err := h.s.UpdateJob(ctx, jobID, uj, claims.Subject)
+	if err != nil {
+		abortWithError(c, traceID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// DeleteJob soft deletes a job
+func (h *handler) DeleteJob(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceID, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceID).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceID, "")
+		return
+	}
+
+	jobID, err := strconv.ParseUint(c.Param("jobID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceID).Send()
+		problem.Abort(c, http.StatusBadRequest, traceID, "Invalid job ID")
+		return
+	}
+
+	err = h.s.DeleteJob(ctx, jobID, claims.Subject)
+	if err != nil {
+		abortWithError(c, traceID, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreJob brings back a deleted job
+func (h *handler) RestoreJob(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceID, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	jobID, err := strconv.ParseUint(c.Param("jobID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceID).Send()
+		problem.Abort(c, http.StatusBadRequest, traceID, "Invalid job ID")
 		return
 	}
 
-	job, err := h.s.JobsById(ctx, uint(jobID), claims.Subject)
+	job, err := h.s.RestoreJob(ctx, jobID)
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceID)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job"})
+		abortWithError(c, traceID, err)
 		return
 	}
 
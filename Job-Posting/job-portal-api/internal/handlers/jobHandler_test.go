@@ -16,6 +16,7 @@ import (
 	"job-portal-api/internal/services"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -23,8 +24,11 @@ import (
 func TestViewCompanies(t *testing.T) {
 	// Sets the Gin router mode to test.
 	gin.SetMode(gin.TestMode)
-	fakeClaims := jwt.RegisteredClaims{
-		Subject: "1",
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleRecruiter},
 	}
 	// MockUser struct initialization
 	mockCompanies := []models.Companies{
@@ -62,8 +66,8 @@ func TestViewCompanies(t *testing.T) {
 		},
 		{
 			name:              "Error",
-			expectedStatus:    400,
-			expectedResponse:  `{"msg":"problem in viewing company"}`,
+			expectedStatus:    500,
+			expectedResponse:  `{"type":"about:blank","title":"Internal Server Error","status":500,"instance":"/api/companies","trace_id":"fake-trace-id"}`,
 			expectedCompanies: nil,
 			mockService: func(m *services.MockService) {
 				m.EXPECT().ViewCompanies(gomock.Any(), gomock.Any()).Times(1).
@@ -85,9 +89,6 @@ func TestViewCompanies(t *testing.T) {
 			// Apply the mock to the user service.
 			tc.mockService(mockS)
 
-			// Create a new instance of `models.Service` with the mock service.
-			ms := services.NewStore(mockS)
-
 			// Create a new context. This is typically passed between functions
 			// carrying deadline, cancellation signals, and other request-scoped values.
 			ctx := context.Background()
@@ -102,7 +103,7 @@ func TestViewCompanies(t *testing.T) {
 			router := gin.New()
 
 			// Create a new handler which uses the service model.
-			h := handler{s: ms}
+			h := handler{s: mockS}
 
 			// Register an endpoint and its handler with the router.
 			router.GET("/api/companies", h.ViewCompanies)
@@ -130,8 +131,11 @@ func TestViewCompanies(t *testing.T) {
 func TestViewCompaniesById(t *testing.T) {
 	// Sets the Gin router mode to test.
 	gin.SetMode(gin.TestMode)
-	fakeClaims := jwt.RegisteredClaims{
-		Subject: "1",
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleRecruiter},
 	}
 
 	// MockUser struct initialization
@@ -191,11 +195,11 @@ func TestViewCompaniesById(t *testing.T) {
 				},
 				// Add more sample companies if needed.
 			},
-			expectedStatus:   400,
-			expectedResponse: `{"msg":"problem in fetching company details"}`,
+			expectedStatus:   404,
+			expectedResponse: `{"type":"about:blank","title":"Not Found","status":404,"detail":"company not found","instance":"/api/companies/1","trace_id":"fake-trace-id"}`,
 			mockService: func(m *services.MockService) {
 				m.EXPECT().ViewCompaniesById(gomock.Any(), gomock.Any(), gomock.Any()).
-					Times(1).Return(nil, errors.New(""))
+					Times(1).Return(nil, &services.Error{Kind: services.ErrNotFound, Detail: "company not found"})
 			},
 		},
 	}
@@ -213,9 +217,6 @@ func TestViewCompaniesById(t *testing.T) {
 			// Apply the mock to the user service.
 			tc.mockService(mockS)
 
-			// Create a new instance of `models.Service` with the mock service.
-			ms := services.NewStore(mockS)
-
 			// Create a new context. This is typically passed between functions
 			// carrying deadline, cancellation signals, and other request-scoped values.
 			ctx := context.Background()
@@ -230,7 +231,7 @@ func TestViewCompaniesById(t *testing.T) {
 			router := gin.New()
 
 			// Create a new handler which uses the service model.
-			h := handler{s: ms}
+			h := handler{s: mockS}
 
 			// Register an endpoint and its handler with the router.
 			router.GET("/api/companies/:companyID", h.ViewCompaniesById)
@@ -258,36 +259,94 @@ func TestViewCompaniesById(t *testing.T) {
 func TestHandler_CreateJob(t *testing.T) {
 	// Sets the Gin router mode to test.
 	gin.SetMode(gin.TestMode)
-	fakeClaims := jwt.RegisteredClaims{
-		Subject: "1",
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleRecruiter},
 	}
 
 	// Define the input data for creating a job
+	newJob := models.NewJob{
+		Title:          "Software Engineer",
+		Description:    "Senior",
+		Location:       "banglore",
+		WorkMode:       models.WorkModeHybrid,
+		EmploymentType: models.EmploymentFullTime,
+		Seniority:      models.SenioritySenior,
+		SalaryMin:      1000000,
+		SalaryMax:      1500000,
+		SalaryCurrency: "INR",
+		Skills:         []string{"go", "postgres"},
+	}
 	jobData := models.Job{
-		Title:       "Software Engineer",
-		Description: "Senior",
-		CompanyID:   1,
+		Title:          newJob.Title,
+		Description:    newJob.Description,
+		CompanyID:      1,
+		Location:       newJob.Location,
+		WorkMode:       newJob.WorkMode,
+		EmploymentType: newJob.EmploymentType,
+		Seniority:      newJob.Seniority,
+		SalaryMin:      newJob.SalaryMin,
+		SalaryMax:      newJob.SalaryMax,
+		SalaryCurrency: newJob.SalaryCurrency,
+		Skills:         newJob.Skills,
+		Status:         models.JobStatusDraft,
 	}
+	invalidSalary := newJob
+	invalidSalary.SalaryMax = 10
+	invalidWorkMode := newJob
+	invalidWorkMode.WorkMode = "moon"
 
 	// Define the list of test cases
 	testCases := []struct {
 		name             string                        // Name of the test case
+		body             any                           // Body to send to request
 		expectedStatus   int                           // Expected status of the response
 		expectedResponse string                        // Expected response body
 		mockService      func(m *services.MockService) // Mock service function
 	}{
 		{
 			name:           "OK",
+			body:           newJob,
 			expectedStatus: 201,
 			// You can adjust the expected response based on your application's actual response format.
-			expectedResponse: `{"ID":0,"CreatedAt":"0001-01-01T00:00:00Z","UpdatedAt":"0001-01-01T00:00:00Z","DeletedAt":null,"title":"Software Engineer","description":"Senior","company_id":1}`,
+			expectedResponse: `{"ID":0,"CreatedAt":"0001-01-01T00:00:00Z","UpdatedAt":"0001-01-01T00:00:00Z","DeletedAt":null,"title":"Software Engineer","description":"Senior","company_id":1,"location":"banglore","work_mode":"hybrid","employment_type":"full_time","seniority":"senior","salary_min":1000000,"salary_max":1500000,"salary_currency":"INR","skills":["go","postgres"],"status":"draft"}`,
 			// Function for mocking service.
 			// This simulates CreateJob service and its return value.
 			mockService: func(m *services.MockService) {
-				m.EXPECT().CreateJob(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+				m.EXPECT().CreateJob(gomock.Any(), gomock.Eq(newJob), gomock.Eq(uint(1)), gomock.Any()).Times(1).
 					Return(jobData, nil)
 			},
 		},
+		{
+			name:             "Forbidden - Not Company Owner",
+			body:             newJob,
+			expectedStatus:   403,
+			expectedResponse: `{"type":"about:blank","title":"Forbidden","status":403,"detail":"you do not own this company","instance":"/companies/1/jobs","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CreateJob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(models.Job{}, &services.Error{Kind: services.ErrForbidden, Detail: "you do not own this company"})
+			},
+		},
+		{
+			name:             "Fail_SalaryMaxBelowMin",
+			body:             invalidSalary,
+			expectedStatus:   400,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/companies/1/jobs","trace_id":"fake-trace-id","errors":[{"field":"salary_max","rule":"gtefield","message":"must be greater than or equal to salary_min"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CreateJob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_InvalidWorkMode",
+			body:             invalidWorkMode,
+			expectedStatus:   400,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/companies/1/jobs","trace_id":"fake-trace-id","errors":[{"field":"work_mode","rule":"oneof","message":"must be one of onsite, remote, hybrid"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CreateJob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
 	}
 
 	// Start a loop over `testCases` array where each element is represented by `tc`.
@@ -303,10 +362,6 @@ func TestHandler_CreateJob(t *testing.T) {
 			// Apply the mock to the user service.
 			tc.mockService(mockS)
 
-			// Create a new instance of `models.Service` with the mock service.
-
-			ms := services.NewStore(mockS)
-
 			// Create a new context. This is typically passed between functions
 			// carrying deadline, cancellation signals, and other request-scoped values.
 			ctx := context.Background()
@@ -321,13 +376,13 @@ func TestHandler_CreateJob(t *testing.T) {
 			router := gin.New()
 
 			// Create a new handler which uses the service model.
-			h := handler{s: ms}
+			h := handler{s: mockS}
 
 			// Register an endpoint and its handler with the router.
 			router.POST("/companies/:companyID/jobs", h.CreateJob)
 
-			// Serialize jobData to JSON and create a request body
-			reqBody, _ := json.Marshal(jobData)
+			// Serialize the body to JSON and create a request body
+			reqBody, _ := json.Marshal(tc.body)
 
 			// Create a new HTTP POST request to "/createjob".
 			req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/companies/1/jobs", bytes.NewReader(reqBody))
@@ -351,8 +406,11 @@ func TestHandler_CreateJob(t *testing.T) {
 func TestAllJobs(t *testing.T) {
 	// Sets the Gin router mode to test.
 	gin.SetMode(gin.TestMode)
-	fakeClaims := jwt.RegisteredClaims{
-		Subject: "1",
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleRecruiter},
 	}
 	// MockUser struct initialization
 	mockJob := []models.Job{
@@ -371,6 +429,7 @@ func TestAllJobs(t *testing.T) {
 	// Define the list of test cases
 	testCases := []struct {
 		name              string                        // Name of the test case
+		query             string                        // Query string of the request
 		expectedStatus    int                           // Expected status of the response
 		expectedResponse  string                        // Expected response body
 		expectedCompanies []models.Job                  // Expected user after signup
@@ -379,12 +438,60 @@ func TestAllJobs(t *testing.T) {
 		{
 			name:              "OK",
 			expectedStatus:    200,
-			expectedResponse:  `[{"ID":1,"CreatedAt":"2006-01-01T01:01:01.000000001Z","UpdatedAt":"2006-01-01T01:01:01.000000001Z","DeletedAt":null,"title":"Software Engineer","description":"Senior","company_id":1}]`,
+			expectedResponse:  `{"jobs":[{"ID":1,"CreatedAt":"2006-01-01T01:01:01.000000001Z","UpdatedAt":"2006-01-01T01:01:01.000000001Z","DeletedAt":null,"title":"Software Engineer","description":"Senior","company_id":1,"location":"","work_mode":"","employment_type":"","status":""}],"total":1}`,
 			expectedCompanies: mockJob,
 			mockService: func(m *services.MockService) {
 
-				m.EXPECT().AllJob(gomock.Any(), gomock.Any()).Times(1).
-					Return(mockJob, nil)
+				m.EXPECT().AllJob(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(models.JobPage{Jobs: mockJob, Total: 1}, nil)
+			},
+		},
+		{
+			name:             "OK_WithFilters",
+			query:            "?q=engineer&company_id=1&employment_type=full_time&salary_min=100&salary_currency=INR&posted_since=2006-01-01&sort=salary_desc&limit=1",
+			expectedStatus:   200,
+			expectedResponse: `{"jobs":[{"ID":1,"CreatedAt":"2006-01-01T01:01:01.000000001Z","UpdatedAt":"2006-01-01T01:01:01.000000001Z","DeletedAt":null,"title":"Software Engineer","description":"Senior","company_id":1,"location":"","work_mode":"","employment_type":"","status":""}],"total":3,"next_cursor":"abc"}`,
+			mockService: func(m *services.MockService) {
+				f := models.JobFilter{
+					Keyword:        "engineer",
+					CompanyID:      1,
+					EmploymentType: models.EmploymentFullTime,
+					SalaryMin:      100,
+					SalaryCurrency: "INR",
+					PostedSince:    time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC),
+					Sort:           models.JobSortSalaryDesc,
+					Limit:          1,
+				}
+				m.EXPECT().AllJob(gomock.Any(), gomock.Eq(f), gomock.Any()).Times(1).
+					Return(models.JobPage{Jobs: mockJob, Total: 3, NextCursor: "abc"}, nil)
+			},
+		},
+		{
+			name:             "Fail_InvalidSort",
+			query:            "?sort=random",
+			expectedStatus:   400,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/jobs","trace_id":"fake-trace-id","errors":[{"field":"sort","rule":"oneof","message":"must be one of newest, oldest, salary_desc, salary_asc"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().AllJob(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_SalaryWithoutCurrency",
+			query:            "?salary_min=50000",
+			expectedStatus:   400,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/jobs","trace_id":"fake-trace-id","errors":[{"field":"salary_currency","rule":"required_with","message":"is required when salary_min or salary_max is set"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().AllJob(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_InvalidCursor",
+			query:            "?cursor=garbage",
+			expectedStatus:   400,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid cursor","instance":"/jobs","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().AllJob(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(models.JobPage{}, services.ErrInvalidCursor)
 			},
 		},
 	}
@@ -402,9 +509,6 @@ func TestAllJobs(t *testing.T) {
 			// Apply the mock to the user service.
 			tc.mockService(mockS)
 
-			// Create a new instance of `models.Service` with the mock service.
-			ms := services.NewStore(mockS)
-
 			// Create a new context. This is typically passed between functions
 			// carrying deadline, cancellation signals, and other request-scoped values.
 			ctx := context.Background()
@@ -419,13 +523,13 @@ func TestAllJobs(t *testing.T) {
 			router := gin.New()
 
 			// Create a new handler which uses the service model.
-			h := handler{s: ms}
+			h := handler{s: mockS}
 
 			// Register an endpoint and its handler with the router.
 			router.GET("/jobs", h.AllJobs)
 
 			// Create a new HTTP POST request to "/signup".
-			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/jobs", nil)
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/jobs"+tc.query, nil)
 			// If the request creation fails, raise an error and stop the test.
 			require.NoError(t, err)
 
@@ -446,22 +550,22 @@ func TestAllJobs(t *testing.T) {
 func TestJobsById(t *testing.T) {
 	// Sets the Gin router mode to test.
 	gin.SetMode(gin.TestMode)
-	fakeClaims := jwt.RegisteredClaims{
-		Subject: "1",
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleRecruiter},
 	}
 	// MockUser struct initialization
-	mockJobs := []models.Job{
-		{
-			Model: gorm.Model{
-				ID:        1,
-				CreatedAt: time.Date(2006, 1, 1, 1, 1, 1, 1, time.UTC),
-				UpdatedAt: time.Date(2006, 1, 1, 1, 1, 1, 1, time.UTC),
-			},
-			Title:       "Software Engineer",
-			Description: "Senior",
-			CompanyID:   1,
+	mockJob := models.Job{
+		Model: gorm.Model{
+			ID:        1,
+			CreatedAt: time.Date(2006, 1, 1, 1, 1, 1, 1, time.UTC),
+			UpdatedAt: time.Date(2006, 1, 1, 1, 1, 1, 1, time.UTC),
 		},
-		// Add more sample companies if needed.
+		Title:       "Software Engineer",
+		Description: "Senior",
+		CompanyID:   1,
 	}
 	// Define the list of test cases
 	testCases := []struct {
@@ -473,15 +577,25 @@ func TestJobsById(t *testing.T) {
 		{
 			name:             "OK",
 			expectedStatus:   200,
-			expectedResponse: `[{"ID":1,"CreatedAt":"2006-01-01T01:01:01.000000001Z","UpdatedAt":"2006-01-01T01:01:01.000000001Z","DeletedAt":null,"title":"Software Engineer","description":"Senior","company_id":1}]`,
+			expectedResponse: `{"ID":1,"CreatedAt":"2006-01-01T01:01:01.000000001Z","UpdatedAt":"2006-01-01T01:01:01.000000001Z","DeletedAt":null,"title":"Software Engineer","description":"Senior","company_id":1,"location":"","work_mode":"","employment_type":"","status":""}`,
 			mockService: func(m *services.MockService) {
 
-				m.EXPECT().JobsById(gomock.Any(), gomock.Any(), gomock.Any()).
+				m.EXPECT().JobsByID(gomock.Any(), gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(mockJobs, nil)
+					Return(mockJob, nil)
 
 			},
 		},
+		{
+			name:             "Fail_NotVisible",
+			expectedStatus:   404,
+			expectedResponse: `{"type":"about:blank","title":"Not Found","status":404,"detail":"job not found","instance":"/api/jobs/1","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().JobsByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(models.Job{}, &services.Error{Kind: services.ErrNotFound, Detail: "job not found"})
+			},
+		},
 	}
 
 	// Start a loop over `testCases` array where each element is represented by `tc`.
@@ -497,9 +611,6 @@ func TestJobsById(t *testing.T) {
 			// Apply the mock to the user service.
 			tc.mockService(mockS)
 
-			// Create a new instance of `models.Service` with the mock service.
-			ms := services.NewStore(mockS)
-
 			// Create a new context. This is typically passed between functions
 			// carrying deadline, cancellation signals, and other request-scoped values.
 			ctx := context.Background()
@@ -514,7 +625,7 @@ func TestJobsById(t *testing.T) {
 			router := gin.New()
 
 			// Create a new handler which uses the service model.
-			h := handler{s: ms}
+			h := handler{s: mockS}
 
 			// Register an endpoint and its handler with the router.
 			router.GET("/api/jobs/:jobID", h.JobsByID)
@@ -541,8 +652,11 @@ func TestJobsById(t *testing.T) {
 func TestHandler_CreateCompany(t *testing.T) {
 	// Sets the Gin router mode to test.
 	gin.SetMode(gin.TestMode)
-	fakeClaims := jwt.RegisteredClaims{
-		Subject: "1",
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleRecruiter},
 	}
 
 	// Define the input data for creating a job
@@ -588,10 +702,6 @@ func TestHandler_CreateCompany(t *testing.T) {
 			// Apply the mock to the user service.
 			tc.mockService(mockS)
 
-			// Create a new instance of `models.Service` with the mock service.
-
-			ms := services.NewStore(mockS)
-
 			// Create a new context. This is typically passed between functions
 			// carrying deadline, cancellation signals, and other request-scoped values.
 			ctx := context.Background()
@@ -606,7 +716,7 @@ func TestHandler_CreateCompany(t *testing.T) {
 			router := gin.New()
 
 			// Create a new handler which uses the service model.
-			h := handler{s: ms}
+			h := handler{s: mockS}
 
 			// Register an endpoint and its handler with the router.
 			router.POST("/api/companies", h.AddCompanies)
@@ -636,8 +746,11 @@ func TestHandler_CreateCompany(t *testing.T) {
 func TestViewJobById(t *testing.T) {
 	// Sets the Gin router mode to test.
 	gin.SetMode(gin.TestMode)
-	fakeClaims := jwt.RegisteredClaims{
-		Subject: "1",
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleRecruiter},
 	}
 	// MockUser struct initialization
 	mockCompanies := []models.Job{
@@ -663,7 +776,7 @@ func TestViewJobById(t *testing.T) {
 		{
 			name:             "OK",
 			expectedStatus:   200,
-			expectedResponse: `[{"ID":1,"CreatedAt":"2006-01-01T01:01:01.000000001Z","UpdatedAt":"2006-01-01T01:01:01.000000001Z","DeletedAt":null,"title":"Software Engineer","description":"Senior","company_id":1}]`,
+			expectedResponse: `[{"ID":1,"CreatedAt":"2006-01-01T01:01:01.000000001Z","UpdatedAt":"2006-01-01T01:01:01.000000001Z","DeletedAt":null,"title":"Software Engineer","description":"Senior","company_id":1,"location":"","work_mode":"","employment_type":"","status":""}]`,
 			mockService: func(m *services.MockService) {
 
 				m.EXPECT().ListJobs(gomock.Any(), gomock.Any(), gomock.Any()).
@@ -687,9 +800,6 @@ func TestViewJobById(t *testing.T) {
 			// Apply the mock to the user service.
 			tc.mockService(mockS)
 
-			// Create a new instance of `models.Service` with the mock service.
-			ms := services.NewStore(mockS)
-
 			// Create a new context. This is typically passed between functions
 			// carrying deadline, cancellation signals, and other request-scoped values.
 			ctx := context.Background()
@@ -704,7 +814,7 @@ func TestViewJobById(t *testing.T) {
 			router := gin.New()
 
 			// Create a new handler which uses the service model.
-			h := handler{s: ms}
+			h := handler{s: mockS}
 
 			// Register an endpoint and its handler with the router.
 			router.GET("/api/companies/:companyID/list-jobs", h.ListJobs)
@@ -728,3 +838,420 @@ func TestViewJobById(t *testing.T) {
 		})
 	}
 }
+
+func TestPublishJob(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleRecruiter},
+	}
+	publishedAt := time.Date(2006, 1, 1, 1, 1, 1, 0, time.UTC)
+	expiresAt := publishedAt.Add(services.DefaultJobTTL)
+	mockJob := models.Job{
+		Model:       gorm.Model{ID: 1},
+		Title:       "Software Engineer",
+		CompanyID:   1,
+		Status:      models.JobStatusPublished,
+		PublishedAt: &publishedAt,
+		ExpiresAt:   &expiresAt,
+	}
+
+	testCases := []struct {
+		name             string
+		path             string
+		expectedStatus   int
+		expectedResponse string
+		mockService      func(m *services.MockService)
+	}{
+		{
+			name:             "OK",
+			path:             "/api/jobs/1/publish",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"ID":1,"CreatedAt":"0001-01-01T00:00:00Z","UpdatedAt":"0001-01-01T00:00:00Z","DeletedAt":null,"title":"Software Engineer","description":"","company_id":1,"location":"","work_mode":"","employment_type":"","status":"published","published_at":"2006-01-01T01:01:01Z","expires_at":"2006-01-31T01:01:01Z"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().PublishJob(gomock.Any(), uint64(1), "1").Times(1).Return(mockJob, nil)
+			},
+		},
+		{
+			name:             "Fail_InvalidID",
+			path:             "/api/jobs/abc/publish",
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"Invalid job ID","instance":"/api/jobs/abc/publish","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().PublishJob(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_NotOwner",
+			path:             "/api/jobs/1/publish",
+			expectedStatus:   http.StatusForbidden,
+			expectedResponse: `{"type":"about:blank","title":"Forbidden","status":403,"detail":"you do not own this company","instance":"/api/jobs/1/publish","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().PublishJob(gomock.Any(), uint64(1), "1").Times(1).Return(models.Job{}, &services.Error{Kind: services.ErrForbidden, Detail: "you do not own this company"})
+			},
+		},
+		{
+			name:             "Fail_AlreadyPublished",
+			path:             "/api/jobs/1/publish",
+			expectedStatus:   http.StatusUnprocessableEntity,
+			expectedResponse: `{"type":"about:blank","title":"Unprocessable Entity","status":422,"detail":"a published job can't be moved to published","instance":"/api/jobs/1/publish","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().PublishJob(gomock.Any(), uint64(1), "1").Times(1).Return(models.Job{}, &services.Error{Kind: services.ErrInvalidTransition, Detail: "a published job can't be moved to published"})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+			tc.mockService(mockS)
+
+			ctx := context.WithValue(context.Background(), auth.Key, fakeClaims)
+			ctx = context.WithValue(ctx, middlewares.TraceIdKey, "fake-trace-id")
+
+			router := gin.New()
+			h := handler{s: mockS}
+			router.POST("/api/jobs/:jobID/publish", h.PublishJob)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, tc.path, nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			require.Equal(t, tc.expectedResponse, resp.Body.String())
+		})
+	}
+}
+
+func TestCloseJob(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleRecruiter},
+	}
+
+	testCases := []struct {
+		name             string
+		expectedStatus   int
+		expectedResponse string
+		mockService      func(m *services.MockService)
+	}{
+		{
+			name:             "OK",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"ID":1,"CreatedAt":"0001-01-01T00:00:00Z","UpdatedAt":"0001-01-01T00:00:00Z","DeletedAt":null,"title":"Software Engineer","description":"","company_id":1,"location":"","work_mode":"","employment_type":"","status":"closed"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CloseJob(gomock.Any(), uint64(1), "1").Times(1).
+					Return(models.Job{Model: gorm.Model{ID: 1}, Title: "Software Engineer", CompanyID: 1, Status: models.JobStatusClosed}, nil)
+			},
+		},
+		{
+			name:             "Fail_NotFound",
+			expectedStatus:   http.StatusNotFound,
+			expectedResponse: `{"type":"about:blank","title":"Not Found","status":404,"detail":"job not found","instance":"/api/jobs/1/close","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CloseJob(gomock.Any(), uint64(1), "1").Times(1).Return(models.Job{}, &services.Error{Kind: services.ErrNotFound, Detail: "job not found"})
+			},
+		},
+		{
+			name:             "Fail_Conflict",
+			expectedStatus:   http.StatusConflict,
+			expectedResponse: `{"type":"about:blank","title":"Conflict","status":409,"detail":"job was updated by someone else, please retry","instance":"/api/jobs/1/close","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CloseJob(gomock.Any(), uint64(1), "1").Times(1).Return(models.Job{}, &services.Error{Kind: services.ErrConflict, Detail: "job was updated by someone else, please retry"})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+			tc.mockService(mockS)
+
+			ctx := context.WithValue(context.Background(), auth.Key, fakeClaims)
+			ctx = context.WithValue(ctx, middlewares.TraceIdKey, "fake-trace-id")
+
+			router := gin.New()
+			h := handler{s: mockS}
+			router.POST("/api/jobs/:jobID/close", h.CloseJob)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/api/jobs/1/close", nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			require.Equal(t, tc.expectedResponse, resp.Body.String())
+		})
+	}
+}
+
+func TestUpdateCompany(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleRecruiter},
+	}
+	name := "Infosys"
+	testCases := []struct {
+		name             string
+		body             string
+		expectedStatus   int
+		expectedResponse string
+		mockService      func(m *services.MockService)
+	}{
+		{
+			name:             "OK",
+			body:             `{"company_name":"Infosys"}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"ID":1,"CreatedAt":"0001-01-01T00:00:00Z","UpdatedAt":"0001-01-01T00:00:00Z","DeletedAt":null,"company_name":"Infosys","founded_year":1981,"location":"banglore","user_id":1,"address":"electronic city"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().UpdateCompany(gomock.Any(), uint(1), models.UpdateCompany{CompanyName: &name}, "1").Times(1).
+					Return(models.Companies{Model: gorm.Model{ID: 1}, CompanyName: name, FoundedYear: 1981, Location: "banglore", UserId: 1, Address: "electronic city"}, nil)
+			},
+		},
+		{
+			name:             "Fail_EmptyName",
+			body:             `{"company_name":""}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/companies/1","trace_id":"fake-trace-id","errors":[{"field":"company_name","rule":"min","message":"must be at least 1 character long"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().UpdateCompany(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_FoundedYearTooEarly",
+			body:             `{"founded_year":1066}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/companies/1","trace_id":"fake-trace-id","errors":[{"field":"founded_year","rule":"founded_year","message":"must be a year between 1800 and ` + strconv.Itoa(time.Now().Year()) + `"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().UpdateCompany(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_NotOwner",
+			body:             `{"company_name":"Infosys"}`,
+			expectedStatus:   http.StatusForbidden,
+			expectedResponse: `{"type":"about:blank","title":"Forbidden","status":403,"detail":"you do not own this company","instance":"/api/companies/1","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().UpdateCompany(gomock.Any(), uint(1), gomock.Any(), "1").Times(1).Return(models.Companies{}, &services.Error{Kind: services.ErrForbidden, Detail: "you do not own this company"})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+			tc.mockService(mockS)
+
+			ctx := context.WithValue(context.Background(), auth.Key, fakeClaims)
+			ctx = context.WithValue(ctx, middlewares.TraceIdKey, "fake-trace-id")
+
+			router := gin.New()
+			h := handler{s: mockS}
+			router.PATCH("/api/companies/:companyID", h.UpdateCompany)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPatch, "/api/companies/1", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			require.Equal(t, tc.expectedResponse, resp.Body.String())
+		})
+	}
+}
+
+func TestDeleteCompany(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleRecruiter},
+	}
+	testCases := []struct {
+		name             string
+		expectedStatus   int
+		expectedResponse string
+		mockService      func(m *services.MockService)
+	}{
+		{
+			name:           "OK",
+			expectedStatus: http.StatusNoContent,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().DeleteCompany(gomock.Any(), uint(1), "1").Times(1).Return(nil)
+			},
+		},
+		{
+			name:             "Fail_NotFound",
+			expectedStatus:   http.StatusNotFound,
+			expectedResponse: `{"type":"about:blank","title":"Not Found","status":404,"detail":"company not found","instance":"/api/companies/1","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().DeleteCompany(gomock.Any(), uint(1), "1").Times(1).Return(&services.Error{Kind: services.ErrNotFound, Detail: "company not found"})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+			tc.mockService(mockS)
+
+			ctx := context.WithValue(context.Background(), auth.Key, fakeClaims)
+			ctx = context.WithValue(ctx, middlewares.TraceIdKey, "fake-trace-id")
+
+			router := gin.New()
+			h := handler{s: mockS}
+			router.DELETE("/api/companies/:companyID", h.DeleteCompany)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodDelete, "/api/companies/1", nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			require.Equal(t, tc.expectedResponse, resp.Body.String())
+		})
+	}
+}
+
+func TestUpdateJob(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleRecruiter},
+	}
+	salaryMax := 1500000
+	testCases := []struct {
+		name             string
+		body             string
+		expectedStatus   int
+		expectedResponse string
+		mockService      func(m *services.MockService)
+	}{
+		{
+			name:             "OK",
+			body:             `{"salary_max":1500000,"skills":[]}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"ID":1,"CreatedAt":"0001-01-01T00:00:00Z","UpdatedAt":"0001-01-01T00:00:00Z","DeletedAt":null,"title":"Software Engineer","description":"","company_id":1,"location":"","work_mode":"","employment_type":"","salary_min":1000000,"salary_max":1500000,"salary_currency":"INR","status":"draft"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().UpdateJob(gomock.Any(), uint64(1), models.UpdateJob{SalaryMax: &salaryMax, Skills: []string{}}, "1").Times(1).
+					Return(models.Job{Model: gorm.Model{ID: 1}, Title: "Software Engineer", CompanyID: 1, SalaryMin: 1000000, SalaryMax: salaryMax, SalaryCurrency: "INR", Status: models.JobStatusDraft}, nil)
+			},
+		},
+		{
+			name:             "Fail_InvalidWorkMode",
+			body:             `{"work_mode":"sometimes"}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/jobs/1","trace_id":"fake-trace-id","errors":[{"field":"work_mode","rule":"oneof","message":"must be one of onsite, remote, hybrid"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().UpdateJob(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_SalaryRange",
+			body:             `{"salary_max":10}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"salary_max must not be less than salary_min","instance":"/api/jobs/1","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().UpdateJob(gomock.Any(), uint64(1), gomock.Any(), "1").Times(1).
+					Return(models.Job{}, &services.Error{Kind: services.ErrInvalidInput, Detail: "salary_max must not be less than salary_min"})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+			tc.mockService(mockS)
+
+			ctx := context.WithValue(context.Background(), auth.Key, fakeClaims)
+			ctx = context.WithValue(ctx, middlewares.TraceIdKey, "fake-trace-id")
+
+			router := gin.New()
+			h := handler{s: mockS}
+			router.PATCH("/api/jobs/:jobID", h.UpdateJob)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPatch, "/api/jobs/1", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			require.Equal(t, tc.expectedResponse, resp.Body.String())
+		})
+	}
+}
+
+func TestRestoreJob(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	testCases := []struct {
+		name             string
+		expectedStatus   int
+		expectedResponse string
+		mockService      func(m *services.MockService)
+	}{
+		{
+			name:             "OK",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"ID":1,"CreatedAt":"0001-01-01T00:00:00Z","UpdatedAt":"0001-01-01T00:00:00Z","DeletedAt":null,"title":"Software Engineer","description":"","company_id":1,"location":"","work_mode":"","employment_type":"","status":"closed"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().RestoreJob(gomock.Any(), uint64(1)).Times(1).
+					Return(models.Job{Model: gorm.Model{ID: 1}, Title: "Software Engineer", CompanyID: 1, Status: models.JobStatusClosed}, nil)
+			},
+		},
+		{
+			name:             "Fail_CompanyDeleted",
+			expectedStatus:   http.StatusConflict,
+			expectedResponse: `{"type":"about:blank","title":"Conflict","status":409,"detail":"the company of the job is deleted, restore the company first","instance":"/api/jobs/1/restore","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().RestoreJob(gomock.Any(), uint64(1)).Times(1).Return(models.Job{}, &services.Error{Kind: services.ErrConflict, Detail: "the company of the job is deleted, restore the company first"})
+			},
+		},
+		{
+			name:             "Fail_NotDeleted",
+			expectedStatus:   http.StatusNotFound,
+			expectedResponse: `{"type":"about:blank","title":"Not Found","status":404,"detail":"deleted job not found","instance":"/api/jobs/1/restore","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().RestoreJob(gomock.Any(), uint64(1)).Times(1).Return(models.Job{}, &services.Error{Kind: services.ErrNotFound, Detail: "deleted job not found"})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+			tc.mockService(mockS)
+
+			ctx := context.WithValue(context.Background(), middlewares.TraceIdKey, "fake-trace-id")
+
+			router := gin.New()
+			h := handler{s: mockS}
+			router.POST("/api/jobs/:jobID/restore", h.RestoreJob)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/api/jobs/1/restore", nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			require.Equal(t, tc.expectedResponse, resp.Body.String())
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"job-portal-api/internal/auth"
+	"job-portal-api/internal/metrics"
+	middlewares "job-portal-api/internal/middleware"
+	"job-portal-api/internal/problem"
+	"job-portal-api/internal/services"
+	"job-portal-api/internal/validate"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// mfaCode is the body of the requests that take a code from the authenticator app or a recovery code
+type mfaCode struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// LoginMFA completes the login of a user with two-factor authentication, exchanging the mfa token returned by
+// Login and a code for an access and a refresh token
+func (h *handler) LoginMFA(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	var req struct {
+		MFAToken string `json:"mfa_token" validate:"required"`
+		Code     string `json:"code" validate:"required"`
+	}
+	err := json.NewDecoder(c.Request.Body).Decode(&req)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Msg("failed to parse request body")
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid request body")
+		return
+	}
+	err = validate.Struct(req)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	claims, err := h.s.VerifyMFA(ctx, req.MFAToken, req.Code)
+	if err != nil {
+		if errors.Is(err, services.ErrUnauthenticated) {
+			metrics.LoginFailed()
+		}
+		abortWithError(c, traceId, err)
+		return
+	}
+	metrics.LoginSucceeded()
+
+	h.respondWithTokens(c, traceId, claims)
+}
+
+// BeginTOTPEnrollment generates a secret for the authenticator app of the authenticated user. The app is set up by
+// scanning the provisioning URI as a QR code, or by typing the secret.
+func (h *handler) BeginTOTPEnrollment(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceId).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
+		return
+	}
+
+	enrollment, err := h.s.BeginTOTPEnrollment(ctx, claims.Subject)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmTOTPEnrollment enables two-factor authentication once the user sends a code from their app, and responds
+// with the recovery codes
+func (h *handler) ConfirmTOTPEnrollment(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceId).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
+		return
+	}
+
+	var req mfaCode
+	err := json.NewDecoder(c.Request.Body).Decode(&req)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Msg("failed to parse request body")
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid request body")
+		return
+	}
+	err = validate.Struct(req)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	codes, err := h.s.ConfirmTOTPEnrollment(ctx, claims.Subject, req.Code)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// DisableTOTP turns two-factor authentication off for the authenticated user, given a code from their app or a
+// recovery code
+func (h *handler) DisableTOTP(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceId).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
+		return
+	}
+
+	var req mfaCode
+	err := json.NewDecoder(c.Request.Body).Decode(&req)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Msg("failed to parse request body")
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid request body")
+		return
+	}
+	err = validate.Struct(req)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	err = h.s.DisableTOTP(ctx, claims.Subject, req.Code)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"job-portal-api/internal/auth"
+	middlewares "job-portal-api/internal/middleware"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMFAHandlers(t *testing.T) {
+	// Sets the Gin router mode to test.
+	gin.SetMode(gin.TestMode)
+
+	// Create an Auth with a throwaway key pair so tokens can be signed.
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	a, err := auth.NewAuth(privateKey, &privateKey.PublicKey)
+	require.NoError(t, err)
+
+	claims := auth.NewClaims("7", models.RoleRecruiter)
+
+	// Define the list of test cases
+	testCases := []struct {
+		name                 string                        // Name of the test case
+		url                  string                        // URL of the request
+		body                 string                        // Body to send to request
+		claims               *auth.Claims                  // Claims of the caller, nil when not logged in
+		expectedStatus       int                           // Expected status of the response
+		expectedResponse     string                        // Expected response body, empty when tokens are returned
+		expectedRefreshToken string                        // Expected refresh token in the response
+		mockService          func(m *services.MockService) // Mock service function
+	}{
+		{
+			name:             "Login_MFARequired",
+			url:              "/api/login",
+			body:             `{"email":"stym@email.com","password":"Passw0rd123"}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"mfa_required":true,"mfa_token":"mfa-token"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().Authenticate(gomock.Any(), gomock.Eq("stym@email.com"), gomock.Eq("Passw0rd123")).Times(1).
+					Return(auth.Claims{}, "mfa-token", nil)
+				m.EXPECT().IssueRefreshToken(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:                 "LoginMFA_OK",
+			url:                  "/api/login/mfa",
+			body:                 `{"mfa_token":"mfa-token","code":"123456"}`,
+			expectedStatus:       http.StatusOK,
+			expectedRefreshToken: "refresh-token",
+			mockService: func(m *services.MockService) {
+				m.EXPECT().VerifyMFA(gomock.Any(), gomock.Eq("mfa-token"), gomock.Eq("123456")).Times(1).Return(claims, nil)
+				m.EXPECT().IssueRefreshToken(gomock.Any(), gomock.Eq("7")).Times(1).Return("refresh-token", nil)
+			},
+		},
+		{
+			name:             "LoginMFA_Fail_Locked",
+			url:              "/api/login/mfa",
+			body:             `{"mfa_token":"mfa-token","code":"000000"}`,
+			expectedStatus:   http.StatusTooManyRequests,
+			expectedResponse: `{"type":"about:blank","title":"Too Many Requests","status":429,"detail":"too many failed logins, try again in 15m0s","instance":"/api/login/mfa","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().VerifyMFA(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(auth.Claims{}, &services.Error{Kind: services.ErrTooManyRequests, Detail: "too many failed logins, try again in 15m0s"})
+				m.EXPECT().IssueRefreshToken(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "LoginMFA_Fail_NoCode",
+			url:              "/api/login/mfa",
+			body:             `{"mfa_token":"mfa-token"}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/login/mfa","trace_id":"fake-trace-id","errors":[{"field":"code","rule":"required","message":"is required"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().VerifyMFA(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "BeginTOTPEnrollment_OK",
+			url:              "/api/mfa/totp",
+			claims:           &claims,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"secret":"JBSWY3DPEHPK3PXP","provisioning_uri":"otpauth://totp/Job%20Portal:stym@email.com?algorithm=SHA1\u0026digits=6\u0026issuer=Job+Portal\u0026period=30\u0026secret=JBSWY3DPEHPK3PXP"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().BeginTOTPEnrollment(gomock.Any(), gomock.Eq("7")).Times(1).Return(models.TOTPEnrollment{
+					Secret:          "JBSWY3DPEHPK3PXP",
+					ProvisioningURI: "otpauth://totp/Job%20Portal:stym@email.com?algorithm=SHA1&digits=6&issuer=Job+Portal&period=30&secret=JBSWY3DPEHPK3PXP",
+				}, nil)
+			},
+		},
+		{
+			name:             "BeginTOTPEnrollment_Fail_AlreadyEnabled",
+			url:              "/api/mfa/totp",
+			claims:           &claims,
+			expectedStatus:   http.StatusConflict,
+			expectedResponse: `{"type":"about:blank","title":"Conflict","status":409,"detail":"two-factor authentication is already enabled","instance":"/api/mfa/totp","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().BeginTOTPEnrollment(gomock.Any(), gomock.Any()).Times(1).
+					Return(models.TOTPEnrollment{}, &services.Error{Kind: services.ErrConflict, Detail: "two-factor authentication is already enabled"})
+			},
+		},
+		{
+			name:             "BeginTOTPEnrollment_Fail_NotLoggedIn",
+			url:              "/api/mfa/totp",
+			expectedStatus:   http.StatusUnauthorized,
+			expectedResponse: `{"type":"about:blank","title":"Unauthorized","status":401,"instance":"/api/mfa/totp","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().BeginTOTPEnrollment(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "ConfirmTOTPEnrollment_OK",
+			url:              "/api/mfa/totp/confirm",
+			body:             `{"code":"123456"}`,
+			claims:           &claims,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"recovery_codes":["abcd-efgh","ijkl-mnop"]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().ConfirmTOTPEnrollment(gomock.Any(), gomock.Eq("7"), gomock.Eq("123456")).Times(1).
+					Return([]string{"abcd-efgh", "ijkl-mnop"}, nil)
+			},
+		},
+		{
+			name:             "ConfirmTOTPEnrollment_Fail_WrongCode",
+			url:              "/api/mfa/totp/confirm",
+			body:             `{"code":"000000"}`,
+			claims:           &claims,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the code doesn't match, check the clock of your device","instance":"/api/mfa/totp/confirm","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().ConfirmTOTPEnrollment(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, &services.Error{Kind: services.ErrInvalidInput, Detail: "the code doesn't match, check the clock of your device"})
+			},
+		},
+		{
+			name:           "DisableTOTP_OK",
+			url:            "/api/mfa/totp/disable",
+			body:           `{"code":"abcd-efgh"}`,
+			claims:         &claims,
+			expectedStatus: http.StatusNoContent,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().DisableTOTP(gomock.Any(), gomock.Eq("7"), gomock.Eq("abcd-efgh")).Times(1).Return(nil)
+			},
+		},
+		{
+			name:             "DisableTOTP_Fail_NoCode",
+			url:              "/api/mfa/totp/disable",
+			body:             `{}`,
+			claims:           &claims,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/mfa/totp/disable","trace_id":"fake-trace-id","errors":[{"field":"code","rule":"required","message":"is required"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().DisableTOTP(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Gomock controller.
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+
+			// Apply the mock to the user service.
+			tc.mockService(mockS)
+
+			// Insert the TraceId, and the claims of a logged in caller, into the context.
+			ctx := context.WithValue(context.Background(), middlewares.TraceIdKey, "fake-trace-id")
+			if tc.claims != nil {
+				ctx = context.WithValue(ctx, auth.Key, *tc.claims)
+			}
+
+			router := gin.New()
+			h := handler{s: mockS, a: a}
+			router.POST("/api/login", h.Login)
+			router.POST("/api/login/mfa", h.LoginMFA)
+			router.POST("/api/mfa/totp", h.BeginTOTPEnrollment)
+			router.POST("/api/mfa/totp/confirm", h.ConfirmTOTPEnrollment)
+			router.POST("/api/mfa/totp/disable", h.DisableTOTP)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, tc.url, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			// Assert the returned HTTP status code is as expected.
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			if tc.expectedRefreshToken == "" {
+				require.Equal(t, tc.expectedResponse, resp.Body.String())
+				return
+			}
+
+			// A login that went through returns a signed access token for the claims and the refresh token
+			var tkn tokenResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&tkn))
+			require.Equal(t, tc.expectedRefreshToken, tkn.RefreshToken)
+			got, err := a.ValidateToken(context.Background(), tkn.Token)
+			require.NoError(t, err)
+			require.Equal(t, claims.Subject, got.Subject)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"job-portal-api/internal/metrics"
+	middlewares "job-portal-api/internal/middleware"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/problem"
+	"job-portal-api/internal/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// oidcCookie keeps the state, nonce and code verifier of a single sign-on login in the browser of the user
+	// until the provider sends them back
+	oidcCookie = "oidc_login"
+	// oidcCookieMaxAge is how long the user has to log in at the provider, in seconds
+	oidcCookieMaxAge = 600
+	oidcCookiePath   = "/api/oidc"
+)
+
+// OIDCLogin sends the user to the single sign-on provider to log in
+func (h *handler) OIDCLogin(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	login, err := h.s.BeginOIDCLogin(ctx)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	// The cookie ties the callback to this browser, so a code can't be injected into the session of someone else.
+	// It has to be sent along when the provider redirects back, which SameSite=Lax allows.
+	value := strings.Join([]string{login.State, login.Nonce, login.CodeVerifier}, ".")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcCookie, value, oidcCookieMaxAge, oidcCookiePath, "", isSecure(c), true)
+	c.Redirect(http.StatusFound, login.AuthorizationURL)
+}
+
+// OIDCCallback completes a single sign-on login when the provider sends the user back, and returns an access and a
+// refresh token like Login
+func (h *handler) OIDCCallback(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	// The login can only be completed once
+	cookie, _ := c.Cookie(oidcCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcCookie, "", -1, oidcCookiePath, "", isSecure(c), true)
+
+	if e := c.Query("error"); e != "" {
+		log.Info().Str("Trace Id", traceId).Str("error", e).Str("description", c.Query("error_description")).
+			Msg("single sign-on provider refused the login")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "the single sign-on provider refused the login")
+		return
+	}
+
+	parts := strings.Split(cookie, ".")
+	state := c.Query("state")
+	if len(parts) != 3 || state == "" || subtle.ConstantTimeCompare([]byte(parts[0]), []byte(state)) != 1 {
+		log.Info().Str("Trace Id", traceId).Msg("single sign-on callback without matching login")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "the single sign-on login expired or was started elsewhere, try again")
+		return
+	}
+	code := c.Query("code")
+	if code == "" {
+		problem.Abort(c, http.StatusBadRequest, traceId, "please provide code")
+		return
+	}
+
+	claims, mfaToken, err := h.s.CompleteOIDCLogin(ctx, code, parts[2], parts[1])
+	if err != nil {
+		if errors.Is(err, services.ErrUnauthenticated) {
+			metrics.LoginFailed()
+		}
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	// With two-factor authentication the client has to send a code to /api/login/mfa before getting tokens
+	if mfaToken != "" {
+		c.JSON(http.StatusOK, models.MFALogin{MFARequired: true, MFAToken: mfaToken})
+		return
+	}
+	metrics.LoginSucceeded()
+
+	h.respondWithTokens(c, traceId, claims)
+}
+
+// isSecure reports whether the request came over HTTPS, directly or through a proxy
+func isSecure(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
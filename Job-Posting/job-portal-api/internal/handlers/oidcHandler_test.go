@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"job-portal-api/internal/auth"
+	middlewares "job-portal-api/internal/middleware"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOIDCLogin(t *testing.T) {
+	// Sets the Gin router mode to test.
+	gin.SetMode(gin.TestMode)
+
+	login := models.OIDCLogin{
+		AuthorizationURL: "https://idp.example/authorize?state=the-state",
+		State:            "the-state",
+		Nonce:            "the-nonce",
+		CodeVerifier:     "the-verifier",
+	}
+
+	// Define the list of test cases
+	testCases := []struct {
+		name             string                        // Name of the test case
+		header           http.Header                   // Headers of the request
+		expectedStatus   int                           // Expected status of the response
+		expectedResponse string                        // Expected response body, empty when redirected
+		expectedCookie   string                        // Expected cookie set by the response
+		mockService      func(m *services.MockService) // Mock service function
+	}{
+		{
+			name:           "OK",
+			expectedStatus: http.StatusFound,
+			expectedCookie: "oidc_login=the-state.the-nonce.the-verifier; Path=/api/oidc; Max-Age=600; HttpOnly; SameSite=Lax",
+			mockService: func(m *services.MockService) {
+				m.EXPECT().BeginOIDCLogin(gomock.Any()).Times(1).Return(login, nil)
+			},
+		},
+		{
+			name:           "OK_BehindTLSProxy",
+			header:         http.Header{"X-Forwarded-Proto": {"https"}},
+			expectedStatus: http.StatusFound,
+			expectedCookie: "oidc_login=the-state.the-nonce.the-verifier; Path=/api/oidc; Max-Age=600; HttpOnly; Secure; SameSite=Lax",
+			mockService: func(m *services.MockService) {
+				m.EXPECT().BeginOIDCLogin(gomock.Any()).Times(1).Return(login, nil)
+			},
+		},
+		{
+			name:             "Fail_Disabled",
+			expectedStatus:   http.StatusNotFound,
+			expectedResponse: `{"type":"about:blank","title":"Not Found","status":404,"detail":"single sign-on is not enabled","instance":"/api/oidc/login","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().BeginOIDCLogin(gomock.Any()).Times(1).
+					Return(models.OIDCLogin{}, &services.Error{Kind: services.ErrNotFound, Detail: "single sign-on is not enabled"})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Gomock controller.
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+
+			// Apply the mock to the user service.
+			tc.mockService(mockS)
+
+			// Insert the TraceId into the context.
+			ctx := context.WithValue(context.Background(), middlewares.TraceIdKey, "fake-trace-id")
+
+			router := gin.New()
+			h := handler{s: mockS}
+			router.GET("/api/oidc/login", h.OIDCLogin)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/api/oidc/login", nil)
+			require.NoError(t, err)
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			// Assert the returned HTTP status code is as expected.
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			if tc.expectedCookie == "" {
+				require.Equal(t, tc.expectedResponse, resp.Body.String())
+				return
+			}
+			require.Equal(t, login.AuthorizationURL, resp.Header().Get("Location"))
+			require.Equal(t, tc.expectedCookie, resp.Header().Get("Set-Cookie"))
+		})
+	}
+}
+
+func TestOIDCCallback(t *testing.T) {
+	// Sets the Gin router mode to test.
+	gin.SetMode(gin.TestMode)
+
+	// Create an Auth with a throwaway key pair so tokens can be signed.
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	a, err := auth.NewAuth(privateKey, &privateKey.PublicKey)
+	require.NoError(t, err)
+
+	claims := auth.NewClaims("7", models.RoleRecruiter)
+	const cookie = "the-state.the-nonce.the-verifier"
+
+	// Define the list of test cases
+	testCases := []struct {
+		name                 string                        // Name of the test case
+		query                string                        // Query the provider redirects back with
+		cookie               string                        // Login cookie sent by the browser
+		expectedStatus       int                           // Expected status of the response
+		expectedResponse     string                        // Expected response body, empty when tokens are returned
+		expectedRefreshToken string                        // Expected refresh token in the response
+		mockService          func(m *services.MockService) // Mock service function
+	}{
+		{
+			name:                 "OK",
+			query:                "?code=the-code&state=the-state",
+			cookie:               cookie,
+			expectedStatus:       http.StatusOK,
+			expectedRefreshToken: "refresh-token",
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CompleteOIDCLogin(gomock.Any(), gomock.Eq("the-code"), gomock.Eq("the-verifier"), gomock.Eq("the-nonce")).
+					Times(1).Return(claims, "", nil)
+				m.EXPECT().IssueRefreshToken(gomock.Any(), gomock.Eq("7")).Times(1).Return("refresh-token", nil)
+			},
+		},
+		{
+			name:             "OK_MFARequired",
+			query:            "?code=the-code&state=the-state",
+			cookie:           cookie,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"mfa_required":true,"mfa_token":"mfa-token"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CompleteOIDCLogin(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(auth.Claims{}, "mfa-token", nil)
+				m.EXPECT().IssueRefreshToken(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_StateMismatch",
+			query:            "?code=the-code&state=other-state",
+			cookie:           cookie,
+			expectedStatus:   http.StatusUnauthorized,
+			expectedResponse: `{"type":"about:blank","title":"Unauthorized","status":401,"detail":"the single sign-on login expired or was started elsewhere, try again","instance":"/api/oidc/callback","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CompleteOIDCLogin(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_NoCookie",
+			query:            "?code=the-code&state=the-state",
+			expectedStatus:   http.StatusUnauthorized,
+			expectedResponse: `{"type":"about:blank","title":"Unauthorized","status":401,"detail":"the single sign-on login expired or was started elsewhere, try again","instance":"/api/oidc/callback","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CompleteOIDCLogin(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_ProviderRefused",
+			query:            "?error=access_denied&state=the-state",
+			cookie:           cookie,
+			expectedStatus:   http.StatusUnauthorized,
+			expectedResponse: `{"type":"about:blank","title":"Unauthorized","status":401,"detail":"the single sign-on provider refused the login","instance":"/api/oidc/callback","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CompleteOIDCLogin(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_NoCode",
+			query:            "?state=the-state",
+			cookie:           cookie,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"please provide code","instance":"/api/oidc/callback","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CompleteOIDCLogin(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_DomainNotAllowed",
+			query:            "?code=the-code&state=the-state",
+			cookie:           cookie,
+			expectedStatus:   http.StatusForbidden,
+			expectedResponse: `{"type":"about:blank","title":"Forbidden","status":403,"detail":"single sign-on isn't allowed for your email domain","instance":"/api/oidc/callback","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().CompleteOIDCLogin(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(auth.Claims{}, "", &services.Error{Kind: services.ErrForbidden, Detail: "single sign-on isn't allowed for your email domain"})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Gomock controller.
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+
+			// Apply the mock to the user service.
+			tc.mockService(mockS)
+
+			// Insert the TraceId into the context.
+			ctx := context.WithValue(context.Background(), middlewares.TraceIdKey, "fake-trace-id")
+
+			router := gin.New()
+			h := handler{s: mockS, a: a}
+			router.GET("/api/oidc/callback", h.OIDCCallback)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/api/oidc/callback"+tc.query, nil)
+			require.NoError(t, err)
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "oidc_login", Value: tc.cookie})
+			}
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			// Assert the returned HTTP status code is as expected, and that the login can't be completed twice.
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			require.Equal(t, "oidc_login=; Path=/api/oidc; Max-Age=0; HttpOnly; SameSite=Lax", resp.Header().Get("Set-Cookie"))
+			if tc.expectedRefreshToken == "" {
+				require.Equal(t, tc.expectedResponse, resp.Body.String())
+				return
+			}
+
+			// A login that went through returns a signed access token for the claims and the refresh token
+			var tkn tokenResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&tkn))
+			require.Equal(t, tc.expectedRefreshToken, tkn.RefreshToken)
+			got, err := a.ValidateToken(context.Background(), tkn.Token)
+			require.NoError(t, err)
+			require.Equal(t, claims.Subject, got.Subject)
+		})
+	}
+}
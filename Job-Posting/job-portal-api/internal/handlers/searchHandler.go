@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"job-portal-api/internal/auth"
+	middlewares "job-portal-api/internal/middleware"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/problem"
+	"job-portal-api/internal/validate"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Search runs a ranked full-text search over jobs and companies
+func (h *handler) Search(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceID, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceID).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceID, "")
+		return
+	}
+
+	var sq models.SearchQuery
+	err := c.ShouldBindQuery(&sq)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceID).Send()
+		problem.Abort(c, http.StatusBadRequest, traceID, "Invalid query parameters")
+		return
+	}
+	err = validate.Struct(sq)
+	if err != nil {
+		abortWithError(c, traceID, err)
+		return
+	}
+
+	page, err := h.s.Search(ctx, sq, claims.Subject)
+	if err != nil {
+		abortWithError(c, traceID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
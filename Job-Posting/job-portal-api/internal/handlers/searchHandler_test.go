@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+	"job-portal-api/internal/auth"
+	middlewares "job-portal-api/internal/middleware"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/problem"
+	"job-portal-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSearch(t *testing.T) {
+	// Sets the Gin router mode to test.
+	gin.SetMode(gin.TestMode)
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleCandidate},
+	}
+
+	// Define the list of test cases
+	testCases := []struct {
+		name             string                        // Name of the test case
+		query            string                        // Query string of the request
+		expectedStatus   int                           // Expected status of the response
+		expectedResponse string                        // Expected response body
+		mockService      func(m *services.MockService) // Mock service function
+	}{
+		{
+			name:             "OK",
+			query:            "?q=golang&page=2&limit=1",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"results":[{"job":{"ID":1,"CreatedAt":"0001-01-01T00:00:00Z","UpdatedAt":"0001-01-01T00:00:00Z","DeletedAt":null,"title":"Golang Developer","description":"","company_id":1,"location":"","work_mode":"","employment_type":"","status":""},"company_name":"infy","rank":0.5,"title_highlight":"\u003cmark\u003eGolang\u003c/mark\u003e Developer","snippet":""}],"total":2,"page":2}`,
+			mockService: func(m *services.MockService) {
+				sq := models.SearchQuery{Query: "golang", Page: 2, Limit: 1}
+				m.EXPECT().Search(gomock.Any(), gomock.Eq(sq), gomock.Eq("1")).Times(1).
+					Return(models.SearchPage{
+						Results: []models.SearchResult{{
+							Job:            models.Job{Model: gorm.Model{ID: 1}, Title: "Golang Developer", CompanyID: 1},
+							CompanyName:    "infy",
+							Rank:           0.5,
+							TitleHighlight: "<mark>Golang</mark> Developer",
+						}},
+						Total: 2,
+						Page:  2,
+					}, nil)
+			},
+		},
+		{
+			name:             "Fail_Timeout",
+			query:            "?q=golang",
+			expectedStatus:   http.StatusGatewayTimeout,
+			expectedResponse: `{"type":"about:blank","title":"Gateway Timeout","status":504,"instance":"/api/search","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().Search(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(models.SearchPage{}, fmt.Errorf("searching jobs: %w", context.DeadlineExceeded))
+			},
+		},
+		{
+			name:             "Fail_NoQuery",
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/search","trace_id":"fake-trace-id","errors":[{"field":"q","rule":"required","message":"is required"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().Search(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_PageTooFar",
+			query:            "?q=golang&page=101",
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/search","trace_id":"fake-trace-id","errors":[{"field":"page","rule":"max","message":"must be at most 100"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().Search(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Gomock controller.
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+
+			// Apply the mock to the user service.
+			tc.mockService(mockS)
+
+			// Insert the claims and TraceId into the context.
+			ctx := context.WithValue(context.Background(), auth.Key, fakeClaims)
+			ctx = context.WithValue(ctx, middlewares.TraceIdKey, "fake-trace-id")
+
+			router := gin.New()
+			h := handler{s: mockS}
+			router.GET("/api/search", h.Search)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/api/search"+tc.query, nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			// Assert the returned HTTP status code and body are as expected.
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			require.Equal(t, tc.expectedResponse, resp.Body.String())
+		})
+	}
+}
+
+func TestSearchTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+		},
+		Roles: []string{models.RoleCandidate},
+	}
+
+	ctrl := gomock.NewController(t)
+	mockS := services.NewMockService(ctrl)
+	// The search only returns once the deadline set by the middleware cancels its context
+	mockS.EXPECT().Search(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(ctx context.Context, sq models.SearchQuery, userId string) (models.SearchPage, error) {
+			<-ctx.Done()
+			return models.SearchPage{}, ctx.Err()
+		})
+
+	ctx := context.WithValue(context.Background(), auth.Key, fakeClaims)
+	ctx = context.WithValue(ctx, middlewares.TraceIdKey, "fake-trace-id")
+
+	router := gin.New()
+	h := handler{s: mockS}
+	m := middlewares.Mid{}
+	router.GET("/api/search", m.Timeout(h.Search, 10*time.Millisecond))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/api/search?q=golang", nil)
+	require.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusGatewayTimeout, resp.Code)
+	require.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+	require.Equal(t, `{"type":"about:blank","title":"Gateway Timeout","status":504,"instance":"/api/search","trace_id":"fake-trace-id"}`, resp.Body.String())
+}
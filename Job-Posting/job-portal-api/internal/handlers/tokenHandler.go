@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"job-portal-api/internal/auth"
+	middlewares "job-portal-api/internal/middleware"
+	"job-portal-api/internal/problem"
+	"job-portal-api/internal/validate"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// tokenResponse is returned on login and refresh.
+type tokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh token
+func (h *handler) Refresh(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	var req struct {
+		RefreshToken string `json:"refresh_token" validate:"required"`
+	}
+	err := json.NewDecoder(c.Request.Body).Decode(&req)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "please provide refresh_token")
+		return
+	}
+	err = validate.Struct(req)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	claims, refreshToken, err := h.s.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	tkn := tokenResponse{RefreshToken: refreshToken}
+	tkn.Token, err = h.a.GenerateToken(claims)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Msg("generating token")
+		problem.Abort(c, http.StatusInternalServerError, traceId, "")
+		return
+	}
+
+	c.JSON(http.StatusOK, tkn)
+}
+
+// Logout revokes the access token used for the request and, if one is sent in the body, the refresh token
+func (h *handler) Logout(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if !ok {
+		log.Error().Str("Trace Id", traceId).Msg("login first")
+		problem.Abort(c, http.StatusUnauthorized, traceId, "")
+		return
+	}
+
+	// The body is optional, clients that only hold an access token can still log out
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	err := json.NewDecoder(c.Request.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "invalid request body")
+		return
+	}
+
+	err = h.s.Logout(ctx, claims, req.RefreshToken)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// JWKS publishes the public keys tokens are signed with, so other services can verify them
+func (h *handler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.a.JWKS())
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"job-portal-api/internal/auth"
+	middlewares "job-portal-api/internal/middleware"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRefresh(t *testing.T) {
+	// Sets the Gin router mode to test.
+	gin.SetMode(gin.TestMode)
+
+	// Create an Auth with a throwaway key pair so tokens can be signed.
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	a, err := auth.NewAuth(privateKey, &privateKey.PublicKey)
+	require.NoError(t, err)
+
+	claims := auth.NewClaims("1", models.RoleCandidate)
+
+	// Define the list of test cases
+	testCases := []struct {
+		name                 string                        // Name of the test case
+		body                 string                        // Body to send to request
+		expectedStatus       int                           // Expected status of the response
+		expectedResponse     string                        // Expected response body, empty when tokens are returned
+		expectedRefreshToken string                        // Expected refresh token in the response
+		mockService          func(m *services.MockService) // Mock service function
+	}{
+		{
+			name:                 "OK",
+			body:                 `{"refresh_token":"old-refresh-token"}`,
+			expectedStatus:       http.StatusOK,
+			expectedRefreshToken: "new-refresh-token",
+			mockService: func(m *services.MockService) {
+				m.EXPECT().Refresh(gomock.Any(), gomock.Eq("old-refresh-token")).Times(1).
+					Return(claims, "new-refresh-token", nil)
+			},
+		},
+		{
+			name:             "Fail_InvalidRefreshToken",
+			body:             `{"refresh_token":"used-refresh-token"}`,
+			expectedStatus:   http.StatusUnauthorized,
+			expectedResponse: `{"type":"about:blank","title":"Unauthorized","status":401,"detail":"invalid refresh token","instance":"/api/token/refresh","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().Refresh(gomock.Any(), gomock.Any()).Times(1).
+					Return(auth.Claims{}, "", services.ErrInvalidRefreshToken)
+			},
+		},
+		{
+			name:             "Fail_NoRefreshToken",
+			body:             `{}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/token/refresh","trace_id":"fake-trace-id","errors":[{"field":"refresh_token","rule":"required","message":"is required"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().Refresh(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Gomock controller.
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+
+			// Apply the mock to the user service.
+			tc.mockService(mockS)
+
+			// Insert the TraceId into the context.
+			ctx := context.WithValue(context.Background(), middlewares.TraceIdKey, "fake-trace-id")
+
+			router := gin.New()
+			h := handler{s: mockS, a: a}
+			router.POST("/api/token/refresh", h.Refresh)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/api/token/refresh", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			// Assert the returned HTTP status code is as expected.
+			require.Equal(t, tc.expectedStatus, resp.Code)
+
+			if tc.expectedResponse != "" {
+				require.Equal(t, tc.expectedResponse, resp.Body.String())
+				return
+			}
+
+			// The access token is signed on every call, so check it validates instead of comparing bytes.
+			var tkn tokenResponse
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &tkn))
+			require.Equal(t, tc.expectedRefreshToken, tkn.RefreshToken)
+			got, err := a.ValidateToken(ctx, tkn.Token)
+			require.NoError(t, err)
+			require.Equal(t, claims.ID, got.ID)
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	// Sets the Gin router mode to test.
+	gin.SetMode(gin.TestMode)
+	fakeClaims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: "1",
+			ID:      "fake-jti",
+		},
+	}
+
+	// Define the list of test cases
+	testCases := []struct {
+		name           string                        // Name of the test case
+		body           string                        // Body to send to request
+		expectedStatus int                           // Expected status of the response
+		mockService    func(m *services.MockService) // Mock service function
+	}{
+		{
+			name:           "OK",
+			body:           `{"refresh_token":"refresh-token"}`,
+			expectedStatus: http.StatusNoContent,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().Logout(gomock.Any(), gomock.Eq(fakeClaims), gomock.Eq("refresh-token")).Times(1).
+					Return(nil)
+			},
+		},
+		{
+			name:           "OK_NoBody",
+			expectedStatus: http.StatusNoContent,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().Logout(gomock.Any(), gomock.Eq(fakeClaims), gomock.Eq("")).Times(1).
+					Return(nil)
+			},
+		},
+		{
+			name:           "Fail_RefreshTokenOfAnotherUser",
+			body:           `{"refresh_token":"stolen-token"}`,
+			expectedStatus: http.StatusNotFound,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().Logout(gomock.Any(), gomock.Eq(fakeClaims), gomock.Eq("stolen-token")).Times(1).
+					Return(&services.Error{Kind: services.ErrNotFound, Detail: "refresh token not found"})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Gomock controller.
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+
+			// Apply the mock to the user service.
+			tc.mockService(mockS)
+
+			// Insert the claims and TraceId into the context.
+			ctx := context.WithValue(context.Background(), auth.Key, fakeClaims)
+			ctx = context.WithValue(ctx, middlewares.TraceIdKey, "fake-trace-id")
+
+			router := gin.New()
+			h := handler{s: mockS}
+			router.POST("/api/logout", h.Logout)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/api/logout", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			// Assert the returned HTTP status code is as expected.
+			require.Equal(t, tc.expectedStatus, resp.Code)
+		})
+	}
+}
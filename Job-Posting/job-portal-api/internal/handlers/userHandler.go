@@ -2,20 +2,26 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"job-portal-api/internal/auth"
+	"job-portal-api/internal/health"
+	"job-portal-api/internal/metrics"
 	middlewares "job-portal-api/internal/middleware"
 	"job-portal-api/internal/models"
+	"job-portal-api/internal/problem"
 	"job-portal-api/internal/services"
+	"job-portal-api/internal/validate"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog/log"
 )
 
 type handler struct {
-	s services.Store
-	a *auth.Auth
+	s  services.Service
+	a  *auth.Auth
+	hc *health.Checker
 }
 
 func (h *handler) Register(c *gin.Context) {
@@ -24,7 +30,7 @@ func (h *handler) Register(c *gin.Context) {
 	if !ok {
 		// If the traceId isn't found in the request, log an error and return
 		log.Error().Msg("traceId missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": http.StatusText(http.StatusInternalServerError)})
+		problem.Abort(c, http.StatusInternalServerError, "", "")
 		return
 	}
 
@@ -35,26 +41,22 @@ func (h *handler) Register(c *gin.Context) {
 	err := json.NewDecoder(c.Request.Body).Decode(&nu)
 	if err != nil {
 		// If there is an error in decoding, log the error and return
-		log.Error().Err(err).Str("Trace Id", traceId)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": http.StatusText(http.StatusInternalServerError)})
+		log.Error().Err(err).Str("Trace Id", traceId).Msg("failed to parse request body")
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid request body")
 		return
 	}
 
-	// Create a new validator and validate the NewUser variable
-	validate := validator.New()
+	// Validate the NewUser variable against its validate tags
 	err = validate.Struct(nu)
 	if err != nil {
-		// If validation fails, log the error and return
-		log.Error().Err(err).Str("Trace Id", traceId).Send()
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "please provide Name, Email and Password"})
+		abortWithError(c, traceId, err)
 		return
 	}
 
 	// Attempt to create the user
 	usr, err := h.s.CreateUser(ctx, nu)
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceId).Msg("user signup problem")
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "user signup failed"})
+		abortWithError(c, traceId, err)
 		return
 	}
 
@@ -62,13 +64,52 @@ func (h *handler) Register(c *gin.Context) {
 	c.JSON(http.StatusOK, usr)
 }
 
+// SetUserRole lets an admin make a user a recruiter, or a candidate again
+func (h *handler) SetUserRole(c *gin.Context) {
+	ctx := c.Request.Context()
+	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
+	if !ok {
+		log.Error().Msg("traceId missing from context")
+		problem.Abort(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Send()
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid user ID")
+		return
+	}
+
+	var ur models.UserRole
+	err = json.NewDecoder(c.Request.Body).Decode(&ur)
+	if err != nil {
+		log.Error().Err(err).Str("Trace Id", traceId).Msg("failed to parse request body")
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid request body")
+		return
+	}
+	err = validate.Struct(ur)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	usr, err := h.s.SetUserRole(ctx, uint(userID), ur)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usr)
+}
+
 // Login is a method for the handler struct which handles user login
 func (h *handler) Login(c *gin.Context) {
 	ctx := c.Request.Context()
 	traceId, ok := ctx.Value(middlewares.TraceIdKey).(string)
 	if !ok {
 		log.Error().Msg("traceId missing from context")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": http.StatusText(http.StatusInternalServerError)})
+		problem.Abort(c, http.StatusInternalServerError, "", "")
 		return
 	}
 
@@ -81,42 +122,57 @@ func (h *handler) Login(c *gin.Context) {
 	// Attempt to decode JSON from the request body into the login variable
 	err := json.NewDecoder(c.Request.Body).Decode(&login)
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceId)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": http.StatusText(http.StatusInternalServerError)})
+		log.Error().Err(err).Str("Trace Id", traceId).Msg("failed to parse request body")
+		problem.Abort(c, http.StatusBadRequest, traceId, "Invalid request body")
 		return
 	}
 
-	// Create a new validator and validate the login variable
-	validate := validator.New()
+	// Validate the login variable against its validate tags
 	err = validate.Struct(login)
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceId).Send()
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"msg": "please provide Email and Password"})
+		abortWithError(c, traceId, err)
 		return
 	}
 
 	// Attempt to authenticate the user with the email and password
-	claims, err := h.s.Authenticate(ctx, login.Email, login.Password)
+	claims, mfaToken, err := h.s.Authenticate(ctx, login.Email, login.Password)
 	if err != nil {
-		log.Error().Err(err).Str("Trace Id", traceId).Send()
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"msg": "login failed"})
+		if errors.Is(err, services.ErrUnauthenticated) {
+			metrics.LoginFailed()
+		}
+		abortWithError(c, traceId, err)
 		return
 	}
 
-	// Define a new struct for the token
-	var tkn struct {
-		Token string `json:"token"`
+	// With two-factor authentication the client has to send a code to /api/login/mfa before getting tokens
+	if mfaToken != "" {
+		c.JSON(http.StatusOK, models.MFALogin{MFARequired: true, MFAToken: mfaToken})
+		return
 	}
+	metrics.LoginSucceeded()
+
+	h.respondWithTokens(c, traceId, claims)
+}
 
-	// Generate a new token and put it in the Token field of the token struct
+// respondWithTokens issues an access and a refresh token for claims, the last step of a login
+func (h *handler) respondWithTokens(c *gin.Context, traceId string, claims auth.Claims) {
+	// Generate a new access token and put it in the Token field of the token struct
+	var tkn tokenResponse
+	var err error
 	tkn.Token, err = h.a.GenerateToken(claims)
 	if err != nil {
 		log.Error().Err(err).Msg("generating token")
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"msg": http.StatusText(http.StatusInternalServerError)})
+		problem.Abort(c, http.StatusInternalServerError, traceId, "")
 		return
 	}
 
-	// If everything goes right, respond with the token
-	c.JSON(http.StatusOK, tkn.Token)
+	// Issue a refresh token so the client can renew the access token without logging in again
+	tkn.RefreshToken, err = h.s.IssueRefreshToken(c.Request.Context(), claims.Subject)
+	if err != nil {
+		abortWithError(c, traceId, err)
+		return
+	}
 
+	// If everything goes right, respond with the tokens
+	c.JSON(http.StatusOK, tkn)
 }
@@ -8,6 +8,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 	"gorm.io/gorm"
+	"job-portal-api/internal/auth"
+	"job-portal-api/internal/metrics"
 	middlewares "job-portal-api/internal/middleware"
 	"job-portal-api/internal/models"
 	"job-portal-api/internal/services"
@@ -21,7 +23,7 @@ func TestRegister(t *testing.T) {
 	nu := models.NewUser{
 		Name:     "stym",
 		Email:    "stym@email.com",
-		Password: "password",
+		Password: "Passw0rd123",
 	}
 	mockUser := models.User{
 		Model: gorm.Model{
@@ -30,6 +32,7 @@ func TestRegister(t *testing.T) {
 		Name:         "satyam",
 		Email:        "satyam@email.com",
 		PasswordHash: "jaldlajjasdf",
+		Role:         models.RoleCandidate,
 	}
 
 	tt := [...]struct {
@@ -44,7 +47,7 @@ func TestRegister(t *testing.T) {
 			name:             "OK",
 			body:             nu,
 			expectedStatus:   http.StatusOK,
-			expectedResponse: `{"ID":1,"CreatedAt":"0001-01-01T00:00:00Z","UpdatedAt":"0001-01-01T00:00:00Z","DeletedAt":null,"name":"satyam","email":"satyam@email.com"}`,
+			expectedResponse: `{"ID":1,"CreatedAt":"0001-01-01T00:00:00Z","UpdatedAt":"0001-01-01T00:00:00Z","DeletedAt":null,"name":"satyam","email":"satyam@email.com","role":"candidate"}`,
 			//set expectations inside it
 			mockUserService: func(m *services.MockService) {
 				m.EXPECT().CreateUser(gomock.Any(), gomock.Eq(nu)).
@@ -56,10 +59,23 @@ func TestRegister(t *testing.T) {
 			name: "Fail_NoEmail",
 			body: models.NewUser{
 				Name:     "testuser",
+				Password: "Passw0rd123",
+			},
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/register","trace_id":"fake-trace-id","errors":[{"field":"email","rule":"required","message":"is required"}]}`,
+			mockUserService: func(m *services.MockService) {
+				m.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "Fail_WeakPassword",
+			body: models.NewUser{
+				Name:     "testuser",
+				Email:    "test@email.com",
 				Password: "password",
 			},
 			expectedStatus:   http.StatusBadRequest,
-			expectedResponse: `{"msg":"please provide Name, Email and Password"}`,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/register","trace_id":"fake-trace-id","errors":[{"field":"password","rule":"password","message":"must be 8 to 72 characters long and contain an upper case letter, a lower case letter and a digit"}]}`,
 			mockUserService: func(m *services.MockService) {
 				m.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
 			},
@@ -72,14 +88,13 @@ func TestRegister(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			//this func give us the mock implementation of the interface
 			mockService := services.NewMockService(ctrl)
-			s := services.NewStore(mockService)
 
 			// Apply the mock to the user service.
 			tc.mockUserService(mockService)
 			// Create a new Gin router.
 			router := gin.New()
 			h := handler{
-				s: s,
+				s: mockService,
 			}
 			ctx := context.Background()
 			// Create a fake TraceID. This would typically be used for request tracing.
@@ -114,3 +129,119 @@ func TestRegister(t *testing.T) {
 		})
 	}
 }
+
+func TestLoginMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	mockService := services.NewMockService(ctrl)
+	mockService.EXPECT().Authenticate(gomock.Any(), "stym@email.com", "wrong").Times(1).
+		Return(auth.Claims{}, "", &services.Error{Kind: services.ErrUnauthenticated, Detail: "invalid email or password"})
+
+	router := gin.New()
+	h := handler{s: mockService}
+	m := middlewares.Mid{}
+	router.Use(m.Metrics())
+	router.POST("/login", h.Login)
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	ctx := context.WithValue(context.Background(), middlewares.TraceIdKey, "fake-trace-id")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/login",
+		bytes.NewReader([]byte(`{"email":"stym@email.com","password":"wrong"}`)))
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `job_portal_logins_total{result="failure"} 1`)
+	require.Contains(t, rec.Body.String(), `job_portal_http_requests_total{method="POST",route="/login",status="401"} 1`)
+}
+
+func TestSetUserRole(t *testing.T) {
+	// Sets the Gin router mode to test.
+	gin.SetMode(gin.TestMode)
+	recruiter := models.User{Model: gorm.Model{ID: 7}, Name: "stym", Email: "stym@email.com", Role: models.RoleRecruiter}
+
+	// Define the list of test cases
+	testCases := []struct {
+		name             string                        // Name of the test case
+		url              string                        // URL of the request
+		body             string                        // Body to send to request
+		expectedStatus   int                           // Expected status of the response
+		expectedResponse string                        // Expected response body
+		mockService      func(m *services.MockService) // Mock service function
+	}{
+		{
+			name:             "OK",
+			url:              "/api/users/7/role",
+			body:             `{"role":"recruiter"}`,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: `{"ID":7,"CreatedAt":"0001-01-01T00:00:00Z","UpdatedAt":"0001-01-01T00:00:00Z","DeletedAt":null,"name":"stym","email":"stym@email.com","role":"recruiter"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().SetUserRole(gomock.Any(), gomock.Eq(uint(7)), gomock.Eq(models.UserRole{Role: models.RoleRecruiter})).Times(1).
+					Return(recruiter, nil)
+			},
+		},
+		{
+			name:             "Fail_Admin",
+			url:              "/api/users/7/role",
+			body:             `{"role":"admin"}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"the request has invalid fields","instance":"/api/users/7/role","trace_id":"fake-trace-id","errors":[{"field":"role","rule":"oneof","message":"must be one of candidate, recruiter"}]}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().SetUserRole(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_InvalidUserID",
+			url:              "/api/users/abc/role",
+			body:             `{"role":"recruiter"}`,
+			expectedStatus:   http.StatusBadRequest,
+			expectedResponse: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"Invalid user ID","instance":"/api/users/abc/role","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().SetUserRole(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:             "Fail_NotFound",
+			url:              "/api/users/9/role",
+			body:             `{"role":"recruiter"}`,
+			expectedStatus:   http.StatusNotFound,
+			expectedResponse: `{"type":"about:blank","title":"Not Found","status":404,"detail":"user not found","instance":"/api/users/9/role","trace_id":"fake-trace-id"}`,
+			mockService: func(m *services.MockService) {
+				m.EXPECT().SetUserRole(gomock.Any(), gomock.Eq(uint(9)), gomock.Any()).Times(1).
+					Return(models.User{}, &services.Error{Kind: services.ErrNotFound, Detail: "user not found"})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a new Gomock controller.
+			ctrl := gomock.NewController(t)
+			mockS := services.NewMockService(ctrl)
+
+			// Apply the mock to the user service.
+			tc.mockService(mockS)
+
+			// Insert the TraceId into the context.
+			ctx := context.WithValue(context.Background(), middlewares.TraceIdKey, "fake-trace-id")
+
+			router := gin.New()
+			h := handler{s: mockS}
+			router.PUT("/api/users/:userID/role", h.SetUserRole)
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodPut, tc.url, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			// Assert the returned HTTP status code and response are as expected.
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			require.Equal(t, tc.expectedResponse, resp.Body.String())
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Package health tracks whether the API is ready to serve traffic, for the readiness probe of the orchestrator.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check reports whether one dependency of the API is usable. It must give up once ctx is done.
+type Check func(ctx context.Context) error
+
+// Statuses of a Report.
+const (
+	StatusReady        = "ready"
+	StatusNotReady     = "not ready"
+	StatusShuttingDown = "shutting down"
+)
+
+// Report is the outcome of a readiness check. Checks holds "ok" or the error of every check by name.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Checker runs the readiness checks. The zero value isn't usable, use New.
+type Checker struct {
+	timeout      time.Duration
+	names        []string
+	checks       map[string]Check
+	shuttingDown atomic.Bool
+}
+
+// New returns a Checker without checks. Every readiness check is cancelled once timeout has passed.
+func New(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout, checks: make(map[string]Check)}
+}
+
+// Add registers a check under name. Checks must be added before the first call to Ready.
+func (h *Checker) Add(name string, check Check) {
+	if _, ok := h.checks[name]; !ok {
+		h.names = append(h.names, name)
+	}
+	h.checks[name] = check
+}
+
+// ShutDown marks the API as shutting down. Ready reports it as not ready from then on, so that the load balancer
+// stops sending new requests while the ones in flight finish.
+func (h *Checker) ShutDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Ready runs every check concurrently and reports whether all of them passed.
+func (h *Checker) Ready(ctx context.Context) (Report, bool) {
+	if h.shuttingDown.Load() {
+		return Report{Status: StatusShuttingDown}, false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	results := make([]error, len(h.names))
+	var wg sync.WaitGroup
+	for i, name := range h.names {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = check(ctx)
+		}(i, h.checks[name])
+	}
+	wg.Wait()
+
+	r := Report{Status: StatusReady, Checks: make(map[string]string, len(h.names))}
+	for i, name := range h.names {
+		if results[i] != nil {
+			r.Status = StatusNotReady
+			r.Checks[name] = results[i].Error()
+			continue
+		}
+		r.Checks[name] = "ok"
+	}
+	return r, r.Status == StatusReady
+}
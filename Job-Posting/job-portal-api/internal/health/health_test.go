@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReady(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+	// slow only returns once the timeout of the checker cancels it
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tt := []struct {
+		name           string
+		checks         map[string]Check
+		shutDown       bool
+		expectedReady  bool
+		expectedReport Report
+	}{
+		{
+			name:           "Ready",
+			checks:         map[string]Check{"database": ok, "keys": ok},
+			expectedReady:  true,
+			expectedReport: Report{Status: StatusReady, Checks: map[string]string{"database": "ok", "keys": "ok"}},
+		},
+		{
+			name:           "Fail_CheckFails",
+			checks:         map[string]Check{"database": down, "keys": ok},
+			expectedReport: Report{Status: StatusNotReady, Checks: map[string]string{"database": "connection refused", "keys": "ok"}},
+		},
+		{
+			name:           "Fail_CheckTimesOut",
+			checks:         map[string]Check{"database": slow},
+			expectedReport: Report{Status: StatusNotReady, Checks: map[string]string{"database": "context deadline exceeded"}},
+		},
+		{
+			name:           "Fail_ShuttingDown",
+			checks:         map[string]Check{"database": ok},
+			shutDown:       true,
+			expectedReport: Report{Status: StatusShuttingDown},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			h := New(10 * time.Millisecond)
+			for name, check := range tc.checks {
+				h.Add(name, check)
+			}
+			if tc.shutDown {
+				h.ShutDown()
+			}
+
+			report, ready := h.Ready(context.Background())
+			require.Equal(t, tc.expectedReady, ready)
+			require.Equal(t, tc.expectedReport, report)
+		})
+	}
+}
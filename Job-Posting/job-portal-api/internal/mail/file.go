@@ -0,0 +1,45 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File writes every email to a .eml file in a directory instead of sending it, so that the links in them can be
+// followed when running the API locally.
+type File struct {
+	from string
+	dir  string
+}
+
+// NewFile returns a Mailer that writes emails from the given address to dir, which is created when missing.
+func NewFile(from string, dir string) *File {
+	return &File{from: from, dir: dir}
+}
+
+// Send implements Mailer.
+func (f *File) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	body, err := format(f.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(f.dir, 0o700)
+	if err != nil {
+		return err
+	}
+	// The random suffix keeps emails sent in the same instant apart, and file names sort by time
+	suffix := make([]byte, 4)
+	_, err = rand.Read(suffix)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+	return os.WriteFile(filepath.Join(f.dir, name), body, 0o600)
+}
@@ -0,0 +1,86 @@
+// Package mail sends the emails of the API, such as the links to verify an address or reset a password.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"job-portal-api/internal/config"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails. Send must give up once ctx is done.
+type Mailer interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// New returns the Mailer selected by cfg.Transport.
+func New(cfg config.Mail) (Mailer, error) {
+	switch cfg.Transport {
+	case config.MailSMTP:
+		return NewSMTP(cfg.From, cfg.SMTP), nil
+	case config.MailFile:
+		return NewFile(cfg.From, cfg.Dir), nil
+	default:
+		return nil, fmt.Errorf("unknown mail transport %q", cfg.Transport)
+	}
+}
+
+// Memory keeps the messages it is given instead of sending them, for tests.
+type Memory struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// Send implements Mailer.
+func (m *Memory) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages returns the messages sent so far, oldest first.
+func (m *Memory) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
+
+// format renders msg as an RFC 5322 message from the given sender. The recipient and subject go in headers, so
+// line breaks in them are rejected rather than letting them add headers of their own.
+func format(from string, msg Message, now time.Time) ([]byte, error) {
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return nil, errors.New("line break in mail header")
+	}
+	_, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("parsing recipient: %w", err)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	// SMTP needs CRLF line endings in the body as well
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return b.Bytes(), nil
+}
@@ -0,0 +1,67 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"job-portal-api/internal/config"
+)
+
+func TestFormat(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	b, err := format("Job Portal <no-reply@jobs.example>", Message{
+		To:      "stym@email.com",
+		Subject: "Vérifiez votre adresse",
+		Body:    "Hello,\nfollow the link.\n",
+	}, now)
+	require.NoError(t, err)
+
+	require.Equal(t, "From: Job Portal <no-reply@jobs.example>\r\n"+
+		"To: stym@email.com\r\n"+
+		"Subject: =?utf-8?q?V=C3=A9rifiez_votre_adresse?=\r\n"+
+		"Date: Tue, 02 Jan 2024 03:04:05 +0000\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/plain; charset=utf-8\r\n"+
+		"Content-Transfer-Encoding: 8bit\r\n"+
+		"\r\n"+
+		"Hello,\r\nfollow the link.\r\n", string(b))
+
+	tt := []Message{
+		{To: "stym@email.com\r\nBcc: all@email.com", Subject: "hi"},
+		{To: "stym@email.com", Subject: "hi\nBcc: all@email.com"},
+		{To: "not an address", Subject: "hi"},
+	}
+	for _, msg := range tt {
+		_, err = format("no-reply@jobs.example", msg, now)
+		require.Error(t, err, "%+v", msg)
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m, err := New(config.Mail{Transport: config.MailFile, From: "no-reply@jobs.example", Dir: dir})
+	require.NoError(t, err)
+
+	msg := Message{To: "stym@email.com", Subject: "Verify your email", Body: "token"}
+	require.NoError(t, m.Send(context.Background(), msg))
+	require.NoError(t, m.Send(context.Background(), msg))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	b, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(b), "From: no-reply@jobs.example\r\nTo: stym@email.com\r\n"))
+}
+
+func TestMemory(t *testing.T) {
+	var m Memory
+	msg := Message{To: "stym@email.com", Subject: "Verify your email", Body: "token"}
+	require.NoError(t, m.Send(context.Background(), msg))
+	require.Equal(t, []Message{msg}, m.Messages())
+}
@@ -0,0 +1,100 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"job-portal-api/internal/config"
+)
+
+// SMTP sends emails through an SMTP server. The connection is upgraded with STARTTLS when the server offers it,
+// and credentials are only ever sent over TLS.
+type SMTP struct {
+	from string
+	cfg  config.SMTP
+}
+
+// NewSMTP returns a Mailer that sends emails from the given address through the server described by cfg.
+func NewSMTP(from string, cfg config.SMTP) *SMTP {
+	return &SMTP{from: from, cfg: cfg}
+}
+
+// Send implements Mailer.
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	body, err := format(s.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	from, err := mail.ParseAddress(s.from)
+	if err != nil {
+		return fmt.Errorf("parsing sender: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("parsing recipient: %w", err)
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("connecting to smtp server: %w", err)
+	}
+	// net/smtp doesn't take a context, the deadline of the connection bounds the whole exchange instead
+	if deadline, ok := ctx.Deadline(); ok {
+		err = conn.SetDeadline(deadline)
+		if err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("starting smtp session: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		err = c.StartTLS(&tls.Config{ServerName: s.cfg.Host})
+		if err != nil {
+			return fmt.Errorf("starting tls: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		// PlainAuth refuses to send the password over a connection that isn't encrypted
+		err = c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host))
+		if err != nil {
+			return fmt.Errorf("authenticating to smtp server: %w", err)
+		}
+	}
+
+	err = c.Mail(from.Address)
+	if err != nil {
+		return err
+	}
+	err = c.Rcpt(to.Address)
+	if err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	if err != nil {
+		return err
+	}
+	err = w.Close()
+	if err != nil {
+		return err
+	}
+	return c.Quit()
+}
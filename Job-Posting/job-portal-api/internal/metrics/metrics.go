@@ -0,0 +1,98 @@
+// Package metrics collects the Prometheus metrics of the API and serves them in the Prometheus text format.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "job_portal"
+
+// Registry holds every metric of the API. It is separate from the default Prometheus registry so that only the
+// metrics registered here are served.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to handle HTTP requests, by method, route and status code.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 15},
+	}, []string{"method", "route", "status"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts, by result: success or failure.",
+	}, []string{"result"})
+
+	jobsPosted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "jobs_posted_total",
+		Help:      "Jobs posted.",
+	})
+
+	companiesCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "companies_created_total",
+		Help:      "Companies created.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, logins, jobsPosted, companiesCreated,
+	)
+}
+
+// RegisterDB adds the connection pool statistics of db, as reported by sql.DB.Stats, to the registry.
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the metrics in the registry.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveRequest records a handled HTTP request. route is the route pattern, such as /api/jobs/:jobID, rather than
+// the request path, so that the number of series doesn't grow with the number of ids.
+func ObserveRequest(method string, route string, status int, d time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, code).Inc()
+	httpDuration.WithLabelValues(method, route, code).Observe(d.Seconds())
+}
+
+// LoginSucceeded counts a login with valid credentials.
+func LoginSucceeded() {
+	logins.WithLabelValues("success").Inc()
+}
+
+// LoginFailed counts a login with wrong credentials.
+func LoginFailed() {
+	logins.WithLabelValues("failure").Inc()
+}
+
+// JobPosted counts a posted job.
+func JobPosted() {
+	jobsPosted.Inc()
+}
+
+// CompanyCreated counts a created company.
+func CompanyCreated() {
+	companiesCreated.Inc()
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	_ "github.com/glebarez/go-sqlite"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveRequest(t *testing.T) {
+	ObserveRequest(http.MethodGet, "/api/jobs/:jobID", http.StatusOK, 30*time.Millisecond)
+	ObserveRequest(http.MethodGet, "/api/jobs/:jobID", http.StatusOK, 2*time.Second)
+	ObserveRequest(http.MethodGet, "/api/jobs/:jobID", http.StatusNotFound, time.Millisecond)
+
+	require.Equal(t, 2.0, testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "/api/jobs/:jobID", "200")))
+	require.Equal(t, 1.0, testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "/api/jobs/:jobID", "404")))
+	require.Equal(t, 2, testutil.CollectAndCount(httpDuration))
+}
+
+func TestHandler(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, RegisterDB(db, "sqlite"))
+
+	LoginFailed()
+	JobPosted()
+	CompanyCreated()
+
+	resp := httptest.NewRecorder()
+	Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, resp.Code)
+	body := resp.Body.String()
+	require.Contains(t, body, `job_portal_logins_total{result="failure"} 1`)
+	require.Contains(t, body, "job_portal_jobs_posted_total 1")
+	require.Contains(t, body, "job_portal_companies_created_total 1")
+	require.Contains(t, body, `go_sql_open_connections{db_name="sqlite"}`)
+	require.Contains(t, body, "go_goroutines")
+}
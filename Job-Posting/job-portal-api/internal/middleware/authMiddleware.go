@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"job-portal-api/internal/auth"
+	"job-portal-api/internal/problem"
+	"job-portal-api/internal/ratelimit"
 	"net/http"
+	"strconv"
 
 	"strings"
 
@@ -13,7 +16,8 @@ import (
 )
 
 // Authenticate is a method that defines a Middleware function for gin HTTP framework
-func (m *Mid) Authenticate(next gin.HandlerFunc) gin.HandlerFunc {
+// Besides access tokens it accepts API keys, but only on routes that list scopes, and only keys granted one of them.
+func (m *Mid) Authenticate(next gin.HandlerFunc, scopes ...string) gin.HandlerFunc {
 	// This middleware function is returned
 	return func(c *gin.Context) {
 		// We get the current request context
@@ -30,7 +34,7 @@ func (m *Mid) Authenticate(next gin.HandlerFunc) gin.HandlerFunc {
 			log.Error().Msg("trace id not present in the context")
 
 			// Sending error response using gin context
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": http.StatusText(http.StatusInternalServerError)})
+			problem.Abort(c, http.StatusInternalServerError, "", "")
 			return
 		}
 
@@ -45,16 +49,42 @@ func (m *Mid) Authenticate(next gin.HandlerFunc) gin.HandlerFunc {
 			// If the header format doesn't match required format, log and send an error
 			err := errors.New("expected authorization header format: Bearer <token>")
 			log.Error().Err(err).Str("Trace Id", traceId).Send()
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			problem.Abort(c, http.StatusUnauthorized, traceId, err.Error())
 			return
 		}
 
-		// ValidateToken presumably checks the token for validity and returns claims if it's valid
-		claims, err :=m.a.ValidateToken(parts[1])
+		// Integrations send an API key in place of the token. Routes that list no scopes are off limits to them
+		var claims auth.Claims
+		var err error
+		if strings.HasPrefix(parts[1], auth.APIKeyPrefix) {
+			if len(scopes) == 0 || m.apiKeys == nil {
+				log.Error().Str("Trace Id", traceId).Msg("api key used on a route that doesn't accept them")
+				problem.Abort(c, http.StatusForbidden, traceId, "api keys can't be used on this route")
+				return
+			}
+			claims, err = m.apiKeys.AuthenticateAPIKey(ctx, parts[1])
+		} else {
+			// ValidateToken presumably checks the token for validity and returns claims if it's valid
+			claims, err = m.a.ValidateToken(ctx, parts[1])
+		}
+		// The revocation check runs a query, which gives up when the request deadline passes
+		if errors.Is(err, context.DeadlineExceeded) {
+			log.Error().Err(err).Str("Trace Id", traceId).Msg("request timed out")
+			problem.Abort(c, http.StatusGatewayTimeout, traceId, "")
+			return
+		}
 		// If there is an error, log it and return an Unauthorized error message
 		if err != nil {
 			log.Error().Err(err).Str("Trace Id", traceId).Send()
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": http.StatusText(http.StatusUnauthorized)})
+			problem.Abort(c, http.StatusUnauthorized, traceId, "")
+			return
+		}
+
+		// A key must have been granted one of the scopes of the route
+		if claims.APIKeyID != 0 && !claims.HasScope(scopes...) {
+			log.Error().Str("Trace Id", traceId).Uint("API Key", claims.APIKeyID).
+				Strs("Scopes", claims.Scopes).Msg("api key lacks the scope of this route")
+			problem.Abort(c, http.StatusForbidden, traceId, "the api key needs the scope "+strings.Join(scopes, " or "))
 			return
 		}
 
@@ -65,6 +95,16 @@ func (m *Mid) Authenticate(next gin.HandlerFunc) gin.HandlerFunc {
 		req := c.Request.WithContext(ctx)
 		c.Request = req
 
+		// Now that the user is known, limit their requests whichever IP they come from. Every API key gets its own
+		// bucket, so that an integration doesn't use up the requests of the people working on the company.
+		limitID := claims.Subject
+		if claims.APIKeyID != 0 {
+			limitID = "apikey:" + strconv.FormatUint(uint64(claims.APIKeyID), 10)
+		}
+		if !m.limit(c, ratelimit.ScopeUser, limitID) {
+			return
+		}
+
 		// Proceed to the next middleware or handler function
 		next(c)
 	}
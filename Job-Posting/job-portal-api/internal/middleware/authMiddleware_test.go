@@ -0,0 +1,133 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"job-portal-api/internal/auth"
+	"job-portal-api/internal/problem"
+)
+
+// apiKeys authenticates the keys it maps to claims.
+type apiKeys map[string]auth.Claims
+
+func (k apiKeys) AuthenticateAPIKey(ctx context.Context, key string) (auth.Claims, error) {
+	claims, ok := k[key]
+	if !ok {
+		return auth.Claims{}, errors.New("invalid api key")
+	}
+	return claims, nil
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	a, err := auth.NewAuth(privateKey, &privateKey.PublicKey)
+	require.NoError(t, err)
+	token, err := a.GenerateToken(auth.NewClaims("1", "recruiter"))
+	require.NoError(t, err)
+
+	reader := auth.NewClaims("1", "recruiter")
+	reader.APIKeyID, reader.CompanyID, reader.Scopes = 1, 3, []string{"jobs:read"}
+	writer := auth.NewClaims("1", "recruiter")
+	writer.APIKeyID, writer.CompanyID, writer.Scopes = 2, 3, []string{"jobs:read", "jobs:write"}
+
+	m, err := NewMid(a)
+	require.NoError(t, err)
+	m.SetAPIKeys(apiKeys{"jpk_reader": reader, "jpk_writer": writer})
+
+	router := gin.New()
+	router.Use(m.Log())
+	whoami := func(c *gin.Context) {
+		claims := c.Request.Context().Value(auth.Key).(auth.Claims)
+		c.JSON(http.StatusOK, gin.H{"subject": claims.Subject, "company_id": claims.CompanyID})
+	}
+	router.GET("/api/logout", m.Authenticate(whoami))
+	router.POST("/api/jobs", m.Authenticate(whoami, "jobs:write"))
+
+	testCases := []struct {
+		name           string
+		method         string
+		url            string
+		credentials    string
+		expectedStatus int
+		expectedBody   string
+		expectedDetail string
+	}{
+		{
+			name:           "Token_OnRouteWithoutScopes",
+			method:         http.MethodGet,
+			url:            "/api/logout",
+			credentials:    token,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"company_id":0,"subject":"1"}`,
+		},
+		{
+			name:           "Token_OnScopedRoute",
+			method:         http.MethodPost,
+			url:            "/api/jobs",
+			credentials:    token,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"company_id":0,"subject":"1"}`,
+		},
+		{
+			name:           "APIKey_OK",
+			method:         http.MethodPost,
+			url:            "/api/jobs",
+			credentials:    "jpk_writer",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"company_id":3,"subject":"1"}`,
+		},
+		{
+			name:           "APIKey_Fail_RouteWithoutScopes",
+			method:         http.MethodGet,
+			url:            "/api/logout",
+			credentials:    "jpk_writer",
+			expectedStatus: http.StatusForbidden,
+			expectedDetail: "api keys can't be used on this route",
+		},
+		{
+			name:           "APIKey_Fail_MissingScope",
+			method:         http.MethodPost,
+			url:            "/api/jobs",
+			credentials:    "jpk_reader",
+			expectedStatus: http.StatusForbidden,
+			expectedDetail: "the api key needs the scope jobs:write",
+		},
+		{
+			name:           "APIKey_Fail_Unknown",
+			method:         http.MethodPost,
+			url:            "/api/jobs",
+			credentials:    "jpk_revoked",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+			req.Header.Set("Authorization", "Bearer "+tc.credentials)
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.expectedStatus, resp.Code)
+			if tc.expectedStatus == http.StatusOK {
+				require.Equal(t, tc.expectedBody, resp.Body.String())
+				return
+			}
+			var p problem.Details
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &p))
+			require.Equal(t, tc.expectedDetail, p.Detail)
+		})
+	}
+}
@@ -3,23 +3,76 @@ package middlewares
 
 import (
 	"context"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type key string
 
 const TraceIdKey key = "1"
 
+// Headers that carry the ids of a request. A request id set by the caller is echoed back as is, the trace id is the
+// one written to the logs and returned in problem details.
+const (
+	RequestIdHeader = "X-Request-ID"
+	TraceIdHeader   = "X-Trace-ID"
+)
+
+const tracerName = "job-portal-api/internal/middleware"
+
+// Log starts the server span of the request and logs its start and end. The span continues the trace of the
+// caller when the request carries a W3C traceparent header. The id of the trace is used as the trace id of the
+// request; when no trace is recorded the X-Request-ID header is used instead, and a new UUID when that is missing.
 func (m *Mid) Log() gin.HandlerFunc {
 	return func(c *gin.Context) {
 
-		// Generate a new unique identifier (UUID)
-		traceId := uuid.NewString()
+		// Continue the trace of the caller, if any, and start the span covering the whole request
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+		ctx, span := otel.Tracer(tracerName).Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+			))
+		defer func() {
+			status := c.Writer.Status()
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			span.End()
+		}()
 
-		// Fetch the current context from the gin context
-		ctx := c.Request.Context()
+		requestId := c.GetHeader(RequestIdHeader)
+		if !validRequestId(requestId) {
+			requestId = ""
+		}
+		traceId := requestId
+		if sc := span.SpanContext(); sc.HasTraceID() {
+			traceId = sc.TraceID().String()
+		}
+		if traceId == "" {
+			traceId = uuid.NewString()
+		}
+		if requestId == "" {
+			requestId = traceId
+		}
+		c.Header(RequestIdHeader, requestId)
+		c.Header(TraceIdHeader, traceId)
 
 		// Add the trace id in context so it can be used by upcoming processes in this request's lifecycle
 		ctx = context.WithValue(ctx, TraceIdKey, traceId)
@@ -35,7 +88,7 @@ func (m *Mid) Log() gin.HandlerFunc {
 		// that carries our trace ID in its context.
 		c.Request = req
 
-		log.Info().Str("Trace Id", traceId).Str("Method", c.Request.Method).
+		log.Info().Str("Trace Id", traceId).Str("Request Id", requestId).Str("Method", c.Request.Method).
 			Str("URL Path", c.Request.URL.Path).Msg("request started")
 		// After the request is processed by the next handler, logs the info again with status code
 		defer log.Info().Str("Trace Id", traceId).Str("Method", c.Request.Method).
@@ -46,3 +99,17 @@ func (m *Mid) Log() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// validRequestId reports whether id can be used as a request id: at most 128 printable ASCII characters, so that a
+// caller can't inject line breaks or huge values into the logs and response headers.
+func validRequestId(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := tracetest.NewSpanRecorder()
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	})
+
+	const (
+		incomingTraceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+		incomingSpanId  = "00f067aa0ba902b7"
+	)
+
+	tt := []struct {
+		name              string
+		headers           map[string]string
+		expectedTraceId   string
+		expectedRequestId string
+	}{
+		{
+			name:              "Traceparent",
+			headers:           map[string]string{"traceparent": "00-" + incomingTraceId + "-" + incomingSpanId + "-01"},
+			expectedTraceId:   incomingTraceId,
+			expectedRequestId: incomingTraceId,
+		},
+		{
+			name: "TraceparentAndRequestId",
+			headers: map[string]string{
+				"traceparent":   "00-" + incomingTraceId + "-" + incomingSpanId + "-01",
+				RequestIdHeader: "gateway-42",
+			},
+			expectedTraceId:   incomingTraceId,
+			expectedRequestId: "gateway-42",
+		},
+		{
+			name:    "NewTrace",
+			headers: map[string]string{RequestIdHeader: "gateway-42"},
+			// The trace id is generated, only the request id is known
+			expectedRequestId: "gateway-42",
+		},
+		{
+			name:    "InvalidRequestId",
+			headers: map[string]string{RequestIdHeader: strings.Repeat("x", 200)},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var ctxTraceId string
+			m := Mid{}
+			router := gin.New()
+			router.Use(m.Log())
+			router.GET("/api/jobs/:jobID", func(c *gin.Context) {
+				ctxTraceId, _ = c.Request.Context().Value(TraceIdKey).(string)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/jobs/1", nil)
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			span := rec.Ended()[len(rec.Ended())-1]
+			require.Equal(t, "GET /api/jobs/:jobID", span.Name())
+			traceId := span.SpanContext().TraceID().String()
+			if tc.expectedTraceId != "" {
+				require.Equal(t, tc.expectedTraceId, traceId)
+				require.Equal(t, incomingSpanId, span.Parent().SpanID().String())
+			}
+			require.Equal(t, traceId, ctxTraceId)
+			require.Equal(t, traceId, resp.Header().Get(TraceIdHeader))
+
+			expectedRequestId := tc.expectedRequestId
+			if expectedRequestId == "" {
+				expectedRequestId = traceId
+			}
+			require.Equal(t, expectedRequestId, resp.Header().Get(RequestIdHeader))
+		})
+	}
+}
+
+func TestLogWithoutTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// Without a tracer provider no trace id is generated, and the request id of the caller is used instead
+	prevTP := otel.GetTracerProvider()
+	otel.SetTracerProvider(noop.NewTracerProvider())
+	t.Cleanup(func() { otel.SetTracerProvider(prevTP) })
+
+	m := Mid{}
+	router := gin.New()
+	router.Use(m.Log())
+	var ctxTraceId string
+	router.GET("/ping", func(c *gin.Context) {
+		ctxTraceId, _ = c.Request.Context().Value(TraceIdKey).(string)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set(RequestIdHeader, "gateway-42")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, "gateway-42", ctxTraceId)
+	require.Equal(t, "gateway-42", resp.Header().Get(TraceIdHeader))
+	require.Equal(t, "gateway-42", resp.Header().Get(RequestIdHeader))
+}
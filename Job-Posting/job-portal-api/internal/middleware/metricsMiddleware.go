@@ -0,0 +1,23 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"job-portal-api/internal/metrics"
+)
+
+// Metrics records the count and duration of every request by method, route and status code. Requests that match
+// no route are recorded under the route "unmatched".
+func (m *Mid) Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -1,8 +1,10 @@
 package middlewares
 
 import (
+	"context"
 	"errors"
 	"job-portal-api/internal/auth"
+	"job-portal-api/internal/ratelimit"
 )
 
 // Mid is a structure that holds an authenticated session.
@@ -12,6 +14,21 @@ type Mid struct {
 	// It's important to note that 'a'
 	//is a pointer because we want to refer to the original 'Auth' object and not a COPY of it.
 	a *auth.Auth
+	// limiter limits the requests of every client, it is nil when rate limiting is disabled.
+	limiter *ratelimit.Limiter
+	// apiKeys checks the API keys of integrations, they are refused when it is nil.
+	apiKeys APIKeyAuthenticator
+}
+
+// APIKeyAuthenticator checks an API key and returns the claims the request is made with, which carry the company
+// and the scopes of the key.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (auth.Claims, error)
+}
+
+// SetAPIKeys makes Authenticate accept the API keys k knows, on the routes that declare scopes.
+func (m *Mid) SetAPIKeys(k APIKeyAuthenticator) {
+	m.apiKeys = k
 }
 
 // NewMid is a function which takes an 'Auth' object pointer
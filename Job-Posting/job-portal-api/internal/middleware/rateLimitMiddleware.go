@@ -0,0 +1,76 @@
+package middlewares
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"job-portal-api/internal/problem"
+	"job-portal-api/internal/ratelimit"
+)
+
+// Headers describing the rate limit of the client, as drafted by the IETF httpapi working group.
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RateLimitPolicyHeader    = "RateLimit-Policy"
+)
+
+// SetRateLimiter makes RateLimit and Authenticate limit requests with l. Without a limiter requests aren't limited.
+func (m *Mid) SetRateLimiter(l *ratelimit.Limiter) {
+	m.limiter = l
+}
+
+// RateLimit limits the requests of every client IP. Authenticate limits the requests of every user on top of it,
+// so that users behind a shared IP are not all refused at once because of one of them.
+func (m *Mid) RateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if m.limit(c, ratelimit.ScopeIP, c.ClientIP()) {
+			c.Next()
+		}
+	}
+}
+
+// limit takes a token from the bucket of id and describes the limit in the response headers. When there is no
+// token left it aborts the request with a 429 and reports false.
+func (m *Mid) limit(c *gin.Context, scope, id string) bool {
+	if m.limiter == nil {
+		return true
+	}
+	r := m.limiter.Allow(scope, c.FullPath(), id)
+	setRateLimitHeaders(c, r)
+	if r.Allowed {
+		return true
+	}
+
+	traceId, _ := c.Request.Context().Value(TraceIdKey).(string)
+	retry := seconds(r.RetryAfter)
+	log.Warn().Str("Trace Id", traceId).Str("scope", scope).Str("id", id).Str("route", c.FullPath()).
+		Msg("rate limit exceeded")
+	c.Header("Retry-After", strconv.Itoa(retry))
+	problem.Abort(c, http.StatusTooManyRequests, traceId, fmt.Sprintf("rate limit exceeded, retry in %d seconds", retry))
+	return false
+}
+
+// setRateLimitHeaders describes the limit closest to being reached, when both the IP and the user are limited the
+// headers of the first one are replaced only if the second leaves fewer requests.
+func setRateLimitHeaders(c *gin.Context, r ratelimit.Result) {
+	h := c.Writer.Header()
+	if prev, err := strconv.Atoi(h.Get(RateLimitRemainingHeader)); err == nil && prev <= r.Remaining {
+		return
+	}
+	h.Set(RateLimitLimitHeader, strconv.Itoa(r.Limit.Requests))
+	h.Set(RateLimitRemainingHeader, strconv.Itoa(r.Remaining))
+	h.Set(RateLimitResetHeader, strconv.Itoa(seconds(r.Reset)))
+	h.Set(RateLimitPolicyHeader, fmt.Sprintf("%d;w=%d", r.Limit.Requests, seconds(r.Limit.Per.Duration)))
+}
+
+// seconds rounds d up to whole seconds, a client waiting that long is never early.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,92 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"job-portal-api/internal/config"
+	"job-portal-api/internal/problem"
+	"job-portal-api/internal/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := Mid{}
+	m.SetRateLimiter(ratelimit.New(config.RateLimit{
+		Enabled: true,
+		Default: config.Limit{Requests: 5, Per: config.Duration{Duration: time.Minute}},
+		Routes: map[string]config.Limit{
+			"/api/login": {Requests: 2, Per: config.Duration{Duration: time.Minute}},
+		},
+	}))
+	router := gin.New()
+	router.Use(m.Log(), m.RateLimit())
+	router.POST("/api/login", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/jobs", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	do := func(method, path, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := do(http.MethodPost, "/api/login", "10.0.0.1")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Equal(t, "2", resp.Header().Get(RateLimitLimitHeader))
+	require.Equal(t, "1", resp.Header().Get(RateLimitRemainingHeader))
+	require.Equal(t, "30", resp.Header().Get(RateLimitResetHeader))
+	require.Equal(t, "2;w=60", resp.Header().Get(RateLimitPolicyHeader))
+
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/api/login", "10.0.0.1").Code)
+
+	resp = do(http.MethodPost, "/api/login", "10.0.0.1")
+	require.Equal(t, http.StatusTooManyRequests, resp.Code)
+	require.Equal(t, "30", resp.Header().Get("Retry-After"))
+	require.Equal(t, "0", resp.Header().Get(RateLimitRemainingHeader))
+	require.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+	var p problem.Details
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &p))
+	require.Equal(t, "rate limit exceeded, retry in 30 seconds", p.Detail)
+	require.NotEmpty(t, p.TraceID)
+
+	// The other routes and the other clients are still allowed
+	resp = do(http.MethodGet, "/api/jobs", "10.0.0.1")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Equal(t, "5", resp.Header().Get(RateLimitLimitHeader))
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/api/login", "10.0.0.2").Code)
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := Mid{}
+	router := gin.New()
+	router.Use(m.Log(), m.RateLimit())
+	router.GET("/api/jobs", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/jobs", nil))
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Empty(t, resp.Header().Get(RateLimitLimitHeader))
+}
+
+func TestSetRateLimitHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limit := config.Limit{Requests: 10, Per: config.Duration{Duration: time.Minute}}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	setRateLimitHeaders(c, ratelimit.Result{Limit: limit, Remaining: 3})
+	// A user limit with more requests left doesn't hide the IP limit
+	setRateLimitHeaders(c, ratelimit.Result{Limit: limit, Remaining: 8})
+	require.Equal(t, "3", c.Writer.Header().Get(RateLimitRemainingHeader))
+	setRateLimitHeaders(c, ratelimit.Result{Limit: limit, Remaining: 1})
+	require.Equal(t, "1", c.Writer.Header().Get(RateLimitRemainingHeader))
+}
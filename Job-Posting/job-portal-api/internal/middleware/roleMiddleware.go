@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"job-portal-api/internal/auth"
+	"job-portal-api/internal/problem"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// Authorize wraps a handler so that it only runs when the authenticated user holds one of the given roles.
+// It must be used inside Authenticate, which puts the claims into the request context.
+func (m *Mid) Authorize(next gin.HandlerFunc, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		traceId, ok := ctx.Value(TraceIdKey).(string)
+		if !ok {
+			log.Error().Msg("trace id not present in the context")
+			problem.Abort(c, http.StatusInternalServerError, "", "")
+			return
+		}
+
+		// Authenticate should have already stored the claims, if not the route is wired up wrong
+		claims, ok := ctx.Value(auth.Key).(auth.Claims)
+		if !ok {
+			log.Error().Str("Trace Id", traceId).Msg("claims not present in the context")
+			problem.Abort(c, http.StatusUnauthorized, traceId, "")
+			return
+		}
+
+		// Reject the request if the user holds none of the allowed roles
+		if !claims.HasRole(roles...) {
+			log.Error().Str("Trace Id", traceId).Str("Subject", claims.Subject).
+				Strs("Roles", claims.Roles).Msg("user is not allowed to access this route")
+			problem.Abort(c, http.StatusForbidden, traceId, "")
+			return
+		}
+
+		next(c)
+	}
+}
@@ -0,0 +1,20 @@
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout wraps a handler so that its request context is cancelled once d has passed. Database calls made with
+// that context give up at the deadline, and the handler answers with 504 Gateway Timeout.
+func (m *Mid) Timeout(next gin.HandlerFunc, d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		next(c)
+	}
+}
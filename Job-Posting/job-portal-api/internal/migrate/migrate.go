@@ -0,0 +1,300 @@
+// Package migrate applies the versioned SQL migrations that define the database schema.
+//
+// Each migration is a pair of files named <version>_<name>.up.sql and <version>_<name>.down.sql. Applied versions
+// are recorded in the schema_migrations table. There is one set of migrations per database driver, with the
+// same versions in each. On Postgres runs hold an advisory lock, so replicas starting at the same time apply every
+// migration exactly once. SQLite databases are meant for a single process and are not locked.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"job-portal-api/internal/config"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations
+var migrations embed.FS
+
+// lockKey identifies the advisory lock taken while migrating. Any constant works as long as nothing else in the
+// database uses it.
+const lockKey = 4_815_162_342
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one schema change and the SQL to undo it.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status tells whether a migration has been applied. AppliedAt is nil for pending migrations.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// queryer is a connection or a pool of them.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+type Migrator struct {
+	db         *sql.DB
+	driver     string
+	migrations []Migration
+}
+
+// New returns a Migrator for the migrations embedded in the binary for the driver, config.DriverPostgres or
+// config.DriverSQLite.
+func New(db *sql.DB, driver string) (*Migrator, error) {
+	sub, err := fs.Sub(migrations, path.Join("migrations", driver))
+	if err != nil {
+		return nil, err
+	}
+	return NewFromFS(db, driver, sub)
+}
+
+// NewFromFS returns a Migrator for the migrations in the root of fsys.
+func NewFromFS(db *sql.DB, driver string, fsys fs.FS) (*Migrator, error) {
+	if db == nil {
+		return nil, errors.New("db cannot be null")
+	}
+	if driver != config.DriverPostgres && driver != config.DriverSQLite {
+		return nil, fmt.Errorf("no migrations for database driver %q", driver)
+	}
+	ms, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, driver: driver, migrations: ms}, nil
+}
+
+// Migrations returns every known migration ordered by version.
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Up applies every pending migration in order and returns the ones it applied. Each migration runs in its own
+// transaction; if one fails the ones before it stay applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if _, ok := done[mig.Version]; ok {
+				continue
+			}
+			err := run(ctx, conn, mig.Up,
+				`INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+				mig.Version, mig.Name, time.Now().UTC())
+			if err != nil {
+				return fmt.Errorf("applying migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the n most recently applied migrations and returns them, newest first.
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < n; i-- {
+			mig := m.migrations[i]
+			if _, ok := done[mig.Version]; !ok {
+				continue
+			}
+			err := run(ctx, conn, mig.Down, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+			if err != nil {
+				return fmt.Errorf("reverting migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists every known migration with the time it was applied. It only reads schema_migrations, without
+// taking the migration lock, so readiness probes can call it while another replica migrates. The migrations of a
+// run in progress are pending until it commits them, and all of them are before the first run.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	exists, err := m.hasVersionTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	done := map[int64]time.Time{}
+	if exists {
+		done, err = appliedVersions(ctx, m.db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Migration: mig}
+		if at, ok := done[mig.Version]; ok {
+			s.AppliedAt = &at
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// hasVersionTable reports whether the schema_migrations table was created, which happens on the first run.
+func (m *Migrator) hasVersionTable(ctx context.Context) (bool, error) {
+	query := `SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`
+	if m.driver == config.DriverPostgres {
+		query = `SELECT count(*) FROM pg_tables WHERE schemaname = current_schema() AND tablename = 'schema_migrations'`
+	}
+	var n int
+	err := m.db.QueryRowContext(ctx, query).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("looking for schema_migrations: %w", err)
+	}
+	return n > 0, nil
+}
+
+// locked runs fn on a single connection while holding the migration lock, for the runs that change the schema. The
+// schema_migrations table is created first if needed.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	timestampType := "datetime"
+	if m.driver == config.DriverPostgres {
+		timestampType = "timestamptz"
+		_, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey)
+		if err != nil {
+			return fmt.Errorf("taking migration lock: %w", err)
+		}
+		defer func() {
+			// Use a fresh context, the lock has to be released even if ctx was cancelled.
+			_, _ = conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+		}()
+	}
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    bigint PRIMARY KEY,
+		name       text NOT NULL,
+		applied_at `+timestampType+` NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+// run executes the migration SQL and the bookkeeping statement in one transaction.
+func run(ctx context.Context, conn *sql.Conn, migration string, record string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, migration)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, record, args...)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func appliedVersions(ctx context.Context, q queryer) (map[int64]time.Time, error) {
+	rows, err := q.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		err := rows.Scan(&version, &at)
+		if err != nil {
+			return nil, err
+		}
+		done[version] = at
+	}
+	return done, rows.Err()
+}
+
+// load reads the migrations in the root of fsys. Every version must have both an up and a down file.
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+		parts := fileName.FindStringSubmatch(e.Name())
+		if parts == nil {
+			return nil, fmt.Errorf("migration file %s: name must look like 0001_name.up.sql", e.Name())
+		}
+		version, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration file %s: %w", e.Name(), err)
+		}
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: parts[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != parts[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, mig.Name, parts[2])
+		}
+		if parts[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	ms := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", mig.Version, mig.Name)
+		}
+		ms = append(ms, *mig)
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Version < ms[j].Version })
+	return ms, nil
+}
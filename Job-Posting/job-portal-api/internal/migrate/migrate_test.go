@@ -0,0 +1,132 @@
+package migrate
+
+import (
+	"context"
+	"io/fs"
+	"job-portal-api/internal/config"
+	"job-portal-api/internal/database"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	var versions [][]string
+	for _, driver := range []string{config.DriverPostgres, config.DriverSQLite} {
+		sub, err := fs.Sub(migrations, "migrations/"+driver)
+		require.NoError(t, err)
+
+		ms, err := load(sub)
+		require.NoError(t, err)
+		require.NotEmpty(t, ms)
+
+		var names []string
+		for i, m := range ms {
+			require.Equal(t, int64(i+1), m.Version, "%s migration versions must have no gaps", driver)
+			names = append(names, m.Name)
+		}
+		versions = append(versions, names)
+	}
+	require.Equal(t, versions[0], versions[1], "every driver must have the same migrations")
+}
+
+func TestMigrateSQLite(t *testing.T) {
+	db, err := database.Open(config.DB{Driver: config.DriverSQLite, DSN: ":memory:"})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	m, err := New(sqlDB, config.DriverSQLite)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Before the first run every migration is pending, and looking doesn't create anything
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, len(m.Migrations()))
+	for _, s := range statuses {
+		require.Nil(t, s.AppliedAt)
+	}
+	require.False(t, db.Migrator().HasTable("schema_migrations"))
+
+	applied, err := m.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, len(m.Migrations()))
+
+	applied, err = m.Up(ctx)
+	require.NoError(t, err)
+	require.Empty(t, applied)
+
+	reverted, err := m.Down(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	require.Equal(t, m.Migrations()[len(m.Migrations())-1].Version, reverted[0].Version)
+
+	statuses, err = m.Status(ctx)
+	require.NoError(t, err)
+	for i, s := range statuses {
+		if i == len(statuses)-1 {
+			require.Nil(t, s.AppliedAt)
+		} else {
+			require.NotNil(t, s.AppliedAt)
+		}
+	}
+
+	reverted, err = m.Down(ctx, len(m.Migrations()))
+	require.NoError(t, err)
+	require.Len(t, reverted, len(m.Migrations())-1)
+	require.False(t, db.Migrator().HasTable("jobs"))
+}
+
+func TestLoad(t *testing.T) {
+	tt := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    []Migration
+		wantErr bool
+	}{
+		{
+			name: "sorted by version",
+			fsys: fstest.MapFS{
+				"0002_b.up.sql":   {Data: []byte("up b")},
+				"0002_b.down.sql": {Data: []byte("down b")},
+				"0001_a.up.sql":   {Data: []byte("up a")},
+				"0001_a.down.sql": {Data: []byte("down a")},
+				"README.md":       {Data: []byte("ignored")},
+			},
+			want: []Migration{
+				{Version: 1, Name: "a", Up: "up a", Down: "down a"},
+				{Version: 2, Name: "b", Up: "up b", Down: "down b"},
+			},
+		},
+		{
+			name:    "missing down",
+			fsys:    fstest.MapFS{"0001_a.up.sql": {Data: []byte("up a")}},
+			wantErr: true,
+		},
+		{
+			name: "conflicting names",
+			fsys: fstest.MapFS{
+				"0001_a.up.sql":   {Data: []byte("up a")},
+				"0001_b.down.sql": {Data: []byte("down b")},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "bad file name",
+			fsys:    fstest.MapFS{"init.sql": {Data: []byte("up")}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, err := load(tc.fsys)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, ms)
+		})
+	}
+}
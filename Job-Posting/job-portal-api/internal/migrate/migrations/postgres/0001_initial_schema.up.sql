@@ -0,0 +1,99 @@
+-- Schema as it was created by GORM AutoMigrate. IF NOT EXISTS lets databases that were set up by AutoMigrate
+-- adopt the migrations without changes.
+
+CREATE TABLE IF NOT EXISTS users (
+    id            bigserial PRIMARY KEY,
+    created_at    timestamptz,
+    updated_at    timestamptz,
+    deleted_at    timestamptz,
+    name          text NOT NULL UNIQUE,
+    email         text,
+    password_hash text,
+    role          text NOT NULL DEFAULT 'candidate'
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS companies (
+    id           bigserial PRIMARY KEY,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz,
+    company_name text,
+    founded_year bigint,
+    location     text,
+    user_id      bigint,
+    address      text
+);
+CREATE INDEX IF NOT EXISTS idx_companies_deleted_at ON companies (deleted_at);
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id                   bigserial PRIMARY KEY,
+    created_at           timestamptz,
+    updated_at           timestamptz,
+    deleted_at           timestamptz,
+    title                text,
+    description          text,
+    company_id           bigint CONSTRAINT fk_companies_jobs REFERENCES companies (id),
+    location             text,
+    work_mode            text,
+    employment_type      text,
+    seniority            text,
+    salary_min           bigint,
+    salary_max           bigint,
+    salary_currency      varchar(3),
+    skills               text,
+    application_deadline timestamptz,
+    status               text NOT NULL DEFAULT 'draft',
+    published_at         timestamptz,
+    expires_at           timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_jobs_deleted_at ON jobs (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs (status);
+CREATE INDEX IF NOT EXISTS idx_jobs_expires_at ON jobs (expires_at);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id    bigint NOT NULL,
+    token_hash text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_deleted_at ON refresh_tokens (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti        text PRIMARY KEY,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+
+CREATE TABLE IF NOT EXISTS applications (
+    id           bigserial PRIMARY KEY,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz,
+    job_id       bigint NOT NULL CONSTRAINT fk_applications_job REFERENCES jobs (id),
+    user_id      bigint NOT NULL CONSTRAINT fk_applications_user REFERENCES users (id),
+    cover_letter text,
+    resume_url   text,
+    status       text NOT NULL DEFAULT 'applied'
+);
+CREATE INDEX IF NOT EXISTS idx_applications_deleted_at ON applications (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_applications_user_id ON applications (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_application_job_user ON applications (job_id, user_id);
+
+CREATE TABLE IF NOT EXISTS application_status_changes (
+    id             bigserial PRIMARY KEY,
+    application_id bigint NOT NULL,
+    from_status    text,
+    to_status      text NOT NULL,
+    changed_by     bigint NOT NULL,
+    note           text,
+    created_at     timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_application_status_changes_application_id ON application_status_changes (application_id);
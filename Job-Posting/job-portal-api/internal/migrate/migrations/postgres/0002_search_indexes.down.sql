@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_companies_search;
+DROP INDEX IF EXISTS idx_jobs_search;
@@ -0,0 +1,8 @@
+-- Expression indexes backing the full-text search in repository.SearchJobs. The expressions must match the ones
+-- used in the queries for Postgres to pick the indexes.
+CREATE INDEX IF NOT EXISTS idx_jobs_search ON jobs USING GIN ((
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')));
+
+CREATE INDEX IF NOT EXISTS idx_companies_search ON companies USING GIN (
+    setweight(to_tsvector('english', coalesce(company_name, '')), 'A'));
@@ -0,0 +1,17 @@
+-- Accounts are activated by following the link emailed on registration. Existing accounts predate verification
+-- and are treated as verified.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at timestamptz;
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
+
+-- Single use tokens emailed to verify an address or reset a password. Only their hash is stored.
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    user_id    bigint NOT NULL,
+    purpose    text NOT NULL,
+    token_hash text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at    timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_id ON user_tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_tokens_token_hash ON user_tokens (token_hash);
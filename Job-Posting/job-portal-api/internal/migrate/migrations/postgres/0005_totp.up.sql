@@ -0,0 +1,16 @@
+-- Two-factor authentication with an authenticator app. The secret is stored when enrolment starts and the login
+-- asks for a code once totp_enabled_at is set. totp_last_step is the time step of the last code accepted, so
+-- that a code can't be used twice.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled_at timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step bigint NOT NULL DEFAULT 0;
+
+-- Single use codes to log in without the authenticator app. Only their hash is stored.
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    user_id    bigint NOT NULL,
+    code_hash  text NOT NULL,
+    used_at    timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id);
@@ -0,0 +1,17 @@
+-- Keys that integrations use instead of logging in. A key acts on one company, within its scopes. Only the hash of
+-- the key is stored, along with its first characters to tell keys apart.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           bigserial PRIMARY KEY,
+    created_at   timestamptz,
+    company_id   bigint NOT NULL,
+    created_by   bigint NOT NULL,
+    name         text NOT NULL,
+    prefix       text NOT NULL,
+    key_hash     text NOT NULL,
+    scopes       text NOT NULL,
+    expires_at   timestamptz,
+    last_used_at timestamptz,
+    revoked_at   timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_company_id ON api_keys (company_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
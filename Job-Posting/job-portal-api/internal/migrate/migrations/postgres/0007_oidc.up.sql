@@ -0,0 +1,5 @@
+-- Single sign-on with an OpenID Connect provider. A user is linked to the account they have at the provider,
+-- identified by the issuer and the subject of its ID tokens, the first time they log in that way.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS oidc_issuer text;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS oidc_subject text;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_oidc ON users (oidc_issuer, oidc_subject);
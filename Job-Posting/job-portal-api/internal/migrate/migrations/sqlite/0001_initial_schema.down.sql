@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS application_status_changes;
+DROP TABLE IF EXISTS applications;
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS companies;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,98 @@
+-- SQLite version of the Postgres schema, for local development and tests.
+
+CREATE TABLE users (
+    id            integer PRIMARY KEY AUTOINCREMENT,
+    created_at    datetime,
+    updated_at    datetime,
+    deleted_at    datetime,
+    name          text NOT NULL UNIQUE,
+    email         text,
+    password_hash text,
+    role          text NOT NULL DEFAULT 'candidate'
+);
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE companies (
+    id           integer PRIMARY KEY AUTOINCREMENT,
+    created_at   datetime,
+    updated_at   datetime,
+    deleted_at   datetime,
+    company_name text,
+    founded_year integer,
+    location     text,
+    user_id      integer,
+    address      text
+);
+CREATE INDEX idx_companies_deleted_at ON companies (deleted_at);
+
+CREATE TABLE jobs (
+    id                   integer PRIMARY KEY AUTOINCREMENT,
+    created_at           datetime,
+    updated_at           datetime,
+    deleted_at           datetime,
+    title                text,
+    description          text,
+    company_id           integer CONSTRAINT fk_companies_jobs REFERENCES companies (id),
+    location             text,
+    work_mode            text,
+    employment_type      text,
+    seniority            text,
+    salary_min           integer,
+    salary_max           integer,
+    salary_currency      varchar(3),
+    skills               text,
+    application_deadline datetime,
+    status               text NOT NULL DEFAULT 'draft',
+    published_at         datetime,
+    expires_at           datetime
+);
+CREATE INDEX idx_jobs_deleted_at ON jobs (deleted_at);
+CREATE INDEX idx_jobs_status ON jobs (status);
+CREATE INDEX idx_jobs_expires_at ON jobs (expires_at);
+
+CREATE TABLE refresh_tokens (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    user_id    integer NOT NULL,
+    token_hash text NOT NULL,
+    expires_at datetime NOT NULL,
+    revoked_at datetime
+);
+CREATE INDEX idx_refresh_tokens_deleted_at ON refresh_tokens (deleted_at);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE UNIQUE INDEX idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+
+CREATE TABLE revoked_tokens (
+    jti        text PRIMARY KEY,
+    expires_at datetime NOT NULL,
+    created_at datetime
+);
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+
+CREATE TABLE applications (
+    id           integer PRIMARY KEY AUTOINCREMENT,
+    created_at   datetime,
+    updated_at   datetime,
+    deleted_at   datetime,
+    job_id       integer NOT NULL CONSTRAINT fk_applications_job REFERENCES jobs (id),
+    user_id      integer NOT NULL CONSTRAINT fk_applications_user REFERENCES users (id),
+    cover_letter text,
+    resume_url   text,
+    status       text NOT NULL DEFAULT 'applied'
+);
+CREATE INDEX idx_applications_deleted_at ON applications (deleted_at);
+CREATE INDEX idx_applications_user_id ON applications (user_id);
+CREATE UNIQUE INDEX idx_application_job_user ON applications (job_id, user_id);
+
+CREATE TABLE application_status_changes (
+    id             integer PRIMARY KEY AUTOINCREMENT,
+    application_id integer NOT NULL,
+    from_status    text,
+    to_status      text NOT NULL,
+    changed_by     integer NOT NULL,
+    note           text,
+    created_at     datetime
+);
+CREATE INDEX idx_application_status_changes_application_id ON application_status_changes (application_id);
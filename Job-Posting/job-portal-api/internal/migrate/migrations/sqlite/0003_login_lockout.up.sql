@@ -0,0 +1,3 @@
+-- Failed logins since the last successful one, and the time the account stays locked until after too many of them.
+ALTER TABLE users ADD COLUMN failed_logins integer NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN locked_until datetime;
@@ -0,0 +1,17 @@
+-- Accounts are activated by following the link emailed on registration. Existing accounts predate verification
+-- and are treated as verified.
+ALTER TABLE users ADD COLUMN email_verified_at datetime;
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
+
+-- Single use tokens emailed to verify an address or reset a password. Only their hash is stored.
+CREATE TABLE user_tokens (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    user_id    integer NOT NULL,
+    purpose    text NOT NULL,
+    token_hash text NOT NULL,
+    expires_at datetime NOT NULL,
+    used_at    datetime
+);
+CREATE INDEX idx_user_tokens_user_id ON user_tokens (user_id);
+CREATE UNIQUE INDEX idx_user_tokens_token_hash ON user_tokens (token_hash);
@@ -0,0 +1,16 @@
+-- Two-factor authentication with an authenticator app. The secret is stored when enrolment starts and the login
+-- asks for a code once totp_enabled_at is set. totp_last_step is the time step of the last code accepted, so
+-- that a code can't be used twice.
+ALTER TABLE users ADD COLUMN totp_secret text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN totp_enabled_at datetime;
+ALTER TABLE users ADD COLUMN totp_last_step integer NOT NULL DEFAULT 0;
+
+-- Single use codes to log in without the authenticator app. Only their hash is stored.
+CREATE TABLE recovery_codes (
+    id         integer PRIMARY KEY AUTOINCREMENT,
+    created_at datetime,
+    user_id    integer NOT NULL,
+    code_hash  text NOT NULL,
+    used_at    datetime
+);
+CREATE INDEX idx_recovery_codes_user_id ON recovery_codes (user_id);
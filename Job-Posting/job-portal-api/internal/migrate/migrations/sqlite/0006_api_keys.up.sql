@@ -0,0 +1,17 @@
+-- Keys that integrations use instead of logging in. A key acts on one company, within its scopes. Only the hash of
+-- the key is stored, along with its first characters to tell keys apart.
+CREATE TABLE api_keys (
+    id           integer PRIMARY KEY AUTOINCREMENT,
+    created_at   datetime,
+    company_id   integer NOT NULL,
+    created_by   integer NOT NULL,
+    name         text NOT NULL,
+    prefix       text NOT NULL,
+    key_hash     text NOT NULL,
+    scopes       text NOT NULL,
+    expires_at   datetime,
+    last_used_at datetime,
+    revoked_at   datetime
+);
+CREATE INDEX idx_api_keys_company_id ON api_keys (company_id);
+CREATE UNIQUE INDEX idx_api_keys_key_hash ON api_keys (key_hash);
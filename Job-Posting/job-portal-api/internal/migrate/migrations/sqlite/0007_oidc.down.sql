@@ -0,0 +1,3 @@
+DROP INDEX idx_users_oidc;
+ALTER TABLE users DROP COLUMN oidc_subject;
+ALTER TABLE users DROP COLUMN oidc_issuer;
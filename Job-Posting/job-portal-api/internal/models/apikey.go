@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Scopes an API key may be granted. A key can only call the routes that accept one of its scopes.
+const (
+	ScopeJobsRead          = "jobs:read"
+	ScopeJobsWrite         = "jobs:write"
+	ScopeApplicationsRead  = "applications:read"
+	ScopeApplicationsWrite = "applications:write"
+)
+
+// APIKey lets an integration, such as an applicant tracking system, act on one company without logging in. Only
+// the SHA-256 hash of the key is stored. Prefix is the start of the key, shown to tell keys apart.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	CompanyID  uint       `gorm:"not null;index" json:"company_id"`
+	CreatedBy  uint       `gorm:"not null" json:"created_by"`
+	Name       string     `gorm:"not null" json:"name"`
+	Prefix     string     `gorm:"not null" json:"prefix"`
+	KeyHash    string     `gorm:"not null;uniqueIndex" json:"-"`
+	Scopes     []string   `gorm:"serializer:json;not null" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// NewAPIKey is the request to create an API key. Without ExpiresAt the key is valid until it is revoked.
+type NewAPIKey struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,oneof=jobs:read jobs:write applications:read applications:write"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// CreatedAPIKey is returned once, when the key is created. Key can't be retrieved afterwards.
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Stages of the hiring pipeline an application moves through.
+const (
+	ApplicationStatusApplied   = "applied"
+	ApplicationStatusScreening = "screening"
+	ApplicationStatusInterview = "interview"
+	ApplicationStatusOffer     = "offer"
+	ApplicationStatusHired     = "hired"
+	ApplicationStatusRejected  = "rejected"
+)
+
+// Application is a candidate's application to a job. A candidate can apply to a job only once.
+type Application struct {
+	gorm.Model
+	JobID       uint   `gorm:"not null;uniqueIndex:idx_application_job_user" json:"job_id"`
+	Job         Job    `json:"-"`
+	UserID      uint   `gorm:"not null;uniqueIndex:idx_application_job_user;index" json:"user_id"`
+	User        User   `json:"-"`
+	CoverLetter string `json:"cover_letter"`
+	ResumeURL   string `json:"resume_url"`
+	Status      string `gorm:"not null;default:applied" json:"status"`
+}
+
+type NewApplication struct {
+	CoverLetter string `json:"cover_letter"`
+	ResumeURL   string `json:"resume_url" validate:"required,httpurl"`
+}
+
+// ApplicationStatusChange records one move of an application through the pipeline: who moved it, when, and from
+// which stage to which. FromStatus is empty for the entry written when the application is submitted. ChangedBy and
+// Note are left out of the timeline the candidate sees.
+type ApplicationStatusChange struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	ApplicationID uint      `gorm:"not null;index" json:"application_id"`
+	FromStatus    string    `json:"from_status"`
+	ToStatus      string    `gorm:"not null" json:"to_status"`
+	ChangedBy     uint      `gorm:"not null" json:"changed_by,omitempty"`
+	Note          string    `json:"note,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type NewStatusChange struct {
+	Status string `json:"status" validate:"required"`
+	Note   string `json:"note"`
+}
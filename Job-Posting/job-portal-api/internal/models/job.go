@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -16,15 +18,137 @@ type Companies struct {
 
 type NewComapanies struct {
 	CompanyName string `json:"company_name" validate:"required"`
-	FoundedYear int    `json:"founded_year" validate:"required,number"`
+	FoundedYear int    `json:"founded_year" validate:"required,founded_year"`
 	Location    string `json:"location" validate:"required"`
 	Address     string `json:"address" validate:"required"`
 	Jobs        []Job  `json:"jobs"`
 }
 
+// UpdateCompany is the request body for changing a company. Only the fields that are present are changed.
+type UpdateCompany struct {
+	CompanyName *string `json:"company_name" validate:"omitempty,min=1"`
+	FoundedYear *int    `json:"founded_year" validate:"omitempty,founded_year"`
+	Location    *string `json:"location" validate:"omitempty,min=1"`
+	Address     *string `json:"address" validate:"omitempty,min=1"`
+}
+
+// Work modes a job can be done in.
+const (
+	WorkModeOnSite = "onsite"
+	WorkModeRemote = "remote"
+	WorkModeHybrid = "hybrid"
+)
+
+// Employment types a job can be offered as.
+const (
+	EmploymentFullTime   = "full_time"
+	EmploymentPartTime   = "part_time"
+	EmploymentContract   = "contract"
+	EmploymentInternship = "internship"
+	EmploymentTemporary  = "temporary"
+)
+
+// Seniority levels a job can be posted for.
+const (
+	SeniorityIntern    = "intern"
+	SeniorityJunior    = "junior"
+	SeniorityMid       = "mid"
+	SenioritySenior    = "senior"
+	SeniorityLead      = "lead"
+	SeniorityPrincipal = "principal"
+)
+
+// Lifecycle states of a job. Jobs are created as drafts and only published jobs that haven't expired are listed.
+const (
+	JobStatusDraft     = "draft"
+	JobStatusPublished = "published"
+	JobStatusClosed    = "closed"
+	JobStatusExpired   = "expired"
+)
+
 type Job struct {
 	gorm.Model
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	CompanyID   uint   `json:"company_id"`
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	CompanyID      uint   `json:"company_id"`
+	Location       string `json:"location"`
+	WorkMode       string `json:"work_mode"`
+	EmploymentType string `json:"employment_type"`
+	Seniority      string `json:"seniority,omitempty"`
+	// Salaries are in whole units of SalaryCurrency, per year.
+	SalaryMin           int        `json:"salary_min,omitempty"`
+	SalaryMax           int        `json:"salary_max,omitempty"`
+	SalaryCurrency      string     `gorm:"size:3" json:"salary_currency,omitempty"`
+	Skills              []string   `gorm:"serializer:json" json:"skills,omitempty"`
+	ApplicationDeadline *time.Time `json:"application_deadline,omitempty"`
+	Status              string     `gorm:"not null;default:draft;index" json:"status"`
+	PublishedAt         *time.Time `json:"published_at,omitempty"`
+	ExpiresAt           *time.Time `gorm:"index" json:"expires_at,omitempty"`
+}
+
+// IsOpen reports whether the job is published and hasn't expired at the given time.
+func (j Job) IsOpen(now time.Time) bool {
+	return j.Status == JobStatusPublished && (j.ExpiresAt == nil || j.ExpiresAt.After(now))
+}
+
+// NewJob is the request body for posting a job. The company is taken from the URL.
+type NewJob struct {
+	Title               string     `json:"title" validate:"required"`
+	Description         string     `json:"description" validate:"required"`
+	Location            string     `json:"location" validate:"required"`
+	WorkMode            string     `json:"work_mode" validate:"required,oneof=onsite remote hybrid"`
+	EmploymentType      string     `json:"employment_type" validate:"required,oneof=full_time part_time contract internship temporary"`
+	Seniority           string     `json:"seniority" validate:"omitempty,oneof=intern junior mid senior lead principal"`
+	SalaryMin           int        `json:"salary_min" validate:"gte=0"`
+	SalaryMax           int        `json:"salary_max" validate:"omitempty,gtefield=SalaryMin"`
+	SalaryCurrency      string     `json:"salary_currency" validate:"required_with=SalaryMin SalaryMax,omitempty,iso4217"`
+	Skills              []string   `json:"skills" validate:"dive,required"`
+	ApplicationDeadline *time.Time `json:"application_deadline"`
+}
+
+// UpdateJob is the request body for changing a job. Only the fields that are present are changed, an empty skills
+// list clears the skills.
+type UpdateJob struct {
+	Title               *string    `json:"title" validate:"omitempty,min=1"`
+	Description         *string    `json:"description" validate:"omitempty,min=1"`
+	Location            *string    `json:"location" validate:"omitempty,min=1"`
+	WorkMode            *string    `json:"work_mode" validate:"omitempty,oneof=onsite remote hybrid"`
+	EmploymentType      *string    `json:"employment_type" validate:"omitempty,oneof=full_time part_time contract internship temporary"`
+	Seniority           *string    `json:"seniority" validate:"omitempty,oneof=intern junior mid senior lead principal"`
+	SalaryMin           *int       `json:"salary_min" validate:"omitempty,gte=0"`
+	SalaryMax           *int       `json:"salary_max" validate:"omitempty,gte=0"`
+	SalaryCurrency      *string    `json:"salary_currency" validate:"omitempty,iso4217"`
+	Skills              []string   `json:"skills" validate:"omitempty,dive,required"`
+	ApplicationDeadline *time.Time `json:"application_deadline"`
+}
+
+// Sort orders supported when listing jobs.
+const (
+	JobSortNewest     = "newest"
+	JobSortOldest     = "oldest"
+	JobSortSalaryDesc = "salary_desc"
+	JobSortSalaryAsc  = "salary_asc"
+)
+
+// JobFilter holds the query parameters accepted when listing jobs. Zero values mean "don't filter".
+type JobFilter struct {
+	Keyword        string    `form:"q"`
+	CompanyID      uint      `form:"company_id"`
+	Location       string    `form:"location"`
+	EmploymentType string    `form:"employment_type" validate:"omitempty,oneof=full_time part_time contract internship temporary"`
+	SalaryMin      int       `form:"salary_min" validate:"gte=0"`
+	SalaryMax      int       `form:"salary_max" validate:"omitempty,gtefield=SalaryMin"`
+	SalaryCurrency string    `form:"salary_currency" validate:"required_with=SalaryMin SalaryMax,omitempty,iso4217"`
+	PostedSince    time.Time `form:"posted_since" time_format:"2006-01-02" time_utc:"1"`
+	Sort           string    `form:"sort" validate:"omitempty,oneof=newest oldest salary_desc salary_asc"`
+	Limit          int       `form:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor         string    `form:"cursor"`
+}
+
+// JobPage is one page of jobs. NextCursor is empty on the last page, otherwise it is passed back as the cursor
+// query parameter to fetch the next page.
+type JobPage struct {
+	Jobs       []Job  `json:"jobs"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// RecoveryCode lets a user log in once without their authenticator app. Only the SHA-256 hash of the code is
+// stored, and UsedAt is set when it is used.
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+// TOTPEnrollment is returned when a user starts enrolling an authenticator app. The app is set up by scanning
+// ProvisioningURI as a QR code, or by typing Secret.
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFALogin is the answer to a login with the right password on an account with two-factor authentication. The
+// client sends MFAToken back with a code from the authenticator app to get the access token.
+type MFALogin struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+}
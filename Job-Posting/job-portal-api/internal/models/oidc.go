@@ -0,0 +1,10 @@
+package models
+
+// OIDCLogin is a login started at the single sign-on provider. The user is sent to AuthorizationURL, while State,
+// Nonce and CodeVerifier are kept for when the provider sends them back, and must not be shown to anyone else.
+type OIDCLogin struct {
+	AuthorizationURL string
+	State            string
+	Nonce            string
+	CodeVerifier     string
+}
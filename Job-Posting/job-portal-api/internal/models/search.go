@@ -0,0 +1,26 @@
+package models
+
+// SearchQuery holds the query parameters of a full-text search over jobs and companies. Results are ranked, so
+// the database has to rank every match before the offset of a page, and pages past the hundredth aren't served.
+type SearchQuery struct {
+	Query string `form:"q" validate:"required,max=200"`
+	Limit int    `form:"limit" validate:"omitempty,min=1,max=100"`
+	Page  int    `form:"page" validate:"omitempty,min=1,max=100"`
+}
+
+// SearchResult is a job matching a search, with its relevance and the matching text highlighted in <mark> tags.
+// TitleHighlight and Snippet are escaped for HTML, so they can be shown as is.
+type SearchResult struct {
+	Job            Job     `json:"job"`
+	CompanyName    string  `json:"company_name"`
+	Rank           float64 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"`
+	Snippet        string  `json:"snippet"`
+}
+
+// SearchPage is one page of search results, best matches first.
+type SearchPage struct {
+	Results []SearchResult `json:"results"`
+	Total   int64          `json:"total"`
+	Page    int            `json:"page"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken is a long-lived token used to obtain new access tokens. Only the SHA-256 hash of the token is
+// stored. Each token is single use: refreshing revokes it and issues a new one.
+type RefreshToken struct {
+	gorm.Model
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash string     `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// RevokedToken records the ID (jti) of an access token that must no longer be accepted. Rows can be removed once
+// ExpiresAt has passed, because the token is rejected as expired from then on.
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey" json:"jti"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Purposes of a UserToken.
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+	// TokenPurposeMFAChallenge tokens are not emailed nor stored. They are handed out on login when the password
+	// is right, and exchanged for an access token along with a code from the authenticator app.
+	TokenPurposeMFAChallenge = "mfa_challenge"
+)
+
+// UserToken is a single use token emailed to a user, to verify their email address or to reset their password.
+// Only the SHA-256 hash of the token is stored, and UsedAt is set when it is redeemed.
+type UserToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Purpose   string     `gorm:"not null" json:"purpose"`
+	TokenHash string     `gorm:"not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
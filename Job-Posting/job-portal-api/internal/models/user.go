@@ -1,18 +1,51 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// Roles a user can hold. Candidates browse and apply to jobs, recruiters
+// manage companies and post jobs, admins can do everything.
+const (
+	RoleCandidate = "candidate"
+	RoleRecruiter = "recruiter"
+	RoleAdmin     = "admin"
+)
+
 type User struct {
 	gorm.Model
 	Name         string `gorm:"unique;not null" json:"name"`
 	Email        string `json:"email"`
 	PasswordHash string `json:"-"`
+	Role         string `gorm:"not null;default:candidate" json:"role"`
+	// FailedLogins counts the failed logins since the last successful one. Once there are too many, logins are
+	// refused until LockedUntil.
+	FailedLogins int        `gorm:"not null;default:0" json:"-"`
+	LockedUntil  *time.Time `json:"-"`
+	// EmailVerifiedAt is set once the user follows the link emailed to them. Until then they can't post jobs.
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// TOTPSecret is the secret shared with the authenticator app of the user, set when enrolment starts. Logins
+	// only ask for a code once TOTPEnabledAt is set by confirming a first code. TOTPLastStep is the time step of
+	// the last code accepted.
+	TOTPSecret    string     `gorm:"column:totp_secret;not null;default:''" json:"-"`
+	TOTPEnabledAt *time.Time `gorm:"column:totp_enabled_at" json:"mfa_enabled_at,omitempty"`
+	TOTPLastStep  int64      `gorm:"column:totp_last_step;not null;default:0" json:"-"`
+	// OIDCIssuer and OIDCSubject identify the account of the user at the single sign-on provider, once they
+	// logged in through it.
+	OIDCIssuer  *string `gorm:"column:oidc_issuer" json:"-"`
+	OIDCSubject *string `gorm:"column:oidc_subject" json:"-"`
 }
 
 type NewUser struct {
 	Name     string `json:"name" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Password string `json:"password" validate:"required,password"`
+}
+
+// UserRole is the role an admin gives a user. Users register as candidates and only an admin makes them
+// recruiters, so that anyone can't post jobs.
+type UserRole struct {
+	Role string `json:"role" validate:"required,oneof=candidate recruiter"`
 }
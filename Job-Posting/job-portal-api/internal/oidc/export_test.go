@@ -0,0 +1,8 @@
+package oidc
+
+import "time"
+
+// SetNow makes the provider read the time from now.
+func (p *Provider) SetNow(now func() time.Time) {
+	p.now = now
+}
@@ -0,0 +1,342 @@
+// Package oidc logs users in with an OpenID Connect provider, using the authorization code flow with PKCE
+// (RFC 7636). The provider is discovered from its issuer URL, and the ID tokens it returns are verified against the
+// keys it publishes.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"job-portal-api/internal/auth"
+	"job-portal-api/internal/config"
+)
+
+// ErrLoginFailed is returned by Exchange when the provider refuses the code or returns an ID token that doesn't
+// check out. Other errors mean the provider couldn't be reached or misbehaves.
+var ErrLoginFailed = errors.New("oidc login failed")
+
+// maxResponseSize bounds what is read from the provider.
+const maxResponseSize = 1 << 20
+
+// keyRefreshInterval is how long to wait before fetching the keys of the provider again for a token signed with
+// an unknown key, so that such tokens can't make us hammer the provider.
+const keyRefreshInterval = time.Minute
+
+// leeway is the clock skew tolerated between the provider and us.
+const leeway = time.Minute
+
+// Identity is the user as the provider knows them, read from a verified ID token.
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider is an OpenID Connect provider. Its metadata and keys are fetched on first use and cached.
+type Provider struct {
+	cfg    config.OIDC
+	client *http.Client
+	now    func() time.Time
+
+	mu          sync.Mutex
+	meta        *metadata
+	keys        map[string]*rsa.PublicKey
+	keysFetched time.Time
+}
+
+// metadata is the part of the discovery document (OpenID Connect Discovery 1.0) the login needs.
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// New returns the provider configured by cfg, which is reached with client.
+func New(cfg config.OIDC, client *http.Client) *Provider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Provider{cfg: cfg, client: client, now: time.Now}
+}
+
+// Issuer returns the issuer URL of the provider.
+func (p *Provider) Issuer() string {
+	return p.cfg.Issuer
+}
+
+// AuthCodeURL returns the URL of the provider to send the user to. The provider sends them back to the redirect
+// URL with a code to pass to Exchange, along with state. The nonce ends up in the ID token, and the code can only
+// be exchanged with the verifier.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(meta.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("parsing authorization endpoint: %w", err)
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", Challenge(verifier))
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Exchange redeems the code the provider sent the user back with and returns who they are. verifier and nonce
+// must be the ones given to AuthCodeURL.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (Identity, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, fmt.Errorf("building token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		// RFC 6749 section 2.3.1 wants the credentials form encoded before they are put in the header
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Identity{}, fmt.Errorf("requesting token: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return Identity{}, fmt.Errorf("reading token response: %w", err)
+	}
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		var e struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(body, &e)
+		return Identity{}, fmt.Errorf("%w: token request refused: %q", ErrLoginFailed, e.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Identity{}, fmt.Errorf("token endpoint responded %s", resp.Status)
+	}
+
+	var tkn struct {
+		IDToken string `json:"id_token"`
+	}
+	err = json.Unmarshal(body, &tkn)
+	if err != nil {
+		return Identity{}, fmt.Errorf("decoding token response: %w", err)
+	}
+	if tkn.IDToken == "" {
+		return Identity{}, errors.New("token response has no id token")
+	}
+	return p.verify(ctx, tkn.IDToken, nonce)
+}
+
+// idClaims are the claims of an ID token.
+type idClaims struct {
+	jwt.RegisteredClaims
+	AuthorizedParty string `json:"azp"`
+	Nonce           string `json:"nonce"`
+	Email           string `json:"email"`
+	EmailVerified   any    `json:"email_verified"`
+	Name            string `json:"name"`
+}
+
+// verify checks the ID token as OpenID Connect Core 1.0 section 3.1.3.7 asks a client to.
+func (p *Provider) verify(ctx context.Context, idToken, nonce string) (Identity, error) {
+	var c idClaims
+	_, err := jwt.ParseWithClaims(idToken, &c,
+		func(t *jwt.Token) (interface{}, error) {
+			kid, _ := t.Header["kid"].(string)
+			return p.key(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithLeeway(leeway),
+		jwt.WithTimeFunc(p.now),
+	)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %w", ErrLoginFailed, err)
+	}
+	if c.ExpiresAt == nil || c.Subject == "" {
+		return Identity{}, fmt.Errorf("%w: id token has no expiry or subject", ErrLoginFailed)
+	}
+	if len(c.Audience) > 1 && c.AuthorizedParty != p.cfg.ClientID {
+		return Identity{}, fmt.Errorf("%w: id token is meant for %q", ErrLoginFailed, c.AuthorizedParty)
+	}
+	if c.Nonce == "" || subtle.ConstantTimeCompare([]byte(c.Nonce), []byte(nonce)) != 1 {
+		return Identity{}, fmt.Errorf("%w: id token nonce doesn't match", ErrLoginFailed)
+	}
+
+	// Some providers send email_verified as a string
+	verified := c.EmailVerified == true || c.EmailVerified == "true"
+	return Identity{
+		Issuer:        c.Issuer,
+		Subject:       c.Subject,
+		Email:         c.Email,
+		EmailVerified: verified,
+		Name:          c.Name,
+	}, nil
+}
+
+// discover returns the metadata of the provider, fetching it on first use. The issuer it claims must be exactly
+// the configured one, or its tokens would never verify.
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.meta != nil {
+		return p.meta, nil
+	}
+
+	var meta metadata
+	err := p.getJSON(ctx, strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", &meta)
+	if err != nil {
+		return nil, fmt.Errorf("discovering oidc provider: %w", err)
+	}
+	if meta.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("oidc provider claims to be %q rather than %q", meta.Issuer, p.cfg.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("oidc provider metadata lacks an endpoint")
+	}
+	p.meta = &meta
+	return p.meta, nil
+}
+
+// key returns the public key of the provider with the given ID. Keys are fetched again when the provider signed
+// with one we don't know, as it may have rotated its keys. A token without kid is accepted when the provider has a
+// single key.
+func (p *Provider) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if k, ok := p.lookupKey(kid); ok {
+		return k, nil
+	}
+	if !p.keysFetched.IsZero() && p.now().Sub(p.keysFetched) < keyRefreshInterval {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	var set auth.JWKS
+	err = p.getJSON(ctx, meta.JWKSURI, &set)
+	if err != nil {
+		return nil, fmt.Errorf("fetching oidc provider keys: %w", err)
+	}
+	p.keys = make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		k, err := publicKey(jwk)
+		if err != nil {
+			// Keys of other types are of no use to us, but don't make the others unusable
+			continue
+		}
+		p.keys[jwk.Kid] = k
+	}
+	p.keysFetched = p.now()
+
+	if k, ok := p.lookupKey(kid); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+func (p *Provider) lookupKey(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+	k, ok := p.keys[kid]
+	return k, ok
+}
+
+func (p *Provider) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded %s", url, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v)
+}
+
+// publicKey converts a JWK to the RSA public key it represents.
+func publicKey(jwk auth.JWK) (*rsa.PublicKey, error) {
+	if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+		return nil, fmt.Errorf("key %q is not an rsa signing key", jwk.Kid)
+	}
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("decoding modulus of key %q: %w", jwk.Kid, err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("decoding exponent of key %q: %w", jwk.Kid, err)
+	}
+	exp := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("key %q is malformed", jwk.Kid)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+}
+
+// RandomValue returns 256 random bits, base64url encoded, for use as state, nonce or code verifier.
+func RandomValue() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("generating random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Challenge returns the S256 code challenge of the verifier.
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,148 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"job-portal-api/internal/oidc"
+	"job-portal-api/internal/oidc/oidctest"
+)
+
+const redirectURL = "http://localhost:8081/api/oidc/callback"
+
+// login goes through the flow up to the exchange, and returns the code along with the verifier and nonce it has
+// to be exchanged with.
+func login(t *testing.T, idp *oidctest.Server, p *oidc.Provider) (code, verifier, nonce string) {
+	t.Helper()
+	ctx := context.Background()
+	state, err := oidc.RandomValue()
+	require.NoError(t, err)
+	nonce, err = oidc.RandomValue()
+	require.NoError(t, err)
+	verifier, err = oidc.RandomValue()
+	require.NoError(t, err)
+
+	authURL, err := p.AuthCodeURL(ctx, state, nonce, verifier)
+	require.NoError(t, err)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	require.Equal(t, oidc.Challenge(verifier), u.Query().Get("code_challenge"))
+	require.Equal(t, "openid email profile", u.Query().Get("scope"))
+
+	code, gotState, err := idp.Authorize(authURL)
+	require.NoError(t, err)
+	require.Equal(t, state, gotState)
+	return code, verifier, nonce
+}
+
+func TestExchange(t *testing.T) {
+	idp := oidctest.New(t, "job-portal")
+	p := oidc.New(idp.Config(redirectURL), idp.Client())
+	ctx := context.Background()
+
+	code, verifier, nonce := login(t, idp, p)
+	id, err := p.Exchange(ctx, code, verifier, nonce)
+	require.NoError(t, err)
+	require.Equal(t, oidc.Identity{
+		Issuer:        idp.URL,
+		Subject:       "stub-subject",
+		Email:         "user@example.com",
+		EmailVerified: true,
+		Name:          "Stub User",
+	}, id)
+
+	// Codes are single use
+	_, err = p.Exchange(ctx, code, verifier, nonce)
+	require.ErrorIs(t, err, oidc.ErrLoginFailed)
+
+	// Some providers send email_verified as a string
+	idp.Tamper(func(c jwt.MapClaims) { c["email_verified"] = "true" })
+	code, verifier, nonce = login(t, idp, p)
+	id, err = p.Exchange(ctx, code, verifier, nonce)
+	require.NoError(t, err)
+	require.True(t, id.EmailVerified)
+}
+
+func TestExchangeErrors(t *testing.T) {
+	tt := []struct {
+		name   string
+		tamper func(jwt.MapClaims)
+		// exchange swaps the values passed to Exchange
+		exchange func(code, verifier, nonce string) (string, string, string)
+	}{
+		{
+			name:     "wrong verifier",
+			exchange: func(code, _, nonce string) (string, string, string) { return code, "not-the-verifier", nonce },
+		},
+		{
+			name:     "unknown code",
+			exchange: func(_, verifier, nonce string) (string, string, string) { return "made-up", verifier, nonce },
+		},
+		{
+			name:     "wrong nonce",
+			exchange: func(code, verifier, _ string) (string, string, string) { return code, verifier, "replayed" },
+		},
+		{name: "no nonce", tamper: func(c jwt.MapClaims) { delete(c, "nonce") }},
+		{name: "other issuer", tamper: func(c jwt.MapClaims) { c["iss"] = "https://evil.example" }},
+		{name: "other audience", tamper: func(c jwt.MapClaims) { c["aud"] = "someone-else" }},
+		{
+			name:   "other authorized party",
+			tamper: func(c jwt.MapClaims) { c["aud"] = []string{"job-portal", "someone-else"}; c["azp"] = "someone-else" },
+		},
+		{name: "expired", tamper: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-2 * time.Minute).Unix() }},
+		{name: "no expiry", tamper: func(c jwt.MapClaims) { delete(c, "exp") }},
+		{name: "no subject", tamper: func(c jwt.MapClaims) { delete(c, "sub") }},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			idp := oidctest.New(t, "job-portal")
+			idp.Tamper(tc.tamper)
+			p := oidc.New(idp.Config(redirectURL), idp.Client())
+
+			code, verifier, nonce := login(t, idp, p)
+			if tc.exchange != nil {
+				code, verifier, nonce = tc.exchange(code, verifier, nonce)
+			}
+			_, err := p.Exchange(context.Background(), code, verifier, nonce)
+			require.ErrorIs(t, err, oidc.ErrLoginFailed)
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	idp := oidctest.New(t, "job-portal")
+	p := oidc.New(idp.Config(redirectURL), idp.Client())
+	ctx := context.Background()
+	now := time.Now()
+	p.SetNow(func() time.Time { return now })
+
+	code, verifier, nonce := login(t, idp, p)
+	_, err := p.Exchange(ctx, code, verifier, nonce)
+	require.NoError(t, err)
+
+	// Unknown keys are only looked up once a minute
+	idp.RotateKey(t)
+	code, verifier, nonce = login(t, idp, p)
+	_, err = p.Exchange(ctx, code, verifier, nonce)
+	require.ErrorIs(t, err, oidc.ErrLoginFailed)
+
+	now = now.Add(2 * time.Minute)
+	code, verifier, nonce = login(t, idp, p)
+	_, err = p.Exchange(ctx, code, verifier, nonce)
+	require.NoError(t, err)
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	idp := oidctest.New(t, "job-portal")
+	cfg := idp.Config(redirectURL)
+	cfg.Issuer += "/"
+	p := oidc.New(cfg, idp.Client())
+
+	_, err := p.AuthCodeURL(context.Background(), "state", "nonce", "verifier")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, oidc.ErrLoginFailed)
+}
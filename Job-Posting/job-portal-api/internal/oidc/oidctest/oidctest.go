@@ -0,0 +1,253 @@
+// Package oidctest runs a stub OpenID Connect provider for tests. It approves every authorization request right
+// away, as User, and enforces PKCE on the token endpoint like a real provider would.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"job-portal-api/internal/auth"
+	"job-portal-api/internal/config"
+	"job-portal-api/internal/oidc"
+)
+
+// User is who logs in at the stub provider.
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Server is the stub provider. Its issuer is the URL of the server.
+type Server struct {
+	*httptest.Server
+	ClientID string
+
+	mu    sync.Mutex
+	user  User
+	codes map[string]grant
+	// tamper changes the claims of the ID tokens before they are signed.
+	tamper func(jwt.MapClaims)
+	keys   []*rsa.PrivateKey
+}
+
+// grant is what an authorization code stands for until it is redeemed.
+type grant struct {
+	user        User
+	redirectURI string
+	challenge   string
+	nonce       string
+}
+
+// New starts a stub provider for the client with the given ID, which is stopped at the end of the test.
+func New(t testing.TB, clientID string) *Server {
+	t.Helper()
+	s := &Server{
+		ClientID: clientID,
+		user:     User{Subject: "stub-subject", Email: "user@example.com", EmailVerified: true, Name: "Stub User"},
+		codes:    make(map[string]grant),
+	}
+	s.RotateKey(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/jwks", s.jwks)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Config returns the configuration of a client of the provider.
+func (s *Server) Config(redirectURL string) config.OIDC {
+	return config.OIDC{
+		Enabled:     true,
+		Issuer:      s.URL,
+		ClientID:    s.ClientID,
+		RedirectURL: redirectURL,
+		Scopes:      []string{"openid", "email", "profile"},
+		DefaultRole: "candidate",
+	}
+}
+
+// SetUser changes who logs in from now on.
+func (s *Server) SetUser(u User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = u
+}
+
+// Tamper makes the provider pass the claims of the ID tokens it issues to f before signing them, to issue tokens
+// a client must refuse.
+func (s *Server) Tamper(f func(jwt.MapClaims)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tamper = f
+}
+
+// RotateKey makes the provider sign with a new key. The previous ones stay published.
+func (s *Server) RotateKey(t testing.TB) {
+	t.Helper()
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = append(s.keys, k)
+}
+
+// Authorize follows authURL like a browser would, and returns the code and state the provider redirects back
+// with.
+func (s *Server) Authorize(authURL string) (code string, state string, err error) {
+	client := s.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Get(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		return "", "", fmt.Errorf("authorize responded %s", resp.Status)
+	}
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return "", "", err
+	}
+	return loc.Query().Get("code"), loc.Query().Get("state"), nil
+}
+
+func (s *Server) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != s.ClientID || q.Get("redirect_uri") == "" ||
+		q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code, err := oidc.RandomValue()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.mu.Lock()
+	s.codes[code] = grant{
+		user:        s.user,
+		redirectURI: q.Get("redirect_uri"),
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+	}
+	s.mu.Unlock()
+
+	rq := redirect.Query()
+	rq.Set("code", code)
+	rq.Set("state", q.Get("state"))
+	redirect.RawQuery = rq.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	clientID := r.PostForm.Get("client_id")
+	if id, _, ok := r.BasicAuth(); ok {
+		clientID, _ = url.QueryUnescape(id)
+	}
+	if clientID != s.ClientID {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	s.mu.Lock()
+	// Codes are single use
+	g, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	tamper := s.tamper
+	key := s.keys[len(s.keys)-1]
+	s.mu.Unlock()
+	if !ok || g.redirectURI != r.PostForm.Get("redirect_uri") || oidc.Challenge(r.PostForm.Get("code_verifier")) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            s.URL,
+		"sub":            g.user.Subject,
+		"aud":            s.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          g.nonce,
+		"email":          g.user.Email,
+		"email_verified": g.user.EmailVerified,
+		"name":           g.user.Name,
+	}
+	if tamper != nil {
+		tamper(claims)
+	}
+	tkn := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tkn.Header["kid"] = auth.KeyID(&key.PublicKey)
+	idToken, err := tkn.SignedString(key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "stub-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	signing := make([]auth.SigningKey, len(s.keys))
+	for i, k := range s.keys {
+		signing[i] = auth.SigningKey{ID: auth.KeyID(&k.PublicKey), PrivateKey: k, PublicKey: &k.PublicKey}
+	}
+	a, err := auth.NewAuthWithKeys(signing[len(signing)-1].ID, signing...)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, a.JWKS())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,63 @@
+// Package problem renders error responses as problem details, the JSON format defined by RFC 7807.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"job-portal-api/internal/validate"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType is the media type of a problem details response.
+const ContentType = "application/problem+json"
+
+// Details describes a failed request. Type is always about:blank, so Title is the text of the status code and
+// Detail says what went wrong with this particular request. TraceID is an extension member that lets the client
+// quote the request when reporting a problem, and Errors lists the fields of an invalid request.
+type Details struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	TraceID  string `json:"trace_id,omitempty"`
+
+	Errors []validate.FieldError `json:"errors,omitempty"`
+}
+
+// New returns the problem details of a response with the given status.
+func New(status int, detail string) Details {
+	return Details{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// Abort ends the request with a problem details response. The request path is used as the instance.
+func Abort(c *gin.Context, status int, traceId string, detail string) {
+	write(c, New(status, detail), traceId)
+}
+
+// AbortInvalid ends the request with a 400 problem details response that lists the fields that failed validation.
+func AbortInvalid(c *gin.Context, traceId string, errs validate.Errors) {
+	p := New(http.StatusBadRequest, "the request has invalid fields")
+	p.Errors = errs
+	write(c, p, traceId)
+}
+
+func write(c *gin.Context, p Details, traceId string) {
+	p.Instance = c.Request.URL.Path
+	p.TraceID = traceId
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Abort()
+	c.Data(p.Status, ContentType, body)
+}
@@ -0,0 +1,137 @@
+// Package ratelimit limits how often a client may call the API, with a token bucket per client and route.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"job-portal-api/internal/config"
+)
+
+// Scopes a bucket can be keyed by.
+const (
+	ScopeIP   = "ip"
+	ScopeUser = "user"
+)
+
+// sweepInterval is how often buckets that have refilled are dropped, so that the limiter doesn't keep one bucket
+// for every client it has ever seen.
+const sweepInterval = time.Minute
+
+// Result is the outcome of a call to Allow. Remaining is the number of requests the client may still make right
+// away, Reset is how long until its bucket is full again and RetryAfter, set when the request is denied, is how
+// long until the next request would be allowed.
+type Result struct {
+	Allowed    bool
+	Limit      config.Limit
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Limiter hands out the tokens of the buckets. It is safe for concurrent use.
+type Limiter struct {
+	def    config.Limit
+	routes map[string]config.Limit
+	now    func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[key]*bucket
+	lastSweep time.Time
+}
+
+type key struct {
+	scope, route, id string
+}
+
+type bucket struct {
+	limit  config.Limit
+	tokens float64
+	last   time.Time
+}
+
+// New returns a Limiter enforcing the limits of cfg. Whether cfg is enabled is up to the caller.
+func New(cfg config.RateLimit) *Limiter {
+	return newLimiter(cfg, time.Now)
+}
+
+func newLimiter(cfg config.RateLimit, now func() time.Time) *Limiter {
+	return &Limiter{
+		def:       cfg.Default,
+		routes:    cfg.Routes,
+		now:       now,
+		buckets:   make(map[key]*bucket),
+		lastSweep: now(),
+	}
+}
+
+// Allow takes a token from the bucket of client id in scope for route, and reports whether there was one. Routes
+// without a limit of their own share the default bucket of the client.
+func (l *Limiter) Allow(scope, route, id string) Result {
+	limit, ok := l.routes[route]
+	if !ok {
+		limit, route = l.def, ""
+	}
+	k := key{scope: scope, route: route, id: id}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[k]
+	if !ok {
+		b = &bucket{limit: limit, tokens: float64(limit.Requests), last: now}
+		l.buckets[k] = b
+	}
+	b.refill(now)
+
+	r := Result{Limit: limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		r.Allowed = true
+	} else {
+		r.RetryAfter = b.timeFor(1 - b.tokens)
+	}
+	r.Remaining = int(math.Floor(b.tokens))
+	r.Reset = b.timeFor(float64(limit.Requests) - b.tokens)
+	return r
+}
+
+// sweep drops the buckets that are full by now, a new bucket would behave the same.
+func (l *Limiter) sweep(now time.Time) {
+	for k, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Requests) {
+			delete(l.buckets, k)
+		}
+	}
+	l.lastSweep = now
+}
+
+// refill adds the tokens earned since the last request, up to the size of the bucket.
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last)
+	if elapsed <= 0 {
+		return
+	}
+	b.tokens = math.Min(float64(b.limit.Requests), b.tokens+elapsed.Seconds()*b.rate())
+	b.last = now
+}
+
+// timeFor returns how long the bucket takes to earn n tokens.
+func (b *bucket) timeFor(n float64) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	return time.Duration(n / b.rate() * float64(time.Second))
+}
+
+// rate is the number of tokens earned per second.
+func (b *bucket) rate() float64 {
+	return float64(b.limit.Requests) / b.limit.Per.Seconds()
+}
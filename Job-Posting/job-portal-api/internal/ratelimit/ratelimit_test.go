@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"job-portal-api/internal/config"
+)
+
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func newTestLimiter() (*Limiter, *clock) {
+	c := &clock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := newLimiter(config.RateLimit{
+		Enabled: true,
+		Default: config.Limit{Requests: 3, Per: config.Duration{Duration: 3 * time.Second}},
+		Routes: map[string]config.Limit{
+			"/api/login":    {Requests: 2, Per: config.Duration{Duration: time.Minute}},
+			"/api/register": {Requests: 1, Per: config.Duration{Duration: time.Hour}},
+		},
+	}, c.now)
+	return l, c
+}
+
+func TestAllow(t *testing.T) {
+	l, c := newTestLimiter()
+
+	for i := 2; i >= 0; i-- {
+		r := l.Allow(ScopeIP, "/api/jobs", "10.0.0.1")
+		require.True(t, r.Allowed)
+		require.Equal(t, i, r.Remaining)
+	}
+
+	// The default bucket is shared by every route without a limit of its own
+	r := l.Allow(ScopeIP, "/api/view", "10.0.0.1")
+	require.False(t, r.Allowed)
+	require.Equal(t, 0, r.Remaining)
+	require.Equal(t, time.Second, r.RetryAfter)
+	require.Equal(t, 3*time.Second, r.Reset)
+
+	// Other clients and scopes have buckets of their own
+	require.True(t, l.Allow(ScopeIP, "/api/jobs", "10.0.0.2").Allowed)
+	require.True(t, l.Allow(ScopeUser, "/api/jobs", "10.0.0.1").Allowed)
+
+	// One token is earned every second
+	c.advance(time.Second)
+	r = l.Allow(ScopeIP, "/api/jobs", "10.0.0.1")
+	require.True(t, r.Allowed)
+	require.Equal(t, 0, r.Remaining)
+	require.False(t, l.Allow(ScopeIP, "/api/jobs", "10.0.0.1").Allowed)
+
+	// The bucket never holds more than its size
+	c.advance(time.Hour)
+	r = l.Allow(ScopeIP, "/api/jobs", "10.0.0.1")
+	require.True(t, r.Allowed)
+	require.Equal(t, 2, r.Remaining)
+}
+
+func TestAllowRoute(t *testing.T) {
+	l, c := newTestLimiter()
+
+	require.True(t, l.Allow(ScopeIP, "/api/login", "10.0.0.1").Allowed)
+	require.True(t, l.Allow(ScopeIP, "/api/login", "10.0.0.1").Allowed)
+	r := l.Allow(ScopeIP, "/api/login", "10.0.0.1")
+	require.False(t, r.Allowed)
+	require.Equal(t, 2, r.Limit.Requests)
+	require.Equal(t, 30*time.Second, r.RetryAfter)
+
+	// The route has its own bucket, the default one is untouched
+	require.Equal(t, 2, l.Allow(ScopeIP, "/api/jobs", "10.0.0.1").Remaining)
+
+	c.advance(30 * time.Second)
+	require.True(t, l.Allow(ScopeIP, "/api/login", "10.0.0.1").Allowed)
+}
+
+func TestSweep(t *testing.T) {
+	l, c := newTestLimiter()
+
+	l.Allow(ScopeIP, "/api/jobs", "10.0.0.1")
+	l.Allow(ScopeIP, "/api/register", "10.0.0.1")
+	require.Len(t, l.buckets, 2)
+
+	// After a minute the default bucket has refilled and is dropped, the register bucket has not
+	c.advance(sweepInterval)
+	l.Allow(ScopeIP, "/api/jobs", "10.0.0.2")
+	require.Len(t, l.buckets, 2)
+	require.Contains(t, l.buckets, key{scope: ScopeIP, route: "/api/register", id: "10.0.0.1"})
+	require.NotContains(t, l.buckets, key{scope: ScopeIP, id: "10.0.0.1"})
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"job-portal-api/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (r *Repo) CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, error) {
+	result := r.DB.WithContext(ctx).Create(&key)
+	if result.Error != nil {
+		return models.APIKey{}, result.Error
+	}
+	return key, nil
+}
+
+// ViewAPIKeysByCompany returns every API key of the company, revoked and expired ones included, oldest first.
+func (r *Repo) ViewAPIKeysByCompany(ctx context.Context, cid uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	result := r.DB.WithContext(ctx).Where("company_id = ?", cid).Order("id").Find(&keys)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return keys, nil
+}
+
+func (r *Repo) FindAPIKey(ctx context.Context, keyHash string) (models.APIKey, error) {
+	var key models.APIKey
+	result := r.DB.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key)
+	if result.Error != nil {
+		return models.APIKey{}, result.Error
+	}
+	return key, nil
+}
+
+// RevokeAPIKey revokes the key of the company with the given ID. It returns gorm.ErrRecordNotFound when the
+// company has no such key or it was revoked already.
+func (r *Repo) RevokeAPIKey(ctx context.Context, cid uint, kid uint) error {
+	result := r.DB.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ? AND company_id = ? AND revoked_at IS NULL", kid, cid).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// TouchAPIKey records that the key was used at the given time. The time is only written when the previous one is
+// older than precision, so that a busy integration doesn't write on every request.
+func (r *Repo) TouchAPIKey(ctx context.Context, kid uint, at time.Time, precision time.Duration) error {
+	return r.DB.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", kid, at.Add(-precision)).
+		UpdateColumn("last_used_at", at).Error
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"job-portal-api/internal/models"
+)
+
+func TestAPIKeys(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	owner := createTestUser(t, r, "recruiter", models.RoleRecruiter)
+	company := createTestCompany(t, r, owner, "tek")
+	other := createTestCompany(t, r, owner, "other")
+
+	k, err := r.CreateAPIKey(ctx, models.APIKey{
+		CompanyID: company.ID,
+		CreatedBy: owner.ID,
+		Name:      "ats",
+		Prefix:    "jpk_abcdefgh",
+		KeyHash:   "hash-1",
+		Scopes:    []string{models.ScopeJobsRead, models.ScopeJobsWrite},
+	})
+	require.NoError(t, err)
+	_, err = r.CreateAPIKey(ctx, models.APIKey{CompanyID: other.ID, CreatedBy: owner.ID, Name: "other", Prefix: "jpk_ijklmnop", KeyHash: "hash-2", Scopes: []string{models.ScopeJobsRead}})
+	require.NoError(t, err)
+
+	found, err := r.FindAPIKey(ctx, "hash-1")
+	require.NoError(t, err)
+	require.Equal(t, k.ID, found.ID)
+	require.Equal(t, []string{models.ScopeJobsRead, models.ScopeJobsWrite}, found.Scopes)
+	_, err = r.FindAPIKey(ctx, "unknown")
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	keys, err := r.ViewAPIKeysByCompany(ctx, company.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, "ats", keys[0].Name)
+
+	// The last use is only written once per precision
+	first := time.Now().Add(-2 * time.Minute)
+	require.NoError(t, r.TouchAPIKey(ctx, k.ID, first, time.Minute))
+	require.NoError(t, r.TouchAPIKey(ctx, k.ID, first.Add(30*time.Second), time.Minute))
+	found, err = r.FindAPIKey(ctx, "hash-1")
+	require.NoError(t, err)
+	require.NotNil(t, found.LastUsedAt)
+	require.True(t, first.Equal(*found.LastUsedAt))
+	now := time.Now()
+	require.NoError(t, r.TouchAPIKey(ctx, k.ID, now, time.Minute))
+	found, err = r.FindAPIKey(ctx, "hash-1")
+	require.NoError(t, err)
+	require.True(t, now.Equal(*found.LastUsedAt))
+
+	// A key is revoked through its own company, once
+	require.ErrorIs(t, r.RevokeAPIKey(ctx, other.ID, k.ID), gorm.ErrRecordNotFound)
+	require.NoError(t, r.RevokeAPIKey(ctx, company.ID, k.ID))
+	require.ErrorIs(t, r.RevokeAPIKey(ctx, company.ID, k.ID), gorm.ErrRecordNotFound)
+	found, err = r.FindAPIKey(ctx, "hash-1")
+	require.NoError(t, err)
+	require.NotNil(t, found.RevokedAt)
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"job-portal-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrStatusChanged is returned by TransitionApplication when the application is no longer in the expected status,
+// because someone else moved it in the meantime.
+var ErrStatusChanged = errors.New("application status changed concurrently")
+
+// CreateApplication stores the application together with the first entry of its timeline.
+func (r *Repo) CreateApplication(ctx context.Context, application models.Application) (models.Application, error) {
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&application).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&models.ApplicationStatusChange{
+			ApplicationID: application.ID,
+			ToStatus:      application.Status,
+			ChangedBy:     application.UserID,
+		}).Error
+	})
+	if err != nil {
+		return models.Application{}, err
+	}
+	return application, nil
+}
+
+// HasApplied reports whether the user already applied to the job.
+func (r *Repo) HasApplied(ctx context.Context, jid uint, uid uint) (bool, error) {
+	var count int64
+	result := r.DB.WithContext(ctx).Model(&models.Application{}).
+		Where("job_id = ? AND user_id = ?", jid, uid).Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+func (r *Repo) ViewApplicationById(ctx context.Context, aid uint) (models.Application, error) {
+	var application models.Application
+	result := r.DB.WithContext(ctx).First(&application, aid)
+	if result.Error != nil {
+		return models.Application{}, result.Error
+	}
+	return application, nil
+}
+
+func (r *Repo) ViewApplicationsByUser(ctx context.Context, uid uint) ([]models.Application, error) {
+	var applications []models.Application
+	result := r.DB.WithContext(ctx).Where("user_id = ?", uid).Order("created_at desc").Find(&applications)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return applications, nil
+}
+
+func (r *Repo) ViewApplicationsByJob(ctx context.Context, jid uint) ([]models.Application, error) {
+	var applications []models.Application
+	result := r.DB.WithContext(ctx).Where("job_id = ?", jid).Order("created_at").Find(&applications)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return applications, nil
+}
+
+// TransitionApplication moves the application from change.FromStatus to change.ToStatus and records the change
+// in its timeline, in a single transaction. It returns ErrStatusChanged if the application is no longer in
+// change.FromStatus.
+func (r *Repo) TransitionApplication(ctx context.Context, change models.ApplicationStatusChange) (models.Application, error) {
+	var application models.Application
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Application{}).
+			Where("id = ? AND status = ?", change.ApplicationID, change.FromStatus).
+			Update("status", change.ToStatus)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStatusChanged
+		}
+		err := tx.Create(&change).Error
+		if err != nil {
+			return err
+		}
+		return tx.First(&application, change.ApplicationID).Error
+	})
+	if err != nil {
+		return models.Application{}, err
+	}
+	return application, nil
+}
+
+func (r *Repo) ViewApplicationTimeline(ctx context.Context, aid uint) ([]models.ApplicationStatusChange, error) {
+	var changes []models.ApplicationStatusChange
+	result := r.DB.WithContext(ctx).Where("application_id = ?", aid).Order("created_at, id").Find(&changes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return changes, nil
+}
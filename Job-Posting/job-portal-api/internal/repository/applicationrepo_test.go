@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"job-portal-api/internal/models"
+)
+
+func TestApplications(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	owner := createTestUser(t, r, "owner", models.RoleRecruiter)
+	candidate := createTestUser(t, r, "candidate", models.RoleCandidate)
+	company := createTestCompany(t, r, owner, "infy")
+	job := createTestJob(t, r, company, models.Job{Title: "Developer"})
+
+	application, err := r.CreateApplication(ctx, models.Application{
+		JobID:     job.ID,
+		UserID:    candidate.ID,
+		ResumeURL: "https://example.com/cv.pdf",
+		Status:    models.ApplicationStatusApplied,
+	})
+	require.NoError(t, err)
+
+	applied, err := r.HasApplied(ctx, job.ID, candidate.ID)
+	require.NoError(t, err)
+	require.True(t, applied)
+	_, err = r.CreateApplication(ctx, models.Application{JobID: job.ID, UserID: candidate.ID, Status: models.ApplicationStatusApplied})
+	require.ErrorIs(t, err, gorm.ErrDuplicatedKey, "a candidate can apply to a job only once")
+
+	moved, err := r.TransitionApplication(ctx, models.ApplicationStatusChange{
+		ApplicationID: application.ID,
+		FromStatus:    models.ApplicationStatusApplied,
+		ToStatus:      models.ApplicationStatusScreening,
+		ChangedBy:     owner.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, models.ApplicationStatusScreening, moved.Status)
+
+	// A second recruiter acting on the stale status loses
+	_, err = r.TransitionApplication(ctx, models.ApplicationStatusChange{
+		ApplicationID: application.ID,
+		FromStatus:    models.ApplicationStatusApplied,
+		ToStatus:      models.ApplicationStatusRejected,
+		ChangedBy:     owner.ID,
+	})
+	require.ErrorIs(t, err, ErrStatusChanged)
+
+	timeline, err := r.ViewApplicationTimeline(ctx, application.ID)
+	require.NoError(t, err)
+	require.Len(t, timeline, 2)
+	require.Equal(t, "", timeline[0].FromStatus)
+	require.Equal(t, models.ApplicationStatusApplied, timeline[0].ToStatus)
+	require.Equal(t, models.ApplicationStatusScreening, timeline[1].ToStatus)
+
+	byJob, err := r.ViewApplicationsByJob(ctx, job.ID)
+	require.NoError(t, err)
+	require.Len(t, byJob, 1)
+	byUser, err := r.ViewApplicationsByUser(ctx, owner.ID)
+	require.NoError(t, err)
+	require.Empty(t, byUser)
+}
@@ -3,13 +3,19 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
 	"job-portal-api/internal/models"
+	"time"
 )
 
+// ErrCompanyDeleted is returned when restoring a job whose company is deleted.
+var ErrCompanyDeleted = errors.New("company is deleted")
+
 func (r *Repo) ViewJobDetailsBy(ctx context.Context, jid uint64) (models.Job, error) {
 	var job models.Job
-	result := r.DB.First(&job, jid)
+	result := r.DB.WithContext(ctx).First(&job, jid)
 
 	if result.Error != nil {
 		return models.Job{}, result.Error
@@ -19,7 +25,7 @@ func (r *Repo) ViewJobDetailsBy(ctx context.Context, jid uint64) (models.Job, er
 
 func (r *Repo) ViewJobByCompanyId(ctx context.Context, id uint) ([]models.Job, error) {
 	var jobs []models.Job
-	result := r.DB.Where("company_id = ?", id).Find(&jobs)
+	result := openJobs(r.DB.WithContext(ctx), time.Now()).Where("company_id = ?", id).Find(&jobs)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -29,7 +35,7 @@ func (r *Repo) ViewJobByCompanyId(ctx context.Context, id uint) ([]models.Job, e
 }
 
 func (r *Repo) CreateJob(ctx context.Context, jobData models.Job) (models.Job, error) {
-	result := r.DB.Create(&jobData)
+	result := r.DB.WithContext(ctx).Create(&jobData)
 
 	if result.Error != nil {
 		return models.Job{}, result.Error
@@ -37,22 +43,45 @@ func (r *Repo) CreateJob(ctx context.Context, jobData models.Job) (models.Job, e
 	return jobData, nil
 }
 
-func (r *Repo) FindAllJobs(ctx context.Context) ([]models.Job, error) {
-	var jobs []models.Job
-	result := r.DB.Find(&jobs)
+// FindAllJobs returns one page of the jobs matching the filter, together with the total number of matches.
+func (r *Repo) FindAllJobs(ctx context.Context, f models.JobFilter) (models.JobPage, error) {
+	var cursor *jobCursor
+	if f.Cursor != "" {
+		c, err := decodeJobCursor(f.Cursor, f.Sort)
+		if err != nil {
+			return models.JobPage{}, err
+		}
+		cursor = &c
+	}
+
+	now := time.Now()
+	var total int64
+	result := filterJobs(openJobs(r.DB.WithContext(ctx).Model(&models.Job{}), now), f).Count(&total)
 	if result.Error != nil {
-		return nil, result.Error
+		return models.JobPage{}, result.Error
+	}
+
+	// Fetch one row more than asked for to know whether there is a next page.
+	jobs := make([]models.Job, 0, f.Limit+1)
+	result = pageJobs(filterJobs(openJobs(r.DB.WithContext(ctx), now), f), f.Sort, cursor).Limit(f.Limit + 1).Find(&jobs)
+	if result.Error != nil {
+		return models.JobPage{}, result.Error
 	}
-	return jobs, nil
 
+	page := models.JobPage{Jobs: jobs, Total: total}
+	if len(jobs) > f.Limit {
+		page.Jobs = jobs[:f.Limit]
+		page.NextCursor = encodeJobCursor(f.Sort, page.Jobs[f.Limit-1])
+	}
+	return page, nil
 }
 
 func (r *Repo) FindJob(ctx context.Context, cid uint64) ([]models.Job, error) {
 	var jobData []models.Job
-	result := r.DB.Where("cid = ?", cid).Find(&jobData)
+	result := r.DB.WithContext(ctx).Where("cid = ?", cid).Find(&jobData)
 	if result.Error != nil {
 		log.Info().Err(result.Error).Send()
-		return nil, errors.New("could not find the company")
+		return nil, fmt.Errorf("could not find the company: %w", result.Error)
 	}
 	return jobData, nil
 }
@@ -69,7 +98,10 @@ func (r *Repo) CreateCompany(ctx context.Context, companyData models.Companies)
 func (r *Repo) ViewCompanies(ctx context.Context) ([]models.Companies, error) {
 	var comp = make([]models.Companies, 0, 10)
 	var companies = make([]models.Companies, 0, 10)
-	r.DB.Find(&comp)
+	result := r.DB.WithContext(ctx).Find(&comp)
+	if result.Error != nil {
+		return nil, result.Error
+	}
 	for _, company := range comp {
 		companies = append(companies, company)
 
@@ -79,28 +111,157 @@ func (r *Repo) ViewCompanies(ctx context.Context) ([]models.Companies, error) {
 
 func (r *Repo) ViewCompanyById(ctx context.Context, cid uint) ([]models.Companies, error) {
 	var company []models.Companies
-	result := r.DB.Where("id = ?", cid).First(&company)
+	result := r.DB.WithContext(ctx).Where("id = ?", cid).First(&company)
 
 	if result.Error != nil {
 		return []models.Companies{}, result.Error
 	}
 	return company, nil
 }
-func (r *Repo) AutoMigrate() error {
-	//if s.db.Migrator().HasTable(&User{}se
-	//	return services
-	//}
 
-	err := r.DB.Migrator().AutoMigrate(&models.User{}, &models.Companies{}, &models.Job{})
+// TransitionJob applies changes, which must include the new status, to the job if its status is one of from.
+// It returns ErrStatusChanged if the job isn't in one of those states.
+func (r *Repo) TransitionJob(ctx context.Context, jid uint, from []string, changes map[string]interface{}) (models.Job, error) {
+	var job models.Job
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Job{}).Where("id = ? AND status IN ?", jid, from).Updates(changes)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStatusChanged
+		}
+		return tx.First(&job, jid).Error
+	})
 	if err != nil {
-		return err
+		return models.Job{}, err
+	}
+	return job, nil
+}
+
+// ExpireJobs marks every published job whose expiry date has passed as expired and returns how many were changed.
+func (r *Repo) ExpireJobs(ctx context.Context, now time.Time) (int64, error) {
+	result := r.DB.WithContext(ctx).Model(&models.Job{}).
+		Where("status = ? AND expires_at <= ?", models.JobStatusPublished, now).
+		Update("status", models.JobStatusExpired)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// openJobs restricts a query on jobs to the ones candidates can see: published and not yet expired. Jobs past
+// their expiry date are hidden right away, even before the sweeper marks them as expired.
+func openJobs(tx *gorm.DB, now time.Time) *gorm.DB {
+	return tx.Where("status = ? AND (expires_at IS NULL OR expires_at > ?)", models.JobStatusPublished, now)
+}
+
+// UpdateCompany saves the given fields of the company and returns it as stored.
+func (r *Repo) UpdateCompany(ctx context.Context, company models.Companies, fields []string) (models.Companies, error) {
+	result := r.DB.WithContext(ctx).Model(&company).Select(fields).Updates(&company)
+	if result.Error != nil {
+		return models.Companies{}, result.Error
+	}
+
+	var updated models.Companies
+	result = r.DB.WithContext(ctx).First(&updated, company.ID)
+	if result.Error != nil {
+		return models.Companies{}, result.Error
 	}
+	return updated, nil
+}
+
+// DeleteCompany soft deletes the company together with its jobs. The jobs get the same deletion time as the
+// company so that RestoreCompany brings back exactly those jobs.
+func (r *Repo) DeleteCompany(ctx context.Context, cid uint) error {
+	now := time.Now()
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Companies{}).Where("id = ?", cid).Update("deleted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&models.Job{}).Where("company_id = ?", cid).Update("deleted_at", now).Error
+	})
+}
+
+// RestoreCompany undoes DeleteCompany. Jobs that were deleted on their own before the company stay deleted.
+func (r *Repo) RestoreCompany(ctx context.Context, cid uint) (models.Companies, error) {
+	var company models.Companies
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", cid).First(&company)
+		if result.Error != nil {
+			return result.Error
+		}
 
-	// AutoMigrate function will ONLY create tables, missing columns and missing indexes, and WON'T change existing column's type or delete unused columns
-	err = r.DB.Migrator().AutoMigrate(&models.User{}, &models.Companies{})
+		result = tx.Unscoped().Model(&models.Job{}).
+			Where("company_id = ? AND deleted_at = ?", cid, company.DeletedAt).
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		company.DeletedAt = gorm.DeletedAt{}
+		return tx.Unscoped().Model(&company).Update("deleted_at", nil).Error
+	})
 	if err != nil {
-		// If there is an error while migrating, log the error message and stop the program
-		return err
+		return models.Companies{}, err
+	}
+	return company, nil
+}
+
+// UpdateJob saves the given fields of the job and returns it as stored.
+func (r *Repo) UpdateJob(ctx context.Context, job models.Job, fields []string) (models.Job, error) {
+	result := r.DB.WithContext(ctx).Model(&job).Select(fields).Updates(&job)
+	if result.Error != nil {
+		return models.Job{}, result.Error
+	}
+
+	var updated models.Job
+	result = r.DB.WithContext(ctx).First(&updated, job.ID)
+	if result.Error != nil {
+		return models.Job{}, result.Error
+	}
+	return updated, nil
+}
+
+// DeleteJob soft deletes the job.
+func (r *Repo) DeleteJob(ctx context.Context, jid uint) error {
+	result := r.DB.WithContext(ctx).Delete(&models.Job{}, jid)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
 	}
 	return nil
 }
+
+// RestoreJob undoes DeleteJob. It returns ErrCompanyDeleted if the company that posted the job is deleted.
+func (r *Repo) RestoreJob(ctx context.Context, jid uint) (models.Job, error) {
+	var job models.Job
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", jid).First(&job)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		var companies int64
+		result = tx.Model(&models.Companies{}).Where("id = ?", job.CompanyID).Count(&companies)
+		if result.Error != nil {
+			return result.Error
+		}
+		if companies == 0 {
+			return ErrCompanyDeleted
+		}
+
+		job.DeletedAt = gorm.DeletedAt{}
+		return tx.Unscoped().Model(&job).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		return models.Job{}, err
+	}
+	return job, nil
+}
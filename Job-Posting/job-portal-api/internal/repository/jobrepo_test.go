@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"job-portal-api/internal/models"
+)
+
+func TestFindAllJobs(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	owner := createTestUser(t, r, "owner", models.RoleRecruiter)
+	infy := createTestCompany(t, r, owner, "infy")
+	tcs := createTestCompany(t, r, owner, "tcs")
+
+	golang := createTestJob(t, r, infy, models.Job{Title: "Golang Developer", Location: "Banglore", EmploymentType: models.EmploymentFullTime, SalaryMin: 10, SalaryMax: 20})
+	rust := createTestJob(t, r, infy, models.Job{Title: "Rust Developer", Location: "Pune", EmploymentType: models.EmploymentContract, SalaryMin: 30, SalaryMax: 40})
+	java := createTestJob(t, r, tcs, models.Job{Title: "Java 100% Developer", Location: "Banglore", EmploymentType: models.EmploymentFullTime, SalaryMin: 15, SalaryMax: 25})
+
+	// Drafts and expired jobs are never listed
+	expired := time.Now().Add(-time.Hour)
+	createTestJob(t, r, infy, models.Job{Title: "Draft Developer", Status: models.JobStatusDraft})
+	createTestJob(t, r, infy, models.Job{Title: "Old Developer", Status: models.JobStatusPublished, ExpiresAt: &expired})
+
+	tt := []struct {
+		name   string
+		filter models.JobFilter
+		want   []uint
+	}{
+		{name: "newest first", filter: models.JobFilter{Sort: models.JobSortNewest, Limit: 10}, want: []uint{java.ID, rust.ID, golang.ID}},
+		{name: "oldest first", filter: models.JobFilter{Sort: models.JobSortOldest, Limit: 10}, want: []uint{golang.ID, rust.ID, java.ID}},
+		{name: "salary desc", filter: models.JobFilter{Sort: models.JobSortSalaryDesc, Limit: 10}, want: []uint{rust.ID, java.ID, golang.ID}},
+		{name: "keyword", filter: models.JobFilter{Keyword: "DEVELOPER", Sort: models.JobSortOldest, Limit: 10}, want: []uint{golang.ID, rust.ID, java.ID}},
+		{name: "keyword is not a pattern", filter: models.JobFilter{Keyword: "100%", Sort: models.JobSortOldest, Limit: 10}, want: []uint{java.ID}},
+		{name: "company", filter: models.JobFilter{CompanyID: tcs.ID, Sort: models.JobSortOldest, Limit: 10}, want: []uint{java.ID}},
+		{name: "location", filter: models.JobFilter{Location: "banglore", Sort: models.JobSortOldest, Limit: 10}, want: []uint{golang.ID, java.ID}},
+		{name: "employment type", filter: models.JobFilter{EmploymentType: models.EmploymentContract, Sort: models.JobSortOldest, Limit: 10}, want: []uint{rust.ID}},
+		{name: "salary overlap", filter: models.JobFilter{SalaryMin: 21, SalaryMax: 35, Sort: models.JobSortOldest, Limit: 10}, want: []uint{rust.ID, java.ID}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			page, err := r.FindAllJobs(ctx, tc.filter)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(tc.want)), page.Total)
+			require.Empty(t, page.NextCursor)
+
+			var got []uint
+			for _, j := range page.Jobs {
+				got = append(got, j.ID)
+			}
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestFindAllJobsPostedSince(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	owner := createTestUser(t, r, "owner", models.RoleRecruiter)
+	infy := createTestCompany(t, r, owner, "infy")
+
+	now := time.Now()
+	monthAgo := now.AddDate(0, -1, 0)
+	// A draft written a month ago and published today counts as posted today
+	fresh := createTestJob(t, r, infy, models.Job{Title: "Golang Developer", Model: gorm.Model{CreatedAt: monthAgo}, PublishedAt: &now})
+	createTestJob(t, r, infy, models.Job{Title: "Rust Developer", Model: gorm.Model{CreatedAt: monthAgo}, PublishedAt: &monthAgo})
+
+	page, err := r.FindAllJobs(ctx, models.JobFilter{PostedSince: now.AddDate(0, 0, -1), Sort: models.JobSortOldest, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Jobs, 1)
+	require.Equal(t, fresh.ID, page.Jobs[0].ID)
+}
+
+func TestFindAllJobsSortsByPublication(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	owner := createTestUser(t, r, "owner", models.RoleRecruiter)
+	infy := createTestCompany(t, r, owner, "infy")
+
+	now := time.Now()
+	monthAgo, weekAgo := now.AddDate(0, -1, 0), now.AddDate(0, 0, -7)
+	// A draft written a month ago and published today is the newest job
+	late := createTestJob(t, r, infy, models.Job{Title: "Golang Developer", Model: gorm.Model{CreatedAt: monthAgo}, PublishedAt: &now})
+	week := createTestJob(t, r, infy, models.Job{Title: "Rust Developer", Model: gorm.Model{CreatedAt: weekAgo}, PublishedAt: &weekAgo})
+
+	for sort, want := range map[string][]uint{
+		models.JobSortNewest: {late.ID, week.ID},
+		models.JobSortOldest: {week.ID, late.ID},
+	} {
+		t.Run(sort, func(t *testing.T) {
+			// One job per page, so the second one is found through the cursor
+			var got []uint
+			f := models.JobFilter{Sort: sort, Limit: 1}
+			for {
+				page, err := r.FindAllJobs(ctx, f)
+				require.NoError(t, err)
+				for _, j := range page.Jobs {
+					got = append(got, j.ID)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				f.Cursor = page.NextCursor
+			}
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestFindAllJobsSalaryCurrency(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	owner := createTestUser(t, r, "owner", models.RoleRecruiter)
+	infy := createTestCompany(t, r, owner, "infy")
+
+	usd := createTestJob(t, r, infy, models.Job{Title: "Golang Developer", SalaryMin: 60000, SalaryMax: 70000, SalaryCurrency: "USD"})
+	createTestJob(t, r, infy, models.Job{Title: "Rust Developer", SalaryMin: 60000, SalaryMax: 70000, SalaryCurrency: "INR"})
+
+	page, err := r.FindAllJobs(ctx, models.JobFilter{SalaryMin: 50000, SalaryCurrency: "USD", Sort: models.JobSortNewest, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Jobs, 1)
+	require.Equal(t, usd.ID, page.Jobs[0].ID)
+}
+
+func TestFindAllJobsPagination(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	owner := createTestUser(t, r, "owner", models.RoleRecruiter)
+	company := createTestCompany(t, r, owner, "infy")
+
+	var want []uint
+	for i := 0; i < 5; i++ {
+		// Equal salaries make the ID the tie breaker
+		job := createTestJob(t, r, company, models.Job{Title: "Developer", SalaryMax: 10})
+		want = append([]uint{job.ID}, want...)
+	}
+
+	for _, sort := range []string{models.JobSortNewest, models.JobSortSalaryDesc} {
+		t.Run(sort, func(t *testing.T) {
+			var got []uint
+			f := models.JobFilter{Sort: sort, Limit: 2}
+			for {
+				page, err := r.FindAllJobs(ctx, f)
+				require.NoError(t, err)
+				require.Equal(t, int64(5), page.Total)
+				for _, j := range page.Jobs {
+					got = append(got, j.ID)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				f.Cursor = page.NextCursor
+			}
+			require.Equal(t, want, got)
+		})
+	}
+
+	_, err := r.FindAllJobs(ctx, models.JobFilter{Sort: models.JobSortOldest, Limit: 2, Cursor: "not a cursor"})
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestJobLifecycle(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	owner := createTestUser(t, r, "owner", models.RoleRecruiter)
+	company := createTestCompany(t, r, owner, "infy")
+	draft := createTestJob(t, r, company, models.Job{Title: "Developer", Status: models.JobStatusDraft})
+
+	jobs, err := r.ViewJobByCompanyId(ctx, company.ID)
+	require.NoError(t, err)
+	require.Empty(t, jobs)
+
+	// Publish with an expiry date that has already passed, so the sweeper picks it up
+	expiresAt := time.Now().Add(-time.Minute)
+	published, err := r.TransitionJob(ctx, draft.ID, []string{models.JobStatusDraft}, map[string]interface{}{
+		"status":     models.JobStatusPublished,
+		"expires_at": expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, models.JobStatusPublished, published.Status)
+
+	_, err = r.TransitionJob(ctx, draft.ID, []string{models.JobStatusDraft}, map[string]interface{}{"status": models.JobStatusPublished})
+	require.ErrorIs(t, err, ErrStatusChanged)
+
+	n, err := r.ExpireJobs(ctx, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	job, err := r.ViewJobDetailsBy(ctx, uint64(draft.ID))
+	require.NoError(t, err)
+	require.Equal(t, models.JobStatusExpired, job.Status)
+}
+
+func TestUpdateJob(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	owner := createTestUser(t, r, "owner", models.RoleRecruiter)
+	company := createTestCompany(t, r, owner, "infy")
+	job := createTestJob(t, r, company, models.Job{Title: "Developer", Description: "Go", SalaryMin: 10, Skills: []string{"go"}})
+
+	job.Title = "Senior Developer"
+	job.SalaryMin = 0
+	job.Skills = []string{"go", "sql"}
+	job.Description = "ignored"
+	updated, err := r.UpdateJob(ctx, job, []string{"Title", "SalaryMin", "Skills"})
+	require.NoError(t, err)
+	require.Equal(t, "Senior Developer", updated.Title)
+	require.Equal(t, 0, updated.SalaryMin)
+	require.Equal(t, []string{"go", "sql"}, updated.Skills)
+	require.Equal(t, "Go", updated.Description, "fields that aren't selected are left alone")
+}
+
+func TestDeleteAndRestoreCompany(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	owner := createTestUser(t, r, "owner", models.RoleRecruiter)
+	company := createTestCompany(t, r, owner, "infy")
+	kept := createTestJob(t, r, company, models.Job{Title: "Kept"})
+	removed := createTestJob(t, r, company, models.Job{Title: "Removed"})
+
+	// A job deleted on its own stays deleted when the company is restored
+	require.NoError(t, r.DeleteJob(ctx, removed.ID))
+	require.ErrorIs(t, r.DeleteJob(ctx, removed.ID), gorm.ErrRecordNotFound)
+
+	require.NoError(t, r.DeleteCompany(ctx, company.ID))
+	_, err := r.ViewCompanyById(ctx, company.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = r.ViewJobDetailsBy(ctx, uint64(kept.ID))
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = r.RestoreJob(ctx, kept.ID)
+	require.ErrorIs(t, err, ErrCompanyDeleted)
+
+	restored, err := r.RestoreCompany(ctx, company.ID)
+	require.NoError(t, err)
+	require.Equal(t, company.ID, restored.ID)
+	_, err = r.ViewJobDetailsBy(ctx, uint64(kept.ID))
+	require.NoError(t, err)
+	_, err = r.ViewJobDetailsBy(ctx, uint64(removed.ID))
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = r.RestoreCompany(ctx, company.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = r.RestoreJob(ctx, removed.ID)
+	require.NoError(t, err)
+}
+
+func TestRepoUsesContext(t *testing.T) {
+	r := newTestRepo(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := r.ViewJobDetailsBy(ctx, 1)
+	require.ErrorIs(t, err, context.Canceled)
+	_, err = r.ViewCompanies(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	_, err = r.CreateUser(ctx, models.User{Name: "satyam"})
+	require.ErrorIs(t, err, context.Canceled)
+	_, err = r.ViewUserByEmail(ctx, "satyam@email.com")
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"job-portal-api/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor can't be decoded or doesn't match the sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// jobCursor points just past the last job of a page. It holds the value of the sort column and the ID of that
+// job, so the next page can be fetched with a keyset condition instead of an ever growing OFFSET.
+type jobCursor struct {
+	Sort        string    `json:"s"`
+	PublishedAt time.Time `json:"p,omitempty"`
+	Salary      int       `json:"m,omitempty"`
+	ID          uint      `json:"i"`
+}
+
+func encodeJobCursor(sort string, last models.Job) string {
+	c := jobCursor{Sort: sort, Salary: last.SalaryMax, ID: last.ID}
+	if last.PublishedAt != nil {
+		c.PublishedAt = *last.PublishedAt
+	}
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeJobCursor(s string, sort string) (jobCursor, error) {
+	var c jobCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return jobCursor{}, ErrInvalidCursor
+	}
+	err = json.Unmarshal(b, &c)
+	if err != nil || c.Sort != sort {
+		return jobCursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// filterJobs adds the WHERE conditions of the filter, except for the cursor, to the query.
+func filterJobs(tx *gorm.DB, f models.JobFilter) *gorm.DB {
+	if f.Keyword != "" {
+		kw := "%" + escapeLike(strings.ToLower(f.Keyword)) + "%"
+		tx = tx.Where("(LOWER(title) LIKE ? ESCAPE '\\' OR LOWER(description) LIKE ? ESCAPE '\\')", kw, kw)
+	}
+	if f.CompanyID != 0 {
+		tx = tx.Where("company_id = ?", f.CompanyID)
+	}
+	if f.Location != "" {
+		tx = tx.Where("LOWER(location) LIKE ? ESCAPE '\\'", "%"+escapeLike(strings.ToLower(f.Location))+"%")
+	}
+	if f.EmploymentType != "" {
+		tx = tx.Where("employment_type = ?", f.EmploymentType)
+	}
+	// A salary filter matches jobs paid in the same currency whose advertised range overlaps the requested one.
+	if f.SalaryCurrency != "" {
+		tx = tx.Where("salary_currency = ?", f.SalaryCurrency)
+	}
+	if f.SalaryMin > 0 {
+		tx = tx.Where("salary_max >= ?", f.SalaryMin)
+	}
+	if f.SalaryMax > 0 {
+		tx = tx.Where("salary_min <= ?", f.SalaryMax)
+	}
+	// A job is posted when it is published, which may be long after its draft was created.
+	if !f.PostedSince.IsZero() {
+		tx = tx.Where("published_at >= ?", f.PostedSince)
+	}
+	return tx
+}
+
+// pageJobs adds the ORDER BY and the keyset condition for the cursor to the query. Jobs are as new as their
+// publication, like for posted_since, and only published jobs are listed so published_at is always set.
+func pageJobs(tx *gorm.DB, sort string, c *jobCursor) *gorm.DB {
+	switch sort {
+	case models.JobSortOldest:
+		if c != nil {
+			tx = tx.Where("(published_at > ? OR (published_at = ? AND id > ?))", c.PublishedAt, c.PublishedAt, c.ID)
+		}
+		return tx.Order("published_at ASC, id ASC")
+	case models.JobSortSalaryDesc:
+		if c != nil {
+			tx = tx.Where("(salary_max < ? OR (salary_max = ? AND id < ?))", c.Salary, c.Salary, c.ID)
+		}
+		return tx.Order("salary_max DESC, id DESC")
+	case models.JobSortSalaryAsc:
+		if c != nil {
+			tx = tx.Where("(salary_max > ? OR (salary_max = ? AND id > ?))", c.Salary, c.Salary, c.ID)
+		}
+		return tx.Order("salary_max ASC, id ASC")
+	default:
+		if c != nil {
+			tx = tx.Where("(published_at < ? OR (published_at = ? AND id < ?))", c.PublishedAt, c.PublishedAt, c.ID)
+		}
+		return tx.Order("published_at DESC, id DESC")
+	}
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"job-portal-api/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SetTOTPSecret stores the secret of an authenticator app the user is enrolling. Two-factor authentication stays
+// off until EnableTOTP. It returns gorm.ErrRecordNotFound when there is no such user or they have it enabled
+// already, so an enabled secret is never replaced.
+func (r *Repo) SetTOTPSecret(ctx context.Context, uid uint, secret string) error {
+	result := r.DB.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND totp_enabled_at IS NULL", uid).
+		Update("totp_secret", secret)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// EnableTOTP turns two-factor authentication on for the user once they proved their app works with the code of
+// the given time step, and replaces their recovery codes with the given hashes.
+func (r *Repo) EnableTOTP(ctx context.Context, uid uint, step int64, codeHashes []string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND totp_enabled_at IS NULL AND totp_secret <> ''", uid).
+			UpdateColumns(map[string]any{"totp_enabled_at": time.Now(), "totp_last_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return replaceRecoveryCodes(tx, uid, codeHashes)
+	})
+}
+
+// DisableTOTP turns two-factor authentication off for the user and forgets their secret and recovery codes.
+func (r *Repo) DisableTOTP(ctx context.Context, uid uint) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", uid).UpdateColumns(map[string]any{
+			"totp_secret":     "",
+			"totp_enabled_at": nil,
+			"totp_last_step":  0,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", uid).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+// UseTOTPStep records that the code of the given time step was used by the user. It reports false when a code of
+// that step or a later one was used before, which makes each code single use even under concurrent logins.
+func (r *Repo) UseTOTPStep(ctx context.Context, uid uint, step int64) (bool, error) {
+	result := r.DB.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", uid, step).
+		UpdateColumn("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UseRecoveryCode marks the unused recovery code of the user with the given hash as used. It returns
+// gorm.ErrRecordNotFound when there is none.
+func (r *Repo) UseRecoveryCode(ctx context.Context, uid uint, codeHash string) error {
+	result := r.DB.WithContext(ctx).Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", uid, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, uid uint, codeHashes []string) error {
+	err := tx.Where("user_id = ?", uid).Delete(&models.RecoveryCode{}).Error
+	if err != nil {
+		return err
+	}
+	codes := make([]models.RecoveryCode, len(codeHashes))
+	for i, h := range codeHashes {
+		codes[i] = models.RecoveryCode{UserID: uid, CodeHash: h}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
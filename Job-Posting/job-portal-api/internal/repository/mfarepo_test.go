@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"job-portal-api/internal/models"
+)
+
+func TestTOTPEnrollment(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	u := createTestUser(t, r, "recruiter", models.RoleRecruiter)
+
+	// Can't be enabled before a secret is set
+	require.ErrorIs(t, r.EnableTOTP(ctx, u.ID, 10, nil), gorm.ErrRecordNotFound)
+
+	require.NoError(t, r.SetTOTPSecret(ctx, u.ID, "FIRST"))
+	// Starting over replaces the secret
+	require.NoError(t, r.SetTOTPSecret(ctx, u.ID, "SECOND"))
+	require.NoError(t, r.EnableTOTP(ctx, u.ID, 10, []string{"hash-1", "hash-2"}))
+
+	stored, err := r.ViewUserById(ctx, u.ID)
+	require.NoError(t, err)
+	require.Equal(t, "SECOND", stored.TOTPSecret)
+	require.NotNil(t, stored.TOTPEnabledAt)
+	require.Equal(t, int64(10), stored.TOTPLastStep)
+
+	// Once enabled, the secret can't be replaced nor enabled again
+	require.ErrorIs(t, r.SetTOTPSecret(ctx, u.ID, "THIRD"), gorm.ErrRecordNotFound)
+	require.ErrorIs(t, r.EnableTOTP(ctx, u.ID, 11, nil), gorm.ErrRecordNotFound)
+
+	require.NoError(t, r.DisableTOTP(ctx, u.ID))
+	stored, err = r.ViewUserById(ctx, u.ID)
+	require.NoError(t, err)
+	require.Empty(t, stored.TOTPSecret)
+	require.Nil(t, stored.TOTPEnabledAt)
+	require.Zero(t, stored.TOTPLastStep)
+	// The recovery codes go with it
+	require.ErrorIs(t, r.UseRecoveryCode(ctx, u.ID, "hash-1"), gorm.ErrRecordNotFound)
+}
+
+func TestUseTOTPStep(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	u := createTestUser(t, r, "recruiter", models.RoleRecruiter)
+	require.NoError(t, r.SetTOTPSecret(ctx, u.ID, "SECRET"))
+	require.NoError(t, r.EnableTOTP(ctx, u.ID, 10, nil))
+
+	// The step used to enable can't be used again, nor an earlier one
+	for _, step := range []int64{9, 10} {
+		ok, err := r.UseTOTPStep(ctx, u.ID, step)
+		require.NoError(t, err)
+		require.False(t, ok, "step %d", step)
+	}
+
+	ok, err := r.UseTOTPStep(ctx, u.ID, 11)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = r.UseTOTPStep(ctx, u.ID, 11)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	u := createTestUser(t, r, "recruiter", models.RoleRecruiter)
+	other := createTestUser(t, r, "other", models.RoleRecruiter)
+	require.NoError(t, r.SetTOTPSecret(ctx, u.ID, "SECRET"))
+	require.NoError(t, r.EnableTOTP(ctx, u.ID, 10, []string{"hash-1", "hash-2"}))
+
+	// Codes belong to their user and can be used once
+	require.ErrorIs(t, r.UseRecoveryCode(ctx, other.ID, "hash-1"), gorm.ErrRecordNotFound)
+	require.NoError(t, r.UseRecoveryCode(ctx, u.ID, "hash-1"))
+	require.ErrorIs(t, r.UseRecoveryCode(ctx, u.ID, "hash-1"), gorm.ErrRecordNotFound)
+	require.ErrorIs(t, r.UseRecoveryCode(ctx, u.ID, "unknown"), gorm.ErrRecordNotFound)
+	require.NoError(t, r.UseRecoveryCode(ctx, u.ID, "hash-2"))
+}
@@ -3,7 +3,8 @@ package repository
 import (
 	"context"
 	"errors"
-	"github.com/golang-jwt/jwt/v5"
+	"time"
+
 	"gorm.io/gorm"
 	"job-portal-api/internal/models"
 )
@@ -16,18 +17,63 @@ type Repo struct {
 
 type UserRepo interface {
 	CreateUser(ctx context.Context, userData models.User) (models.User, error)
-	CheckEmail(ctx context.Context, email string, password string) (jwt.RegisteredClaims, error)
+	ViewUserByEmail(ctx context.Context, email string) (models.User, error)
+	ViewUsersByEmail(ctx context.Context, email string) ([]models.User, error)
+	ViewUserById(ctx context.Context, uid uint) (models.User, error)
+	ViewUserByOIDC(ctx context.Context, issuer string, subject string) (models.User, error)
+	LinkOIDC(ctx context.Context, uid uint, issuer string, subject string) error
+	RecordFailedLogin(ctx context.Context, uid uint, lockUntil func(failures int) time.Time) (models.User, error)
+	ResetFailedLogins(ctx context.Context, uid uint) error
+	MarkEmailVerified(ctx context.Context, uid uint, at time.Time) error
+	UpdatePassword(ctx context.Context, uid uint, passwordHash string) error
+	UpdateUserRole(ctx context.Context, uid uint, role string) (models.User, error)
+	SetTOTPSecret(ctx context.Context, uid uint, secret string) error
+	EnableTOTP(ctx context.Context, uid uint, step int64, codeHashes []string) error
+	DisableTOTP(ctx context.Context, uid uint) error
+	UseTOTPStep(ctx context.Context, uid uint, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, uid uint, codeHash string) error
+
+	CreateRefreshToken(ctx context.Context, token models.RefreshToken) (models.RefreshToken, error)
+	FindRefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, oldID uint, newToken models.RefreshToken) (models.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, userID uint, tokenHash string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uint) error
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	CreateUserToken(ctx context.Context, token models.UserToken) (models.UserToken, error)
+	UseUserToken(ctx context.Context, tokenHash string, purpose string) (models.UserToken, error)
 
 	CreateCompany(ctx context.Context, companyData models.Companies) (models.Companies, error)
 	ViewCompanies(ctx context.Context) ([]models.Companies, error)
 	ViewCompanyById(ctx context.Context, cid uint) ([]models.Companies, error)
+	UpdateCompany(ctx context.Context, company models.Companies, fields []string) (models.Companies, error)
+	DeleteCompany(ctx context.Context, cid uint) error
+	RestoreCompany(ctx context.Context, cid uint) (models.Companies, error)
+	CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, error)
+	ViewAPIKeysByCompany(ctx context.Context, cid uint) ([]models.APIKey, error)
+	FindAPIKey(ctx context.Context, keyHash string) (models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, cid uint, kid uint) error
+	TouchAPIKey(ctx context.Context, kid uint, at time.Time, precision time.Duration) error
 
 	CreateJob(ctx context.Context, jobData models.Job) (models.Job, error)
 	FindJob(ctx context.Context, cid uint64) ([]models.Job, error)
-	FindAllJobs(ctx context.Context) ([]models.Job, error)
+	FindAllJobs(ctx context.Context, f models.JobFilter) (models.JobPage, error)
+	SearchJobs(ctx context.Context, query string, limit int, offset int) (models.SearchPage, error)
 	ViewJobDetailsBy(ctx context.Context, jid uint64) (models.Job, error)
 	ViewJobByCompanyId(ctx context.Context, id uint) ([]models.Job, error)
-	AutoMigrate() error
+	TransitionJob(ctx context.Context, jid uint, from []string, changes map[string]interface{}) (models.Job, error)
+	ExpireJobs(ctx context.Context, now time.Time) (int64, error)
+	UpdateJob(ctx context.Context, job models.Job, fields []string) (models.Job, error)
+	DeleteJob(ctx context.Context, jid uint) error
+	RestoreJob(ctx context.Context, jid uint) (models.Job, error)
+
+	CreateApplication(ctx context.Context, application models.Application) (models.Application, error)
+	HasApplied(ctx context.Context, jid uint, uid uint) (bool, error)
+	ViewApplicationById(ctx context.Context, aid uint) (models.Application, error)
+	ViewApplicationsByUser(ctx context.Context, uid uint) ([]models.Application, error)
+	ViewApplicationsByJob(ctx context.Context, jid uint) ([]models.Application, error)
+	TransitionApplication(ctx context.Context, change models.ApplicationStatusChange) (models.Application, error)
+	ViewApplicationTimeline(ctx context.Context, aid uint) ([]models.ApplicationStatusChange, error)
 }
 
 func NewRepository(db *gorm.DB) (UserRepo, error) {
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"job-portal-api/internal/config"
+	"job-portal-api/internal/database"
+	"job-portal-api/internal/migrate"
+	"job-portal-api/internal/models"
+)
+
+// newTestRepo returns a Repo backed by a fresh in-memory SQLite database with every migration applied.
+func newTestRepo(t *testing.T) *Repo {
+	t.Helper()
+	db, err := database.Open(config.DB{Driver: config.DriverSQLite, DSN: ":memory:"})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	m, err := migrate.New(sqlDB, config.DriverSQLite)
+	require.NoError(t, err)
+	_, err = m.Up(context.Background())
+	require.NoError(t, err)
+
+	return &Repo{DB: db}
+}
+
+func createTestUser(t *testing.T, r *Repo, name string, role string) models.User {
+	t.Helper()
+	u, err := r.CreateUser(context.Background(), models.User{Name: name, Email: name + "@email.com", Role: role})
+	require.NoError(t, err)
+	return u
+}
+
+func createTestCompany(t *testing.T, r *Repo, owner models.User, name string) models.Companies {
+	t.Helper()
+	c, err := r.CreateCompany(context.Background(), models.Companies{CompanyName: name, UserId: owner.ID, Location: "banglore"})
+	require.NoError(t, err)
+	return c
+}
+
+// createTestJob stores a published job that expires in a day.
+func createTestJob(t *testing.T, r *Repo, company models.Companies, job models.Job) models.Job {
+	t.Helper()
+	expiresAt := time.Now().Add(24 * time.Hour)
+	job.CompanyID = company.ID
+	if job.Status == "" {
+		job.Status = models.JobStatusPublished
+		job.ExpiresAt = &expiresAt
+	}
+	if job.Status == models.JobStatusPublished && job.PublishedAt == nil {
+		now := time.Now()
+		job.PublishedAt = &now
+	}
+	job, err := r.CreateJob(context.Background(), job)
+	require.NoError(t, err)
+	return job
+}
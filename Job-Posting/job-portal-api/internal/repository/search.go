@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"html"
+	"job-portal-api/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The search vectors are built from the same expressions as the GIN indexes created by the search_indexes
+// migration, so Postgres can use the indexes. Titles and company names weigh more than descriptions when ranking.
+const (
+	jobSearchVector = `(setweight(to_tsvector('english', coalesce(j.title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(j.description, '')), 'B'))`
+	companySearchVector = `setweight(to_tsvector('english', coalesce(c.company_name, '')), 'A')`
+
+	searchFrom = `FROM jobs j
+		JOIN companies c ON c.id = j.company_id AND c.deleted_at IS NULL,
+		websearch_to_tsquery('english', @query) q
+		WHERE j.deleted_at IS NULL
+		AND j.status = 'published' AND (j.expires_at IS NULL OR j.expires_at > now())
+		AND (` + jobSearchVector + ` @@ q OR ` + companySearchVector + ` @@ q)`
+
+	// ts_headline marks matches with control characters rather than <mark> tags, as it leaves the rest of the
+	// text as written by the recruiter. The characters are taken out of the text before it is searched, so only
+	// ts_headline puts them in, and the text is escaped for HTML before they become tags.
+	highlightStart   = "\x02"
+	highlightStop    = "\x03"
+	highlightOptions = "StartSel=" + highlightStart + ", StopSel=" + highlightStop
+	titleOptions     = "HighlightAll=true, " + highlightOptions
+	snippetOptions   = highlightOptions + ", MaxFragments=2, MaxWords=30, MinWords=10"
+)
+
+var stripHighlightMarks = strings.NewReplacer(highlightStart, "", highlightStop, "")
+
+// highlight escapes a ts_headline result for HTML and marks its matches with <mark> tags. Marks that would open a
+// tag twice or close one that isn't open are dropped, so the tags are always balanced.
+func highlight(headline string) string {
+	var b strings.Builder
+	open := false
+	for {
+		i := strings.IndexAny(headline, highlightStart+highlightStop)
+		if i < 0 {
+			b.WriteString(html.EscapeString(headline))
+			break
+		}
+		b.WriteString(html.EscapeString(headline[:i]))
+		switch mark := headline[i : i+1]; {
+		case mark == highlightStart && !open:
+			b.WriteString("<mark>")
+			open = true
+		case mark == highlightStop && open:
+			b.WriteString("</mark>")
+			open = false
+		}
+		headline = headline[i+1:]
+	}
+	if open {
+		b.WriteString("</mark>")
+	}
+	return b.String()
+}
+
+// jobSearchRow is a row of the search query: the job columns plus the computed ones.
+type jobSearchRow struct {
+	models.Job
+	CompanyName    string
+	Rank           float64
+	TitleHighlight string
+	Snippet        string
+}
+
+// SearchJobs runs a Postgres full-text search over job titles, descriptions and company names.
+// The query uses web search syntax ("quoted phrases", OR, -excluded). Other databases get searchJobsLike.
+func (r *Repo) SearchJobs(ctx context.Context, query string, limit int, offset int) (models.SearchPage, error) {
+	if r.DB.Dialector.Name() != "postgres" {
+		return r.searchJobsLike(ctx, query, limit, offset)
+	}
+
+	args := map[string]interface{}{
+		"query":          query,
+		"limit":          limit,
+		"offset":         offset,
+		"titleOptions":   titleOptions,
+		"snippetOptions": snippetOptions,
+		"highlightMarks": highlightStart + highlightStop,
+	}
+
+	var total int64
+	result := r.DB.WithContext(ctx).Raw(`SELECT count(*) `+searchFrom, args).Scan(&total)
+	if result.Error != nil {
+		return models.SearchPage{}, result.Error
+	}
+
+	var rows []jobSearchRow
+	result = r.DB.WithContext(ctx).Raw(`SELECT j.*, c.company_name,
+		ts_rank(`+jobSearchVector+` || `+companySearchVector+`, q) AS rank,
+		ts_headline('english', translate(j.title, @highlightMarks, ''), q, @titleOptions) AS title_highlight,
+		ts_headline('english', translate(coalesce(j.description, ''), @highlightMarks, ''), q, @snippetOptions) AS snippet
+		`+searchFrom+`
+		ORDER BY rank DESC, j.id DESC
+		LIMIT @limit OFFSET @offset`, args).Scan(&rows)
+	if result.Error != nil {
+		return models.SearchPage{}, result.Error
+	}
+
+	page := models.SearchPage{Results: make([]models.SearchResult, 0, len(rows)), Total: total}
+	for _, row := range rows {
+		page.Results = append(page.Results, models.SearchResult{
+			Job:            row.Job,
+			CompanyName:    row.CompanyName,
+			Rank:           row.Rank,
+			TitleHighlight: highlight(row.TitleHighlight),
+			Snippet:        highlight(row.Snippet),
+		})
+	}
+	return page, nil
+}
+
+// snippetWords is the number of words of the description searchJobsLike returns as the snippet.
+const snippetWords = 30
+
+// searchJobsLike is the SearchJobs fallback for databases without full-text search, such as SQLite in
+// development. Every word of the query must appear in the title, description or company name, words starting
+// with - must not. Results are newest first with a rank of 0 and nothing highlighted, but escaped for HTML like
+// those of SearchJobs.
+func (r *Repo) searchJobsLike(ctx context.Context, query string, limit int, offset int) (models.SearchPage, error) {
+	tx := r.DB.WithContext(ctx).Table("jobs j").
+		Joins("JOIN companies c ON c.id = j.company_id AND c.deleted_at IS NULL").
+		Where("j.deleted_at IS NULL AND j.status = ? AND (j.expires_at IS NULL OR j.expires_at > ?)",
+			models.JobStatusPublished, time.Now())
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		word = strings.Trim(word, `"`)
+		exclude := strings.HasPrefix(word, "-")
+		word = strings.TrimPrefix(word, "-")
+		if word == "" || word == "or" {
+			continue
+		}
+		kw := "%" + escapeLike(word) + "%"
+		cond := "(LOWER(j.title) LIKE ? ESCAPE '\\' OR LOWER(j.description) LIKE ? ESCAPE '\\' OR LOWER(c.company_name) LIKE ? ESCAPE '\\')"
+		if exclude {
+			tx = tx.Where("NOT "+cond, kw, kw, kw)
+		} else {
+			tx = tx.Where(cond, kw, kw, kw)
+		}
+	}
+
+	var total int64
+	result := tx.Session(&gorm.Session{}).Count(&total)
+	if result.Error != nil {
+		return models.SearchPage{}, result.Error
+	}
+
+	var rows []jobSearchRow
+	result = tx.Select("j.*, c.company_name").Order("j.created_at DESC, j.id DESC").
+		Limit(limit).Offset(offset).Scan(&rows)
+	if result.Error != nil {
+		return models.SearchPage{}, result.Error
+	}
+
+	page := models.SearchPage{Results: make([]models.SearchResult, 0, len(rows)), Total: total}
+	for _, row := range rows {
+		snippet := strings.Fields(row.Description)
+		if len(snippet) > snippetWords {
+			snippet = snippet[:snippetWords]
+		}
+		page.Results = append(page.Results, models.SearchResult{
+			Job:            row.Job,
+			CompanyName:    row.CompanyName,
+			TitleHighlight: html.EscapeString(stripHighlightMarks.Replace(row.Title)),
+			Snippet:        html.EscapeString(stripHighlightMarks.Replace(strings.Join(snippet, " "))),
+		})
+	}
+	return page, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"job-portal-api/internal/models"
+)
+
+func TestSearchJobsLike(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	owner := createTestUser(t, r, "owner", models.RoleRecruiter)
+	infy := createTestCompany(t, r, owner, "Infosys")
+	tcs := createTestCompany(t, r, owner, "TCS")
+
+	golang := createTestJob(t, r, infy, models.Job{Title: "Golang Developer", Description: "Build APIs with Go and Postgres"})
+	rust := createTestJob(t, r, tcs, models.Job{Title: "Rust Developer", Description: "Systems programming"})
+	createTestJob(t, r, tcs, models.Job{Title: "Golang Intern", Status: models.JobStatusDraft})
+
+	tt := []struct {
+		query string
+		want  []uint
+	}{
+		{query: "golang", want: []uint{golang.ID}},
+		{query: "developer", want: []uint{rust.ID, golang.ID}},
+		{query: "developer -rust", want: []uint{golang.ID}},
+		{query: "tcs", want: []uint{rust.ID}},
+		{query: `"postgres"`, want: []uint{golang.ID}},
+		{query: "java", want: nil},
+	}
+	for _, tc := range tt {
+		t.Run(tc.query, func(t *testing.T) {
+			page, err := r.SearchJobs(ctx, tc.query, 10, 0)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(tc.want)), page.Total)
+
+			var got []uint
+			for _, res := range page.Results {
+				got = append(got, res.Job.ID)
+			}
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	page, err := r.SearchJobs(ctx, "developer", 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), page.Total)
+	require.Len(t, page.Results, 1)
+	require.Equal(t, "Infosys", page.Results[0].CompanyName)
+	require.Equal(t, "Golang Developer", page.Results[0].TitleHighlight)
+}
+
+func TestSearchJobsEscapesHTML(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	owner := createTestUser(t, r, "owner", models.RoleRecruiter)
+	infy := createTestCompany(t, r, owner, "Infosys")
+	// The marks of ts_headline are dropped from the text of the recruiter
+	createTestJob(t, r, infy, models.Job{Title: "Golang <b>Developer</b>" + highlightStop, Description: highlightStart + `<img src=x onerror="alert(1)">`})
+
+	page, err := r.SearchJobs(ctx, "golang", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, page.Results, 1)
+	require.Equal(t, "Golang &lt;b&gt;Developer&lt;/b&gt;", page.Results[0].TitleHighlight)
+	require.Equal(t, "&lt;img src=x onerror=&#34;alert(1)&#34;&gt;", page.Results[0].Snippet)
+}
+
+func TestHighlight(t *testing.T) {
+	headline := highlightStart + "Golang" + highlightStop + ` <script>alert("x")</script> & ` + highlightStart + "Go" + highlightStop
+	require.Equal(t, `<mark>Golang</mark> &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &amp; <mark>Go</mark>`, highlight(headline))
+
+	// Marks a recruiter slipped into the text never unbalance the tags
+	headline = "Rust" + highlightStop + " " + highlightStart + highlightStart + "Golang" + highlightStop + highlightStop + " " + highlightStart + "Go"
+	require.Equal(t, `Rust <mark>Golang</mark> <mark>Go</mark>`, highlight(headline))
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"job-portal-api/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrTokenAlreadyUsed is returned by RotateRefreshToken when the refresh token was revoked concurrently.
+var ErrTokenAlreadyUsed = errors.New("refresh token already used")
+
+func (r *Repo) CreateRefreshToken(ctx context.Context, token models.RefreshToken) (models.RefreshToken, error) {
+	result := r.DB.WithContext(ctx).Create(&token)
+	if result.Error != nil {
+		return models.RefreshToken{}, result.Error
+	}
+	return token, nil
+}
+
+func (r *Repo) FindRefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	var token models.RefreshToken
+	result := r.DB.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token)
+	if result.Error != nil {
+		return models.RefreshToken{}, result.Error
+	}
+	return token, nil
+}
+
+// RotateRefreshToken revokes the old refresh token and stores its replacement in a single transaction.
+// If the old token was already revoked it returns ErrTokenAlreadyUsed and stores nothing.
+func (r *Repo) RotateRefreshToken(ctx context.Context, oldID uint, newToken models.RefreshToken) (models.RefreshToken, error) {
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", oldID).
+			Update("revoked_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTokenAlreadyUsed
+		}
+		return tx.Create(&newToken).Error
+	})
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	return newToken, nil
+}
+
+// RevokeRefreshToken revokes a refresh token of the user. It returns gorm.ErrRecordNotFound when the user has no
+// such token or it is revoked already, so no one can revoke the tokens of someone else.
+func (r *Repo) RevokeRefreshToken(ctx context.Context, userID uint, tokenHash string) error {
+	result := r.DB.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND token_hash = ? AND revoked_at IS NULL", userID, tokenHash).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RevokeUserRefreshTokens revokes every refresh token of a user, e.g. when token reuse is detected.
+func (r *Repo) RevokeUserRefreshTokens(ctx context.Context, userID uint) error {
+	return r.DB.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *Repo) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	token := models.RevokedToken{JTI: jti, ExpiresAt: expiresAt}
+	return r.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&token).Error
+}
+
+func (r *Repo) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	result := r.DB.WithContext(ctx).Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+func (r *Repo) CreateUserToken(ctx context.Context, token models.UserToken) (models.UserToken, error) {
+	result := r.DB.WithContext(ctx).Create(&token)
+	if result.Error != nil {
+		return models.UserToken{}, result.Error
+	}
+	return token, nil
+}
+
+// UseUserToken marks the token with the given hash and purpose as used and returns it. It returns
+// gorm.ErrRecordNotFound when there is no such token, and ErrTokenAlreadyUsed when it was used before, even
+// concurrently. Whether the token has expired is left to the caller.
+func (r *Repo) UseUserToken(ctx context.Context, tokenHash string, purpose string) (models.UserToken, error) {
+	var token models.UserToken
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("token_hash = ? AND purpose = ?", tokenHash, purpose).First(&token).Error
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		result := tx.Model(&models.UserToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTokenAlreadyUsed
+		}
+		token.UsedAt = &now
+		return nil
+	})
+	if err != nil {
+		return models.UserToken{}, err
+	}
+	return token, nil
+}
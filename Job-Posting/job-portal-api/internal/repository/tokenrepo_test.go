@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"job-portal-api/internal/models"
+)
+
+func TestRotateRefreshToken(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	u := createTestUser(t, r, "candidate", models.RoleCandidate)
+	expiresAt := time.Now().Add(time.Hour)
+
+	old, err := r.CreateRefreshToken(ctx, models.RefreshToken{UserID: u.ID, TokenHash: "old", ExpiresAt: expiresAt})
+	require.NoError(t, err)
+
+	_, err = r.RotateRefreshToken(ctx, old.ID, models.RefreshToken{UserID: u.ID, TokenHash: "new", ExpiresAt: expiresAt})
+	require.NoError(t, err)
+
+	// Reusing the old token fails and stores nothing
+	_, err = r.RotateRefreshToken(ctx, old.ID, models.RefreshToken{UserID: u.ID, TokenHash: "other", ExpiresAt: expiresAt})
+	require.ErrorIs(t, err, ErrTokenAlreadyUsed)
+	_, err = r.FindRefreshToken(ctx, "other")
+	require.Error(t, err)
+
+	require.NoError(t, r.RevokeUserRefreshTokens(ctx, u.ID))
+	token, err := r.FindRefreshToken(ctx, "new")
+	require.NoError(t, err)
+	require.NotNil(t, token.RevokedAt)
+}
+
+func TestRevokeRefreshToken(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	u := createTestUser(t, r, "candidate", models.RoleCandidate)
+	other := createTestUser(t, r, "other", models.RoleCandidate)
+	_, err := r.CreateRefreshToken(ctx, models.RefreshToken{UserID: u.ID, TokenHash: "hash", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	// Only the owner can revoke the token
+	require.ErrorIs(t, r.RevokeRefreshToken(ctx, other.ID, "hash"), gorm.ErrRecordNotFound)
+	token, err := r.FindRefreshToken(ctx, "hash")
+	require.NoError(t, err)
+	require.Nil(t, token.RevokedAt)
+
+	require.NoError(t, r.RevokeRefreshToken(ctx, u.ID, "hash"))
+	token, err = r.FindRefreshToken(ctx, "hash")
+	require.NoError(t, err)
+	require.NotNil(t, token.RevokedAt)
+
+	require.ErrorIs(t, r.RevokeRefreshToken(ctx, u.ID, "hash"), gorm.ErrRecordNotFound)
+}
+
+func TestRevokeToken(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	revoked, err := r.IsTokenRevoked(ctx, "jti")
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	require.NoError(t, r.RevokeToken(ctx, "jti", time.Now().Add(time.Hour)))
+	require.NoError(t, r.RevokeToken(ctx, "jti", time.Now().Add(time.Hour)), "revoking twice is not an error")
+
+	revoked, err = r.IsTokenRevoked(ctx, "jti")
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
+func TestUseUserToken(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	u := createTestUser(t, r, "candidate", models.RoleCandidate)
+
+	_, err := r.CreateUserToken(ctx, models.UserToken{
+		UserID:    u.ID,
+		Purpose:   models.TokenPurposeVerifyEmail,
+		TokenHash: "verify",
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	// A token only works for its own purpose
+	_, err = r.UseUserToken(ctx, "verify", models.TokenPurposeResetPassword)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	token, err := r.UseUserToken(ctx, "verify", models.TokenPurposeVerifyEmail)
+	require.NoError(t, err)
+	require.Equal(t, u.ID, token.UserID)
+	require.NotNil(t, token.UsedAt)
+
+	_, err = r.UseUserToken(ctx, "verify", models.TokenPurposeVerifyEmail)
+	require.ErrorIs(t, err, ErrTokenAlreadyUsed)
+}
@@ -2,43 +2,161 @@ package repository
 
 import (
 	"context"
-	"errors"
-	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
+	"fmt"
 	"job-portal-api/internal/models"
-	"strconv"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
 )
 
 func (r *Repo) CreateUser(ctx context.Context, UserDetails models.User) (models.User, error) {
-	result := r.DB.Create(&UserDetails)
+	result := r.DB.WithContext(ctx).Create(&UserDetails)
 	if result.Error != nil {
 		log.Info().Err(result.Error).Send()
-		return models.User{}, errors.New("could not create the user")
+		return models.User{}, fmt.Errorf("could not create the user: %w", result.Error)
 	}
 	return UserDetails, nil
 }
-func (r *Repo) CheckEmail(ctx context.Context, email string, password string) (jwt.RegisteredClaims, error) {
+
+// ViewUserByEmail returns the user with the given email, or gorm.ErrRecordNotFound.
+func (r *Repo) ViewUserByEmail(ctx context.Context, email string) (models.User, error) {
+	var u models.User
+	tx := r.DB.WithContext(ctx).Where("email = ?", email).First(&u)
+	if tx.Error != nil {
+		return models.User{}, tx.Error
+	}
+	return u, nil
+}
+
+// ViewUsersByEmail returns the users whose email matches the given one, ignoring case. The email must be lower
+// case. Addresses aren't unique, so there may be more than one.
+func (r *Repo) ViewUsersByEmail(ctx context.Context, email string) ([]models.User, error) {
+	var users []models.User
+	tx := r.DB.WithContext(ctx).Where("LOWER(email) = ?", email).Order("id").Find(&users)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return users, nil
+}
+
+// ViewUserByOIDC returns the user linked to the given subject of the single sign-on provider, or
+// gorm.ErrRecordNotFound.
+func (r *Repo) ViewUserByOIDC(ctx context.Context, issuer string, subject string) (models.User, error) {
 	var u models.User
-	tx := r.DB.Where("email = ?", email).First(&u)
+	tx := r.DB.WithContext(ctx).Where("oidc_issuer = ? AND oidc_subject = ?", issuer, subject).First(&u)
 	if tx.Error != nil {
-		return jwt.RegisteredClaims{}, tx.Error
+		return models.User{}, tx.Error
+	}
+	return u, nil
+}
+
+// LinkOIDC links the user to the given subject of the single sign-on provider. Only users who verified their email
+// can be linked, as otherwise whoever registered with an address they don't own would share the account with its
+// owner. It returns gorm.ErrRecordNotFound when there is no such user, their email isn't verified or they are linked
+// already, so a link is never replaced.
+func (r *Repo) LinkOIDC(ctx context.Context, uid uint, issuer string, subject string) error {
+	result := r.DB.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND oidc_subject IS NULL AND email_verified_at IS NOT NULL", uid).
+		UpdateColumns(map[string]any{"oidc_issuer": issuer, "oidc_subject": subject})
+	if result.Error != nil {
+		return result.Error
 	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
 
-	// We check if the provided password matches the hashed password in the database.
-	err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
+// RecordFailedLogin counts a failed login of the user and locks the account until lockUntil(failures), where
+// failures is the count including this one. A zero time leaves the lock as it is. The counter is incremented in the
+// database so that concurrent failures are all counted.
+func (r *Repo) RecordFailedLogin(ctx context.Context, uid uint, lockUntil func(failures int) time.Time) (models.User, error) {
+	var u models.User
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", uid).
+			UpdateColumn("failed_logins", gorm.Expr("failed_logins + 1")).Error
+		if err != nil {
+			return err
+		}
+		err = tx.First(&u, uid).Error
+		if err != nil {
+			return err
+		}
+
+		until := lockUntil(u.FailedLogins)
+		if until.IsZero() {
+			return nil
+		}
+		u.LockedUntil = &until
+		return tx.Model(&u).UpdateColumn("locked_until", until).Error
+	})
 	if err != nil {
-		return jwt.RegisteredClaims{}, err
+		return models.User{}, fmt.Errorf("recording failed login: %w", err)
 	}
-	c := jwt.RegisteredClaims{
-		Issuer:    "jobportal project",
-		Subject:   strconv.FormatUint(uint64(u.ID), 10),
-		Audience:  jwt.ClaimStrings{"companies"},
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	return u, nil
+}
+
+// ResetFailedLogins clears the failed login count and the lock of the user after a successful login.
+func (r *Repo) ResetFailedLogins(ctx context.Context, uid uint) error {
+	return r.DB.WithContext(ctx).Model(&models.User{}).Where("id = ?", uid).
+		UpdateColumns(map[string]any{"failed_logins": 0, "locked_until": nil}).Error
+}
+
+// MarkEmailVerified records that the user proved they own their email address. A user verified before keeps the
+// original time.
+func (r *Repo) MarkEmailVerified(ctx context.Context, uid uint, at time.Time) error {
+	return r.DB.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND email_verified_at IS NULL", uid).
+		Update("email_verified_at", at).Error
+}
+
+// UpdatePassword replaces the password hash of the user. As the old password may have leaked, the account is
+// unlocked and every session and outstanding reset token of the user is revoked in the same transaction.
+func (r *Repo) UpdatePassword(ctx context.Context, uid uint, passwordHash string) error {
+	now := time.Now()
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).Where("id = ?", uid).UpdateColumns(map[string]any{
+			"password_hash": passwordHash,
+			"failed_logins": 0,
+			"locked_until":  nil,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		err := tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", uid).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&models.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", uid, models.TokenPurposeResetPassword).
+			Update("used_at", now).Error
+	})
+}
+
+func (r *Repo) ViewUserById(ctx context.Context, uid uint) (models.User, error) {
+	var u models.User
+	tx := r.DB.WithContext(ctx).First(&u, uid)
+	if tx.Error != nil {
+		return models.User{}, tx.Error
 	}
-	return c, nil
+	return u, nil
+}
 
+// UpdateUserRole changes the role of the user and returns them. It returns gorm.ErrRecordNotFound when there is no
+// such user.
+func (r *Repo) UpdateUserRole(ctx context.Context, uid uint, role string) (models.User, error) {
+	result := r.DB.WithContext(ctx).Model(&models.User{}).Where("id = ?", uid).Update("role", role)
+	if result.Error != nil {
+		return models.User{}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return models.User{}, gorm.ErrRecordNotFound
+	}
+	return r.ViewUserById(ctx, uid)
 }
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"job-portal-api/internal/models"
+)
+
+func TestViewUserByEmail(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	u, err := r.CreateUser(ctx, models.User{Name: "satyam", Email: "satyam@email.com", PasswordHash: "hash", Role: models.RoleRecruiter})
+	require.NoError(t, err)
+
+	found, err := r.ViewUserByEmail(ctx, "satyam@email.com")
+	require.NoError(t, err)
+	require.Equal(t, u.ID, found.ID)
+	require.Equal(t, models.RoleRecruiter, found.Role)
+	require.Equal(t, "hash", found.PasswordHash)
+
+	_, err = r.ViewUserByEmail(ctx, "nobody@email.com")
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// Names are unique
+	_, err = r.CreateUser(ctx, models.User{Name: "satyam", Email: "other@email.com", PasswordHash: "hash"})
+	require.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+}
+
+func TestViewUsersByEmail(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	u, err := r.CreateUser(ctx, models.User{Name: "satyam", Email: "Satyam@Email.com"})
+	require.NoError(t, err)
+	found, err := r.ViewUsersByEmail(ctx, "satyam@email.com")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, u.ID, found[0].ID)
+
+	// Addresses aren't unique
+	_, err = r.CreateUser(ctx, models.User{Name: "other", Email: "satyam@email.com"})
+	require.NoError(t, err)
+	found, err = r.ViewUsersByEmail(ctx, "satyam@email.com")
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+
+	found, err = r.ViewUsersByEmail(ctx, "nobody@email.com")
+	require.NoError(t, err)
+	require.Empty(t, found)
+}
+
+func TestFailedLogins(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	u := createTestUser(t, r, "satyam", models.RoleCandidate)
+
+	lockAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	lockUntil := func(failures int) time.Time {
+		if failures < 2 {
+			return time.Time{}
+		}
+		return lockAt
+	}
+
+	// The first failure doesn't lock the account
+	got, err := r.RecordFailedLogin(ctx, u.ID, lockUntil)
+	require.NoError(t, err)
+	require.Equal(t, 1, got.FailedLogins)
+	require.Nil(t, got.LockedUntil)
+
+	got, err = r.RecordFailedLogin(ctx, u.ID, lockUntil)
+	require.NoError(t, err)
+	require.Equal(t, 2, got.FailedLogins)
+	require.NotNil(t, got.LockedUntil)
+
+	stored, err := r.ViewUserById(ctx, u.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, stored.FailedLogins)
+	require.NotNil(t, stored.LockedUntil)
+	require.True(t, lockAt.Equal(*stored.LockedUntil))
+
+	require.NoError(t, r.ResetFailedLogins(ctx, u.ID))
+	stored, err = r.ViewUserById(ctx, u.ID)
+	require.NoError(t, err)
+	require.Zero(t, stored.FailedLogins)
+	require.Nil(t, stored.LockedUntil)
+}
+
+func TestMarkEmailVerified(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	u := createTestUser(t, r, "candidate", models.RoleCandidate)
+	require.Nil(t, u.EmailVerifiedAt)
+
+	first := time.Now().Add(-time.Hour)
+	require.NoError(t, r.MarkEmailVerified(ctx, u.ID, first))
+	// Verifying again keeps the original time
+	require.NoError(t, r.MarkEmailVerified(ctx, u.ID, time.Now()))
+
+	stored, err := r.ViewUserById(ctx, u.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.EmailVerifiedAt)
+	require.True(t, first.Equal(*stored.EmailVerifiedAt))
+}
+
+func TestUpdatePassword(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	u := createTestUser(t, r, "candidate", models.RoleCandidate)
+	other := createTestUser(t, r, "other", models.RoleCandidate)
+	expiresAt := time.Now().Add(time.Hour)
+
+	_, err := r.RecordFailedLogin(ctx, u.ID, func(int) time.Time { return expiresAt })
+	require.NoError(t, err)
+	for _, rt := range []models.RefreshToken{
+		{UserID: u.ID, TokenHash: "session", ExpiresAt: expiresAt},
+		{UserID: other.ID, TokenHash: "other session", ExpiresAt: expiresAt},
+	} {
+		_, err = r.CreateRefreshToken(ctx, rt)
+		require.NoError(t, err)
+	}
+	for _, ut := range []models.UserToken{
+		{UserID: u.ID, Purpose: models.TokenPurposeResetPassword, TokenHash: "reset", ExpiresAt: expiresAt},
+		{UserID: u.ID, Purpose: models.TokenPurposeVerifyEmail, TokenHash: "verify", ExpiresAt: expiresAt},
+	} {
+		_, err = r.CreateUserToken(ctx, ut)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, r.UpdatePassword(ctx, u.ID, "new hash"))
+
+	stored, err := r.ViewUserById(ctx, u.ID)
+	require.NoError(t, err)
+	require.Equal(t, "new hash", stored.PasswordHash)
+	require.Zero(t, stored.FailedLogins)
+	require.Nil(t, stored.LockedUntil)
+
+	// The sessions and reset links of the user are revoked, nobody else's
+	rt, err := r.FindRefreshToken(ctx, "session")
+	require.NoError(t, err)
+	require.NotNil(t, rt.RevokedAt)
+	rt, err = r.FindRefreshToken(ctx, "other session")
+	require.NoError(t, err)
+	require.Nil(t, rt.RevokedAt)
+	_, err = r.UseUserToken(ctx, "reset", models.TokenPurposeResetPassword)
+	require.ErrorIs(t, err, ErrTokenAlreadyUsed)
+	_, err = r.UseUserToken(ctx, "verify", models.TokenPurposeVerifyEmail)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, r.UpdatePassword(ctx, 9999, "hash"), gorm.ErrRecordNotFound)
+}
+
+func TestLinkOIDC(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+	u := createTestUser(t, r, "recruiter", models.RoleRecruiter)
+	other := createTestUser(t, r, "other", models.RoleRecruiter)
+
+	_, err := r.ViewUserByOIDC(ctx, "https://idp.example", "sub-1")
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// Only users who verified their email can be linked
+	require.ErrorIs(t, r.LinkOIDC(ctx, u.ID, "https://idp.example", "sub-1"), gorm.ErrRecordNotFound)
+	require.NoError(t, r.MarkEmailVerified(ctx, u.ID, time.Now()))
+	require.NoError(t, r.MarkEmailVerified(ctx, other.ID, time.Now()))
+
+	require.NoError(t, r.LinkOIDC(ctx, u.ID, "https://idp.example", "sub-1"))
+	found, err := r.ViewUserByOIDC(ctx, "https://idp.example", "sub-1")
+	require.NoError(t, err)
+	require.Equal(t, u.ID, found.ID)
+
+	// A link is never replaced, and a subject is linked to a single user
+	require.ErrorIs(t, r.LinkOIDC(ctx, u.ID, "https://idp.example", "sub-2"), gorm.ErrRecordNotFound)
+	require.Error(t, r.LinkOIDC(ctx, other.ID, "https://idp.example", "sub-1"))
+
+	// Subjects are only unique per issuer
+	_, err = r.ViewUserByOIDC(ctx, "https://other.example", "sub-1")
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	require.NoError(t, r.LinkOIDC(ctx, other.ID, "https://other.example", "sub-1"))
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"job-portal-api/internal/config"
+	"job-portal-api/internal/mail"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/repository"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Accounts configures the email verification, password reset, two-factor and single sign-on login flows. The URLs
+// are the links put in the emails, with config.TokenPlaceholder replaced by the token.
+type Accounts struct {
+	Mailer           mail.Mailer
+	Tokens           *TokenSigner
+	VerifyEmailURL   string
+	ResetPasswordURL string
+	VerifyEmailTTL   time.Duration
+	ResetPasswordTTL time.Duration
+	TOTPIssuer       string
+	MFAChallengeTTL  time.Duration
+	SSO              SSO
+}
+
+// SendVerificationEmail emails the user a new link to verify their address. Links sent before stay valid until
+// they expire.
+func (s *Store) SendVerificationEmail(ctx context.Context, userID string) error {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parsing user id %q: %w", userID, err)
+	}
+	u, err := s.UserRepo.ViewUserById(ctx, uint(uid))
+	if err != nil {
+		return err
+	}
+	if u.EmailVerifiedAt != nil {
+		return errAlreadyVerified
+	}
+	return s.sendVerificationEmail(ctx, u)
+}
+
+// VerifyEmail redeems a token emailed by SendVerificationEmail and marks the address of its user as verified.
+func (s *Store) VerifyEmail(ctx context.Context, token string) error {
+	t, err := s.redeemToken(ctx, token, models.TokenPurposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+	return s.UserRepo.MarkEmailVerified(ctx, t.UserID, time.Now())
+}
+
+// RequestPasswordReset emails a link to reset the password to the user with the given email. Whether there is
+// such a user isn't revealed: an unknown email is not an error.
+func (s *Store) RequestPasswordReset(ctx context.Context, email string) error {
+	u, err := s.UserRepo.ViewUserByEmail(ctx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ttl := s.Accounts.ResetPasswordTTL
+	token, err := s.issueToken(ctx, u.ID, models.TokenPurposeResetPassword, ttl)
+	if err != nil {
+		return err
+	}
+	return s.Accounts.Mailer.Send(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Someone asked to reset the password of your account. Open the link below to choose a new one, "+
+			"it expires in %s.\n\n%s\n\n"+
+			"If it wasn't you, ignore this email and your password stays the same.\n",
+			u.Name, humanDuration(ttl), link(s.Accounts.ResetPasswordURL, token)),
+	})
+}
+
+// ResetPassword redeems a token emailed by RequestPasswordReset and replaces the password of its user. Every
+// session of the user is revoked and the account is unlocked.
+func (s *Store) ResetPassword(ctx context.Context, token string, password string) error {
+	t, err := s.redeemToken(ctx, token, models.TokenPurposeResetPassword)
+	if err != nil {
+		return err
+	}
+
+	hashedPass, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("generating password hash: %w", err)
+	}
+	return s.UserRepo.UpdatePassword(ctx, t.UserID, string(hashedPass))
+}
+
+func (s *Store) sendVerificationEmail(ctx context.Context, u models.User) error {
+	ttl := s.Accounts.VerifyEmailTTL
+	token, err := s.issueToken(ctx, u.ID, models.TokenPurposeVerifyEmail, ttl)
+	if err != nil {
+		return err
+	}
+	return s.Accounts.Mailer.Send(ctx, mail.Message{
+		To:      u.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Confirm that this is your email address by opening the link below, it expires in %s.\n\n%s\n\n"+
+			"If you didn't create an account, ignore this email.\n",
+			u.Name, humanDuration(ttl), link(s.Accounts.VerifyEmailURL, token)),
+	})
+}
+
+// issueToken signs a new token for the user and stores its hash so that it can be redeemed once.
+func (s *Store) issueToken(ctx context.Context, userID uint, purpose string, ttl time.Duration) (string, error) {
+	expiresAt := time.Now().Add(ttl)
+	token, err := s.Accounts.Tokens.issue(purpose, userID, expiresAt)
+	if err != nil {
+		return "", err
+	}
+	_, err = s.UserRepo.CreateUserToken(ctx, models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// redeemToken checks token and marks it used. The signature is checked first, so made up tokens never reach the
+// database.
+func (s *Store) redeemToken(ctx context.Context, token string, purpose string) (models.UserToken, error) {
+	now := time.Now()
+	p, err := s.Accounts.Tokens.verify(token, purpose, now)
+	if err != nil {
+		return models.UserToken{}, ErrInvalidEmailToken
+	}
+
+	t, err := s.UserRepo.UseUserToken(ctx, hashToken(token), purpose)
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, repository.ErrTokenAlreadyUsed) {
+		return models.UserToken{}, ErrInvalidEmailToken
+	}
+	if err != nil {
+		return models.UserToken{}, err
+	}
+	if t.UserID != p.UserID || !now.Before(t.ExpiresAt) {
+		return models.UserToken{}, ErrInvalidEmailToken
+	}
+	return t, nil
+}
+
+// checkEmailVerified returns errEmailNotVerified unless the user has verified their email address.
+func (s *Store) checkEmailVerified(ctx context.Context, userID string) error {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parsing user id %q: %w", userID, err)
+	}
+	u, err := s.UserRepo.ViewUserById(ctx, uint(uid))
+	if err != nil {
+		return err
+	}
+	if u.EmailVerifiedAt == nil {
+		return errEmailNotVerified
+	}
+	return nil
+}
+
+func link(template string, token string) string {
+	return strings.ReplaceAll(template, config.TokenPlaceholder, url.QueryEscape(token))
+}
+
+// humanDuration writes d in whole hours or minutes for the emails, such as "48 hours" rather than "48h0m0s".
+func humanDuration(d time.Duration) string {
+	n, unit := int(d/time.Minute), "minute"
+	if d >= time.Hour && d%time.Hour == 0 {
+		n, unit = int(d/time.Hour), "hour"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", n, unit)
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"job-portal-api/internal/auth"
+	"job-portal-api/internal/config"
+	"job-portal-api/internal/database"
+	"job-portal-api/internal/mail"
+	"job-portal-api/internal/migrate"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/repository"
+)
+
+// newAccountStore returns a Store backed by a fresh in-memory SQLite database, and the mailer its emails go to.
+func newAccountStore(t *testing.T) (*Store, *mail.Memory) {
+	t.Helper()
+	db, err := database.Open(config.DB{Driver: config.DriverSQLite, DSN: ":memory:"})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	m, err := migrate.New(sqlDB, config.DriverSQLite)
+	require.NoError(t, err)
+	_, err = m.Up(context.Background())
+	require.NoError(t, err)
+
+	repo, err := repository.NewRepository(db)
+	require.NoError(t, err)
+	tokens, err := NewTokenSigner([]byte(strings.Repeat("k", 32)))
+	require.NoError(t, err)
+	mailer := &mail.Memory{}
+	s, err := NewStore(repo, Accounts{
+		Mailer:           mailer,
+		Tokens:           tokens,
+		VerifyEmailURL:   "https://jobs.example/verify?token={token}",
+		ResetPasswordURL: "https://jobs.example/reset?token={token}",
+		VerifyEmailTTL:   48 * time.Hour,
+		ResetPasswordTTL: time.Hour,
+		TOTPIssuer:       "Job Portal",
+		MFAChallengeTTL:  5 * time.Minute,
+	})
+	require.NoError(t, err)
+	return s.(*Store), mailer
+}
+
+// createRecruiter registers a user and makes them a recruiter, as an admin would.
+func createRecruiter(ctx context.Context, s *Store, nu models.NewUser) (models.User, error) {
+	u, err := s.CreateUser(ctx, nu)
+	if err != nil {
+		return models.User{}, err
+	}
+	return s.SetUserRole(ctx, u.ID, models.UserRole{Role: models.RoleRecruiter})
+}
+
+var linkToken = regexp.MustCompile(`token=(\S+)`)
+
+// lastToken returns the token in the link of the last email sent to the given address.
+func lastToken(t *testing.T, mailer *mail.Memory, to string) string {
+	t.Helper()
+	messages := mailer.Messages()
+	require.NotEmpty(t, messages)
+	msg := messages[len(messages)-1]
+	require.Equal(t, to, msg.To)
+	match := linkToken.FindStringSubmatch(msg.Body)
+	require.NotNil(t, match, "no link in %q", msg.Body)
+	token, err := url.QueryUnescape(match[1])
+	require.NoError(t, err)
+	return token
+}
+
+func TestSetUserRole(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newAccountStore(t)
+
+	// Everyone registers as a candidate
+	u, err := s.CreateUser(ctx, models.NewUser{Name: "stym", Email: "stym@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	require.Equal(t, models.RoleCandidate, u.Role)
+
+	u, err = s.SetUserRole(ctx, u.ID, models.UserRole{Role: models.RoleRecruiter})
+	require.NoError(t, err)
+	require.Equal(t, models.RoleRecruiter, u.Role)
+	stored, err := s.UserRepo.ViewUserById(ctx, u.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.RoleRecruiter, stored.Role)
+
+	_, err = s.SetUserRole(ctx, u.ID+1, models.UserRole{Role: models.RoleRecruiter})
+	require.ErrorIs(t, err, errUserNotFound)
+
+	// An admin stays one
+	admin, err := s.UserRepo.CreateUser(ctx, models.User{Name: "root", Email: "root@email.com", Role: models.RoleAdmin})
+	require.NoError(t, err)
+	_, err = s.SetUserRole(ctx, admin.ID, models.UserRole{Role: models.RoleCandidate})
+	require.ErrorIs(t, err, errAdminRole)
+}
+
+func TestLogoutRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newAccountStore(t)
+	alice, err := s.CreateUser(ctx, models.NewUser{Name: "alice", Email: "alice@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	bob, err := s.CreateUser(ctx, models.NewUser{Name: "bob", Email: "bob@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	aliceID := strconv.FormatUint(uint64(alice.ID), 10)
+	rt, err := s.IssueRefreshToken(ctx, aliceID)
+	require.NoError(t, err)
+
+	// Bob can't end the session of Alice with her refresh token
+	err = s.Logout(ctx, auth.NewClaims(strconv.FormatUint(uint64(bob.ID), 10), models.RoleCandidate), rt)
+	require.ErrorIs(t, err, errRefreshTokenUnknown)
+
+	require.NoError(t, s.Logout(ctx, auth.NewClaims(aliceID, models.RoleCandidate), rt))
+	_, _, err = s.Refresh(ctx, rt)
+	require.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	s, mailer := newAccountStore(t)
+
+	u, err := createRecruiter(ctx, s, models.NewUser{Name: "stym", Email: "stym@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	require.Nil(t, u.EmailVerifiedAt)
+	userID := strconv.FormatUint(uint64(u.ID), 10)
+
+	msg := mailer.Messages()[0]
+	require.Equal(t, "Verify your email address", msg.Subject)
+	require.Contains(t, msg.Body, "https://jobs.example/verify?token=")
+	require.Contains(t, msg.Body, "48 hours")
+	token := lastToken(t, mailer, "stym@email.com")
+
+	// Unverified users can't post jobs
+	c, err := s.CreatCompanies(ctx, models.NewComapanies{CompanyName: "tek", Location: "banglore"}, u.ID)
+	require.NoError(t, err)
+	_, err = s.CreateJob(ctx, models.NewJob{Title: "dev"}, c.ID, userID)
+	require.ErrorIs(t, err, errEmailNotVerified)
+
+	require.ErrorIs(t, s.VerifyEmail(ctx, token+"x"), ErrInvalidEmailToken)
+	require.NoError(t, s.VerifyEmail(ctx, token))
+	require.ErrorIs(t, s.VerifyEmail(ctx, token), ErrInvalidEmailToken, "tokens are single use")
+
+	verified, err := s.UserRepo.ViewUserById(ctx, u.ID)
+	require.NoError(t, err)
+	require.NotNil(t, verified.EmailVerifiedAt)
+	_, err = s.CreateJob(ctx, models.NewJob{Title: "dev"}, c.ID, userID)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, s.SendVerificationEmail(ctx, userID), errAlreadyVerified)
+}
+
+func TestSendVerificationEmail(t *testing.T) {
+	ctx := context.Background()
+	s, mailer := newAccountStore(t)
+
+	u, err := s.CreateUser(ctx, models.NewUser{Name: "stym", Email: "stym@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	first := lastToken(t, mailer, "stym@email.com")
+
+	require.NoError(t, s.SendVerificationEmail(ctx, strconv.FormatUint(uint64(u.ID), 10)))
+	require.Len(t, mailer.Messages(), 2)
+	second := lastToken(t, mailer, "stym@email.com")
+	require.NotEqual(t, first, second)
+
+	// A password reset token can't verify an email
+	require.NoError(t, s.RequestPasswordReset(ctx, "stym@email.com"))
+	require.ErrorIs(t, s.VerifyEmail(ctx, lastToken(t, mailer, "stym@email.com")), ErrInvalidEmailToken)
+
+	require.NoError(t, s.VerifyEmail(ctx, first))
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	s, mailer := newAccountStore(t)
+
+	u, err := s.CreateUser(ctx, models.NewUser{Name: "stym", Email: "stym@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	session, err := s.IssueRefreshToken(ctx, strconv.FormatUint(uint64(u.ID), 10))
+	require.NoError(t, err)
+
+	// Unknown emails are not an error and get no email
+	require.NoError(t, s.RequestPasswordReset(ctx, "nobody@email.com"))
+	require.Len(t, mailer.Messages(), 1)
+
+	require.NoError(t, s.RequestPasswordReset(ctx, "stym@email.com"))
+	msg := mailer.Messages()[1]
+	require.Equal(t, "Reset your password", msg.Subject)
+	require.Contains(t, msg.Body, "1 hour")
+	token := lastToken(t, mailer, "stym@email.com")
+
+	require.NoError(t, s.ResetPassword(ctx, token, "N3wPassword"))
+	require.ErrorIs(t, s.ResetPassword(ctx, token, "Oth3rPassword"), ErrInvalidEmailToken)
+
+	stored, err := s.UserRepo.ViewUserById(ctx, u.ID)
+	require.NoError(t, err)
+	require.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte("N3wPassword")))
+	_, _, err = s.Authenticate(ctx, "stym@email.com", "N3wPassword")
+	require.NoError(t, err)
+
+	// The sessions opened with the old password are gone
+	_, _, err = s.Refresh(ctx, session)
+	require.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
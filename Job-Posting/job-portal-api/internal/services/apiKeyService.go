@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"job-portal-api/internal/auth"
+	"job-portal-api/internal/models"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// apiKeyPrefixLen is how many characters of a key are stored in the clear, to tell keys apart in listings.
+	apiKeyPrefixLen = len(auth.APIKeyPrefix) + 8
+	// apiKeyUsePrecision is how precisely the last use of a key is recorded.
+	apiKeyUsePrecision = time.Minute
+)
+
+var apiKeyEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// CreateAPIKey creates a key an integration can use to act on the company, within the given scopes. The key is
+// only returned here, the portal keeps its hash.
+func (s *Store) CreateAPIKey(ctx context.Context, companyID uint, nk models.NewAPIKey, userID string) (models.CreatedAPIKey, error) {
+	err := s.checkCompanyOwner(ctx, companyID, userID)
+	if err != nil {
+		return models.CreatedAPIKey{}, err
+	}
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return models.CreatedAPIKey{}, fmt.Errorf("parsing user id %q: %w", userID, err)
+	}
+	if nk.ExpiresAt != nil && !nk.ExpiresAt.After(time.Now()) {
+		return models.CreatedAPIKey{}, errAPIKeyExpiryPassed
+	}
+
+	key, err := newAPIKey()
+	if err != nil {
+		return models.CreatedAPIKey{}, err
+	}
+	scopes := slices.Clone(nk.Scopes)
+	slices.Sort(scopes)
+	k, err := s.UserRepo.CreateAPIKey(ctx, models.APIKey{
+		CompanyID: companyID,
+		CreatedBy: uint(uid),
+		Name:      nk.Name,
+		Prefix:    key[:apiKeyPrefixLen],
+		KeyHash:   hashToken(key),
+		Scopes:    slices.Compact(scopes),
+		ExpiresAt: nk.ExpiresAt,
+	})
+	if err != nil {
+		return models.CreatedAPIKey{}, err
+	}
+	return models.CreatedAPIKey{APIKey: k, Key: key}, nil
+}
+
+// ListAPIKeys returns the keys of the company, including the revoked and expired ones.
+func (s *Store) ListAPIKeys(ctx context.Context, companyID uint, userID string) ([]models.APIKey, error) {
+	err := s.checkCompanyOwner(ctx, companyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.UserRepo.ViewAPIKeysByCompany(ctx, companyID)
+}
+
+// RevokeAPIKey revokes a key of the company. Requests made with it are refused from then on.
+func (s *Store) RevokeAPIKey(ctx context.Context, companyID uint, keyID uint, userID string) error {
+	err := s.checkCompanyOwner(ctx, companyID, userID)
+	if err != nil {
+		return err
+	}
+	err = s.UserRepo.RevokeAPIKey(ctx, companyID, keyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errAPIKeyNotFound
+	}
+	return err
+}
+
+// AuthenticateAPIKey returns the claims of a request made with key. The request acts as the owner of the company
+// of the key, with their current role, restricted to that company and to the scopes of the key. The keys of an
+// owner who is no longer a recruiter stop working.
+func (s *Store) AuthenticateAPIKey(ctx context.Context, key string) (auth.Claims, error) {
+	k, err := s.UserRepo.FindAPIKey(ctx, hashToken(key))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return auth.Claims{}, errInvalidAPIKey
+	}
+	if err != nil {
+		return auth.Claims{}, err
+	}
+	now := time.Now()
+	if k.RevokedAt != nil || (k.ExpiresAt != nil && !k.ExpiresAt.After(now)) {
+		return auth.Claims{}, errInvalidAPIKey
+	}
+
+	// The keys of a deleted company stop working with it
+	companies, err := s.UserRepo.ViewCompanyById(ctx, k.CompanyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && len(companies) == 0) {
+		return auth.Claims{}, errInvalidAPIKey
+	}
+	if err != nil {
+		return auth.Claims{}, err
+	}
+
+	owner, err := s.UserRepo.ViewUserById(ctx, companies[0].UserId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return auth.Claims{}, errInvalidAPIKey
+	}
+	if err != nil {
+		return auth.Claims{}, err
+	}
+	if owner.Role != models.RoleRecruiter && owner.Role != models.RoleAdmin {
+		return auth.Claims{}, errInvalidAPIKey
+	}
+
+	err = s.UserRepo.TouchAPIKey(ctx, k.ID, now, apiKeyUsePrecision)
+	if err != nil {
+		return auth.Claims{}, err
+	}
+
+	claims := auth.NewClaims(strconv.FormatUint(uint64(owner.ID), 10), owner.Role)
+	claims.APIKeyID = k.ID
+	claims.CompanyID = k.CompanyID
+	claims.Scopes = k.Scopes
+	return claims, nil
+}
+
+// newAPIKey returns a random key of 160 bits, starting with auth.APIKeyPrefix.
+func newAPIKey() (string, error) {
+	b := make([]byte, 20)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("generating api key: %w", err)
+	}
+	return auth.APIKeyPrefix + strings.ToLower(apiKeyEncoding.EncodeToString(b)), nil
+}
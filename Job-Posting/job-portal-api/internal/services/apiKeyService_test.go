@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"job-portal-api/internal/auth"
+	"job-portal-api/internal/models"
+)
+
+func TestAPIKeys(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newAccountStore(t)
+	u, err := createRecruiter(ctx, s, models.NewUser{Name: "stym", Email: "stym@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	require.NoError(t, s.UserRepo.MarkEmailVerified(ctx, u.ID, time.Now()))
+	userID := strconv.FormatUint(uint64(u.ID), 10)
+	c, err := s.CreatCompanies(ctx, models.NewComapanies{CompanyName: "tek", Location: "banglore"}, u.ID)
+	require.NoError(t, err)
+	other, err := s.CreatCompanies(ctx, models.NewComapanies{CompanyName: "other", Location: "banglore"}, u.ID)
+	require.NoError(t, err)
+
+	past := time.Now().Add(-time.Minute)
+	_, err = s.CreateAPIKey(ctx, c.ID, models.NewAPIKey{Name: "ats", Scopes: []string{models.ScopeJobsRead}, ExpiresAt: &past}, userID)
+	require.ErrorIs(t, err, errAPIKeyExpiryPassed)
+
+	created, err := s.CreateAPIKey(ctx, c.ID, models.NewAPIKey{
+		Name:   "ats",
+		Scopes: []string{models.ScopeJobsWrite, models.ScopeJobsRead, models.ScopeJobsWrite},
+	}, userID)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(created.Key, auth.APIKeyPrefix))
+	require.Equal(t, created.Key[:apiKeyPrefixLen], created.Prefix)
+	require.Equal(t, []string{models.ScopeJobsRead, models.ScopeJobsWrite}, created.Scopes)
+
+	// Only the hash is kept
+	keys, err := s.ListAPIKeys(ctx, c.ID, userID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, hashToken(created.Key), keys[0].KeyHash)
+	require.Nil(t, keys[0].LastUsedAt)
+
+	claims, err := s.AuthenticateAPIKey(ctx, created.Key)
+	require.NoError(t, err)
+	require.Equal(t, userID, claims.Subject)
+	require.Equal(t, []string{models.RoleRecruiter}, claims.Roles)
+	require.Equal(t, created.ID, claims.APIKeyID)
+	require.Equal(t, c.ID, claims.CompanyID)
+	require.Equal(t, created.Scopes, claims.Scopes)
+	keys, err = s.ListAPIKeys(ctx, c.ID, userID)
+	require.NoError(t, err)
+	require.NotNil(t, keys[0].LastUsedAt)
+
+	// The key acts on its own company only, even though its owner has others
+	keyCtx := context.WithValue(ctx, auth.Key, claims)
+	_, err = s.CreateJob(keyCtx, models.NewJob{Title: "dev"}, c.ID, claims.Subject)
+	require.NoError(t, err)
+	_, err = s.CreateJob(keyCtx, models.NewJob{Title: "dev"}, other.ID, claims.Subject)
+	require.ErrorIs(t, err, ErrForbidden)
+
+	_, err = s.AuthenticateAPIKey(ctx, created.Key+"x")
+	require.ErrorIs(t, err, errInvalidAPIKey)
+
+	require.ErrorIs(t, s.RevokeAPIKey(ctx, other.ID, created.ID, userID), errAPIKeyNotFound)
+	require.NoError(t, s.RevokeAPIKey(ctx, c.ID, created.ID, userID))
+	_, err = s.AuthenticateAPIKey(ctx, created.Key)
+	require.ErrorIs(t, err, errInvalidAPIKey)
+}
+
+func TestAPIKeyOwnership(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newAccountStore(t)
+	owner, err := createRecruiter(ctx, s, models.NewUser{Name: "owner", Email: "owner@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	stranger, err := createRecruiter(ctx, s, models.NewUser{Name: "stranger", Email: "stranger@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	c, err := s.CreatCompanies(ctx, models.NewComapanies{CompanyName: "tek", Location: "banglore"}, owner.ID)
+	require.NoError(t, err)
+	strangerID := strconv.FormatUint(uint64(stranger.ID), 10)
+
+	nk := models.NewAPIKey{Name: "ats", Scopes: []string{models.ScopeJobsRead}}
+	_, err = s.CreateAPIKey(ctx, c.ID, nk, strangerID)
+	require.ErrorIs(t, err, ErrForbidden)
+	_, err = s.ListAPIKeys(ctx, c.ID, strangerID)
+	require.ErrorIs(t, err, ErrForbidden)
+
+	// Expired keys and the keys of deleted companies are refused
+	soon := time.Now().Add(50 * time.Millisecond)
+	ownerID := strconv.FormatUint(uint64(owner.ID), 10)
+	expiring, err := s.CreateAPIKey(ctx, c.ID, models.NewAPIKey{Name: "trial", Scopes: nk.Scopes, ExpiresAt: &soon}, ownerID)
+	require.NoError(t, err)
+	created, err := s.CreateAPIKey(ctx, c.ID, nk, ownerID)
+	require.NoError(t, err)
+	time.Sleep(time.Until(soon))
+	_, err = s.AuthenticateAPIKey(ctx, expiring.Key)
+	require.ErrorIs(t, err, errInvalidAPIKey)
+
+	require.NoError(t, s.DeleteCompany(ctx, c.ID, ownerID))
+	_, err = s.AuthenticateAPIKey(ctx, created.Key)
+	require.ErrorIs(t, err, errInvalidAPIKey)
+}
+
+func TestAPIKeyOwnerDemoted(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newAccountStore(t)
+	owner, err := createRecruiter(ctx, s, models.NewUser{Name: "owner", Email: "owner@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	ownerID := strconv.FormatUint(uint64(owner.ID), 10)
+	c, err := s.CreatCompanies(ctx, models.NewComapanies{CompanyName: "tek", Location: "banglore"}, owner.ID)
+	require.NoError(t, err)
+	created, err := s.CreateAPIKey(ctx, c.ID, models.NewAPIKey{Name: "ats", Scopes: []string{models.ScopeJobsWrite}}, ownerID)
+	require.NoError(t, err)
+	_, err = s.AuthenticateAPIKey(ctx, created.Key)
+	require.NoError(t, err)
+
+	// The key can't do more than its owner, who an admin made a candidate again
+	_, err = s.SetUserRole(ctx, owner.ID, models.UserRole{Role: models.RoleCandidate})
+	require.NoError(t, err)
+	_, err = s.AuthenticateAPIKey(ctx, created.Key)
+	require.ErrorIs(t, err, errInvalidAPIKey)
+}
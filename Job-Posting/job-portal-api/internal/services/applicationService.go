@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"job-portal-api/internal/models"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Apply submits the user's application to a job. A user can apply to each job only once.
+func (s *Store) Apply(ctx context.Context, jobID uint64, na models.NewApplication, userID string) (models.Application, error) {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return models.Application{}, fmt.Errorf("parsing user id %q: %w", userID, err)
+	}
+
+	job, err := s.UserRepo.ViewJobDetailsBy(ctx, jobID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Application{}, errJobNotFound
+	}
+	if err != nil {
+		return models.Application{}, err
+	}
+	if !job.IsOpen(time.Now()) {
+		return models.Application{}, ErrJobClosed
+	}
+
+	applied, err := s.UserRepo.HasApplied(ctx, job.ID, uint(uid))
+	if err != nil {
+		return models.Application{}, err
+	}
+	if applied {
+		return models.Application{}, ErrAlreadyApplied
+	}
+
+	application := models.Application{
+		JobID:       job.ID,
+		UserID:      uint(uid),
+		CoverLetter: na.CoverLetter,
+		ResumeURL:   na.ResumeURL,
+		Status:      models.ApplicationStatusApplied,
+	}
+	application, err = s.UserRepo.CreateApplication(ctx, application)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		// Another request of the same candidate got in between the check above and the insert
+		return models.Application{}, ErrAlreadyApplied
+	}
+	if err != nil {
+		return models.Application{}, err
+	}
+	return application, nil
+}
+
+// MyApplications lists the applications submitted by the user, newest first.
+func (s *Store) MyApplications(ctx context.Context, userID string) ([]models.Application, error) {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parsing user id %q: %w", userID, err)
+	}
+	return s.UserRepo.ViewApplicationsByUser(ctx, uint(uid))
+}
+
+// JobApplications lists the applications to a job. Only the owner of the company that posted the job may see them.
+func (s *Store) JobApplications(ctx context.Context, jobID uint64, userID string) ([]models.Application, error) {
+	job, err := s.UserRepo.ViewJobDetailsBy(ctx, jobID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkCompanyOwner(ctx, job.CompanyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.UserRepo.ViewApplicationsByJob(ctx, job.ID)
+}
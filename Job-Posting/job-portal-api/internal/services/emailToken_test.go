@@ -0,0 +1,62 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"job-portal-api/internal/models"
+)
+
+func TestTokenSigner(t *testing.T) {
+	_, err := NewTokenSigner([]byte("short"))
+	require.Error(t, err)
+
+	ts, err := NewTokenSigner([]byte(strings.Repeat("k", 32)))
+	require.NoError(t, err)
+	now := time.Now()
+
+	token, err := ts.issue(models.TokenPurposeVerifyEmail, 7, now.Add(time.Hour))
+	require.NoError(t, err)
+	p, err := ts.verify(token, models.TokenPurposeVerifyEmail, now)
+	require.NoError(t, err)
+	require.Equal(t, uint(7), p.UserID)
+
+	other, err := ts.issue(models.TokenPurposeVerifyEmail, 7, now.Add(time.Hour))
+	require.NoError(t, err)
+	require.NotEqual(t, token, other, "every token is unique")
+
+	otherSigner, err := NewTokenSigner([]byte(strings.Repeat("x", 32)))
+	require.NoError(t, err)
+	forged, err := otherSigner.issue(models.TokenPurposeVerifyEmail, 7, now.Add(time.Hour))
+	require.NoError(t, err)
+
+	payload, sig, _ := strings.Cut(token, ".")
+	tt := []struct {
+		name    string
+		token   string
+		purpose string
+		now     time.Time
+	}{
+		{name: "OtherPurpose", token: token, purpose: models.TokenPurposeResetPassword, now: now},
+		{name: "Expired", token: token, purpose: models.TokenPurposeVerifyEmail, now: now.Add(time.Hour)},
+		{name: "OtherSecret", token: forged, purpose: models.TokenPurposeVerifyEmail, now: now},
+		{name: "AlteredPayload", token: payload + "x." + sig, purpose: models.TokenPurposeVerifyEmail, now: now},
+		{name: "NoSignature", token: payload, purpose: models.TokenPurposeVerifyEmail, now: now},
+		{name: "Garbage", token: "not.a-token", purpose: models.TokenPurposeVerifyEmail, now: now},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ts.verify(tc.token, tc.purpose, tc.now)
+			require.ErrorIs(t, err, errBadToken)
+		})
+	}
+}
+
+func TestHumanDuration(t *testing.T) {
+	require.Equal(t, "48 hours", humanDuration(48*time.Hour))
+	require.Equal(t, "1 hour", humanDuration(time.Hour))
+	require.Equal(t, "90 minutes", humanDuration(90*time.Minute))
+	require.Equal(t, "1 minute", humanDuration(time.Minute))
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The kinds of domain errors. Handlers pick the response status from the kind, so every error the services return
+// for something the client did wrong should match one of them with errors.Is. Anything else is an internal error.
+var (
+	// ErrForbidden is returned when the caller is authenticated but is not allowed to act on the requested resource,
+	// for example when posting a job under a company they do not own.
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrNotFound is returned when the requested resource does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict is returned when a change can't be applied because it clashes with the current data, for example
+	// when the resource was modified concurrently or already exists.
+	ErrConflict = errors.New("conflict")
+
+	// ErrInvalidTransition is returned when the current state of a resource doesn't allow the change, for example
+	// when an application is moved to a stage it can't reach from its current one.
+	ErrInvalidTransition = errors.New("invalid status transition")
+
+	// ErrInvalidInput is returned when a request is well formed but its values are inconsistent, for example a
+	// salary range whose maximum is below its minimum.
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrUnauthenticated is returned when the credentials presented by the caller are wrong.
+	ErrUnauthenticated = errors.New("unauthenticated")
+
+	// ErrTooManyRequests is returned when the caller has to wait before trying again, for example when an account
+	// is locked after too many failed logins.
+	ErrTooManyRequests = errors.New("too many requests")
+)
+
+// Specific domain errors the handlers or tests need to tell apart from others of the same kind.
+var (
+	// ErrAlreadyApplied is returned when a candidate applies to a job they already applied to.
+	ErrAlreadyApplied = newError(ErrConflict, "already applied to this job")
+
+	// ErrJobClosed is returned when a candidate applies to a job that isn't published or has expired.
+	ErrJobClosed = newError(ErrInvalidTransition, "job is not accepting applications")
+
+	// ErrInvalidCursor is returned when a pagination cursor is malformed or was issued for another sort order.
+	ErrInvalidCursor = newError(ErrInvalidInput, "invalid cursor")
+
+	// ErrInvalidRefreshToken is returned when a refresh token is unknown, expired or has already been used.
+	ErrInvalidRefreshToken = newError(ErrUnauthenticated, "invalid refresh token")
+
+	// ErrOIDCLoginFailed is returned when the single sign-on provider doesn't vouch for the user, for example
+	// because the login was tampered with or took too long.
+	ErrOIDCLoginFailed = newError(ErrUnauthenticated, "single sign-on failed, try again")
+
+	// ErrInvalidEmailToken is returned when an email verification or password reset token is forged, expired or
+	// has already been used.
+	ErrInvalidEmailToken = newError(ErrInvalidInput, "invalid or expired link, ask for a new one")
+
+	errJobNotFound         = newError(ErrNotFound, "job not found")
+	errCompanyNotFound     = newError(ErrNotFound, "company not found")
+	errApplicationNotFound = newError(ErrNotFound, "application not found")
+	errUserNotFound        = newError(ErrNotFound, "user not found")
+	errAdminRole           = newError(ErrForbidden, "the role of an admin can't be changed")
+	errRefreshTokenUnknown = newError(ErrNotFound, "refresh token not found")
+	errNotCompanyOwner     = newError(ErrForbidden, "you do not own this company")
+	errInvalidCredentials  = newError(ErrUnauthenticated, "invalid email or password")
+	errEmailNotVerified    = newError(ErrForbidden, "verify your email address before posting jobs")
+	errAlreadyVerified     = newError(ErrConflict, "email address is already verified")
+	errInvalidMFAToken     = newError(ErrUnauthenticated, "invalid or expired mfa token, log in again")
+	errInvalidMFACode      = newError(ErrUnauthenticated, "invalid authentication code")
+	errMFACodeMismatch     = newError(ErrInvalidInput, "the code doesn't match, check the clock of your device")
+	errMFAAlreadyEnabled   = newError(ErrConflict, "two-factor authentication is already enabled")
+	errMFANotEnrolled      = newError(ErrInvalidTransition, "start the enrolment of an authenticator app first")
+	errMFANotEnabled       = newError(ErrInvalidTransition, "two-factor authentication is not enabled")
+	errInvalidAPIKey       = newError(ErrUnauthenticated, "invalid api key")
+	errAPIKeyNotFound      = newError(ErrNotFound, "api key not found")
+	errAPIKeyExpiryPassed  = newError(ErrInvalidInput, "expires_at must be in the future")
+
+	errOIDCDisabled          = newError(ErrNotFound, "single sign-on is not enabled")
+	errOIDCEmailNotVerified  = newError(ErrForbidden, "your email address isn't verified by the single sign-on provider")
+	errOIDCDomainNotAllowed  = newError(ErrForbidden, "single sign-on isn't allowed for your email domain")
+	errOIDCAccountLinked     = newError(ErrConflict, "your account is linked to another single sign-on user")
+	errOIDCAccountUnverified = newError(ErrForbidden, "verify your email address before logging in with single sign-on")
+	errOIDCAccountAmbiguous  = newError(ErrConflict, "several accounts use your email address, log in with your password")
+)
+
+// Error is a domain error. Kind is one of the error kinds above and Detail describes what went wrong in words that
+// are safe to show to the client. RetryAfter, when set, is how long the client should wait before trying again.
+type Error struct {
+	Kind       error
+	Detail     string
+	RetryAfter time.Duration
+}
+
+func newError(kind error, format string, args ...any) *Error {
+	return &Error{Kind: kind, Detail: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+// Is makes errors.Is match an Error against its kind, so callers can check for ErrNotFound and the like without
+// knowing the specific error.
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorKinds(t *testing.T) {
+	err := fmt.Errorf("applying: %w", ErrAlreadyApplied)
+	require.ErrorIs(t, err, ErrAlreadyApplied)
+	require.ErrorIs(t, err, ErrConflict)
+	require.NotErrorIs(t, err, ErrNotFound)
+
+	var de *Error
+	require.True(t, errors.As(err, &de))
+	require.Equal(t, "already applied to this job", de.Detail)
+
+	require.ErrorIs(t, errJobNotFound, ErrNotFound)
+	require.NotErrorIs(t, errJobNotFound, errCompanyNotFound)
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"job-portal-api/internal/auth"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/repository"
+)
+
+// DefaultJobTTL is how long a published job stays open when it has no application deadline.
+const DefaultJobTTL = 30 * 24 * time.Hour
+
+// PublishJob makes a draft job visible to candidates. The job expires at its application deadline, or after
+// DefaultJobTTL if it doesn't have one.
+func (s *Store) PublishJob(ctx context.Context, jobID uint64, userID string) (models.Job, error) {
+	job, err := s.jobForOwner(ctx, jobID, userID)
+	if err != nil {
+		return models.Job{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(DefaultJobTTL)
+	if job.ApplicationDeadline != nil {
+		expiresAt = *job.ApplicationDeadline
+	}
+	if !expiresAt.After(now) {
+		return models.Job{}, newError(ErrInvalidTransition, "a job can't be published after its application deadline")
+	}
+
+	return s.transitionJob(ctx, job, []string{models.JobStatusDraft}, map[string]interface{}{
+		"status":       models.JobStatusPublished,
+		"published_at": now,
+		"expires_at":   expiresAt,
+	})
+}
+
+// CloseJob stops a published job from accepting applications before it expires.
+func (s *Store) CloseJob(ctx context.Context, jobID uint64, userID string) (models.Job, error) {
+	job, err := s.jobForOwner(ctx, jobID, userID)
+	if err != nil {
+		return models.Job{}, err
+	}
+
+	return s.transitionJob(ctx, job, []string{models.JobStatusPublished}, map[string]interface{}{
+		"status": models.JobStatusClosed,
+	})
+}
+
+// ExpireJobs marks the published jobs whose expiry date has passed as expired and returns how many were changed.
+func (s *Store) ExpireJobs(ctx context.Context) (int64, error) {
+	return s.UserRepo.ExpireJobs(ctx, time.Now())
+}
+
+// RunJobSweeper calls ExpireJobs every interval until ctx is cancelled.
+func RunJobSweeper(ctx context.Context, s Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.ExpireJobs(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("expiring jobs")
+				continue
+			}
+			if n > 0 {
+				log.Info().Int64("Jobs", n).Msg("expired jobs")
+			}
+		}
+	}
+}
+
+func (s *Store) transitionJob(ctx context.Context, job models.Job, from []string, changes map[string]interface{}) (models.Job, error) {
+	if !contains(from, job.Status) {
+		return models.Job{}, newError(ErrInvalidTransition, "a %s job can't be moved to %s", job.Status, changes["status"])
+	}
+
+	job, err := s.UserRepo.TransitionJob(ctx, job.ID, from, changes)
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return models.Job{}, newError(ErrConflict, "job was updated by someone else, please retry")
+	}
+	if err != nil {
+		return models.Job{}, err
+	}
+	return job, nil
+}
+
+// jobForOwner loads a job and checks that the user owns the company that posted it.
+func (s *Store) jobForOwner(ctx context.Context, jobID uint64, userID string) (models.Job, error) {
+	job, err := s.UserRepo.ViewJobDetailsBy(ctx, jobID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Job{}, errJobNotFound
+	}
+	if err != nil {
+		return models.Job{}, err
+	}
+
+	err = s.checkCompanyOwner(ctx, job.CompanyID, userID)
+	if err != nil {
+		return models.Job{}, err
+	}
+	return job, nil
+}
+
+// canViewJob reports whether the user may see the job. Open jobs are public, anything else is only visible to
+// the owner of the company that posted it.
+func (s *Store) canViewJob(ctx context.Context, job models.Job, userID string) (bool, error) {
+	if job.IsOpen(time.Now()) {
+		return true, nil
+	}
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if ok && !claims.HasRole(models.RoleRecruiter, models.RoleAdmin) {
+		return false, nil
+	}
+
+	err := s.checkCompanyOwner(ctx, job.CompanyID, userID)
+	if errors.Is(err, ErrForbidden) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -2,7 +2,14 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"job-portal-api/internal/auth"
 	"job-portal-api/internal/models"
+	"job-portal-api/internal/repository"
+	"strconv"
+
+	"gorm.io/gorm"
 )
 
 func (s *Store) CreatCompanies(ctx context.Context, nc models.NewComapanies, UserID uint) (models.Companies, error) {
@@ -37,15 +44,43 @@ func (s *Store) ViewCompanies(ctx context.Context, companyID string) ([]models.C
 
 func (s *Store) ViewCompaniesById(ctx context.Context, companyID uint, userID string) ([]models.Companies, error) {
 	company, err := s.UserRepo.ViewCompanyById(ctx, companyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return []models.Companies{}, errCompanyNotFound
+	}
 	if err != nil {
 		return []models.Companies{}, err
 	}
 
 	return company, nil
 }
-func (s *Store) CreateJob(ctx context.Context, job models.Job, userID string) (models.Job, error) {
+func (s *Store) CreateJob(ctx context.Context, nj models.NewJob, companyID uint, userID string) (models.Job, error) {
+	// Only users who proved their email address is real may post jobs
+	err := s.checkEmailVerified(ctx, userID)
+	if err != nil {
+		return models.Job{}, err
+	}
+	err = s.checkCompanyOwner(ctx, companyID, userID)
+	if err != nil {
+		return models.Job{}, err
+	}
+
+	job := models.Job{
+		Title:               nj.Title,
+		Description:         nj.Description,
+		CompanyID:           companyID,
+		Location:            nj.Location,
+		WorkMode:            nj.WorkMode,
+		EmploymentType:      nj.EmploymentType,
+		Seniority:           nj.Seniority,
+		SalaryMin:           nj.SalaryMin,
+		SalaryMax:           nj.SalaryMax,
+		SalaryCurrency:      nj.SalaryCurrency,
+		Skills:              nj.Skills,
+		ApplicationDeadline: nj.ApplicationDeadline,
+		Status:              models.JobStatusDraft,
+	}
 
-	job, err := s.UserRepo.CreateJob(ctx, job)
+	job, err = s.UserRepo.CreateJob(ctx, job)
 	if err != nil {
 		return models.Job{}, err
 	}
@@ -60,19 +95,74 @@ func (s *Store) ListJobs(ctx context.Context, companyID uint, userid string) ([]
 
 	return jobs, nil
 }
-func (s *Store) AllJob(ctx context.Context, userId string) ([]models.Job, error) {
-	jobs, err := s.UserRepo.FindAllJobs(ctx)
+
+// DefaultJobPageSize is the number of jobs returned per page when the client doesn't ask for a limit.
+const DefaultJobPageSize = 20
+
+func (s *Store) AllJob(ctx context.Context, f models.JobFilter, userId string) (models.JobPage, error) {
+	if f.Sort == "" {
+		f.Sort = models.JobSortNewest
+	}
+	if f.Limit <= 0 {
+		f.Limit = DefaultJobPageSize
+	}
+
+	page, err := s.UserRepo.FindAllJobs(ctx, f)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return models.JobPage{}, ErrInvalidCursor
+	}
 	if err != nil {
-		return []models.Job{}, err
+		return models.JobPage{}, err
 	}
 
-	return jobs, nil
+	return page, nil
 }
 func (s *Store) JobsByID(ctx context.Context, jobID uint64, userId string) (models.Job, error) {
 	job, err := s.UserRepo.ViewJobDetailsBy(ctx, jobID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Job{}, errJobNotFound
+	}
 	if err != nil {
 		return models.Job{}, err
 
 	}
+
+	visible, err := s.canViewJob(ctx, job, userId)
+	if err != nil {
+		return models.Job{}, err
+	}
+	if !visible {
+		return models.Job{}, errJobNotFound
+	}
 	return job, nil
 }
+
+// checkCompanyOwner makes sure the user identified by userID owns the company before it or its jobs are changed.
+// Admins are allowed to act on any company, and API keys only on their own. It returns an ErrForbidden error when
+// the user is not allowed.
+func (s *Store) checkCompanyOwner(ctx context.Context, companyID uint, userID string) error {
+	claims, ok := ctx.Value(auth.Key).(auth.Claims)
+	if ok && claims.CompanyID != 0 && claims.CompanyID != companyID {
+		return errNotCompanyOwner
+	}
+	if ok && claims.HasRole(models.RoleAdmin) {
+		return nil
+	}
+
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parsing user id %q: %w", userID, err)
+	}
+
+	companies, err := s.UserRepo.ViewCompanyById(ctx, companyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errCompanyNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if len(companies) == 0 || companies[0].UserId != uint(uid) {
+		return errNotCompanyOwner
+	}
+	return nil
+}
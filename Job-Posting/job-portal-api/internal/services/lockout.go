@@ -0,0 +1,61 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// LockoutPolicy decides how long an account is locked after repeated failed logins. The first Threshold-1
+// failures in a row don't lock the account. The next one locks it for Base, and every failure after that doubles
+// the lock, up to Max. A successful login resets the count.
+type LockoutPolicy struct {
+	Threshold int
+	Base      time.Duration
+	Max       time.Duration
+}
+
+// DefaultLockoutPolicy locks an account for a minute after five failed logins in a row, and for up to an hour
+// when the guessing goes on.
+func DefaultLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{Threshold: 5, Base: time.Minute, Max: time.Hour}
+}
+
+// LockFor returns how long the account is locked after the given number of failed logins in a row.
+func (p LockoutPolicy) LockFor(failures int) time.Duration {
+	if p.Threshold <= 0 || failures < p.Threshold {
+		return 0
+	}
+	d := p.Base
+	for i := p.Threshold; i < failures && d < p.Max; i++ {
+		d *= 2
+	}
+	return min(d, p.Max)
+}
+
+// LockUntil returns the end of the lock after the given number of failed logins at now, or the zero time when the
+// account isn't locked.
+func (p LockoutPolicy) LockUntil(now time.Time, failures int) time.Time {
+	d := p.LockFor(failures)
+	if d <= 0 {
+		return time.Time{}
+	}
+	return now.Add(d)
+}
+
+func accountLocked(d time.Duration) *Error {
+	// Round up so that the client never retries a moment too early
+	if r := d % time.Second; r != 0 {
+		d += time.Second - r
+	}
+	e := newError(ErrTooManyRequests, "too many failed logins, try again in %s", d)
+	e.RetryAfter = d
+	return e
+}
+
+// dummyHash is compared against the password given for an unknown email.
+var dummyHash = sync.OnceValue(func() []byte {
+	h, _ := bcrypt.GenerateFromPassword([]byte("not the password of anyone"), bcrypt.DefaultCost)
+	return h
+})
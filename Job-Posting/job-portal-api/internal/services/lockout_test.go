@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/repository"
+)
+
+func TestLockFor(t *testing.T) {
+	p := LockoutPolicy{Threshold: 3, Base: time.Minute, Max: 5 * time.Minute}
+	expected := []time.Duration{0, 0, 0, time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for failures, d := range expected {
+		require.Equal(t, d, p.LockFor(failures), "after %d failures", failures)
+	}
+	require.Zero(t, LockoutPolicy{}.LockFor(100), "no lockout without a threshold")
+}
+
+// loginRepo keeps a single user in memory. The methods Authenticate doesn't use are left to the nil embedded
+// interface and panic if called.
+type loginRepo struct {
+	repository.UserRepo
+	user models.User
+}
+
+func (r *loginRepo) ViewUserByEmail(ctx context.Context, email string) (models.User, error) {
+	if email != r.user.Email {
+		return models.User{}, gorm.ErrRecordNotFound
+	}
+	return r.user, nil
+}
+
+func (r *loginRepo) RecordFailedLogin(ctx context.Context, uid uint, lockUntil func(failures int) time.Time) (models.User, error) {
+	r.user.FailedLogins++
+	if until := lockUntil(r.user.FailedLogins); !until.IsZero() {
+		r.user.LockedUntil = &until
+	}
+	return r.user, nil
+}
+
+func (r *loginRepo) ResetFailedLogins(ctx context.Context, uid uint) error {
+	r.user.FailedLogins = 0
+	r.user.LockedUntil = nil
+	return nil
+}
+
+func TestAuthenticateLockout(t *testing.T) {
+	ctx := context.Background()
+	hash, err := bcrypt.GenerateFromPassword([]byte("Passw0rd123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	repo := &loginRepo{user: models.User{Model: gorm.Model{ID: 7}, Email: "stym@email.com", PasswordHash: string(hash), Role: models.RoleCandidate}}
+	s := Store{UserRepo: repo, Lockout: LockoutPolicy{Threshold: 2, Base: time.Minute, Max: time.Hour}}
+
+	_, _, err = s.Authenticate(ctx, "nobody@email.com", "Passw0rd123")
+	require.ErrorIs(t, err, errInvalidCredentials)
+
+	// A successful login resets the failures
+	_, _, err = s.Authenticate(ctx, "stym@email.com", "wrong")
+	require.ErrorIs(t, err, errInvalidCredentials)
+	require.Equal(t, 1, repo.user.FailedLogins)
+	claims, _, err := s.Authenticate(ctx, "stym@email.com", "Passw0rd123")
+	require.NoError(t, err)
+	require.Equal(t, "7", claims.Subject)
+	require.Zero(t, repo.user.FailedLogins)
+
+	// The second failure in a row locks the account, even for the right password
+	for i := 0; i < 2; i++ {
+		_, _, err = s.Authenticate(ctx, "stym@email.com", "wrong")
+		require.ErrorIs(t, err, errInvalidCredentials)
+	}
+	require.NotNil(t, repo.user.LockedUntil)
+	_, _, err = s.Authenticate(ctx, "stym@email.com", "Passw0rd123")
+	require.ErrorIs(t, err, ErrTooManyRequests)
+	var de *Error
+	require.ErrorAs(t, err, &de)
+	require.Equal(t, time.Minute, de.RetryAfter)
+	require.Equal(t, "too many failed logins, try again in 1m0s", de.Detail)
+
+	// Once the lock has passed the right password works again
+	past := time.Now().Add(-time.Second)
+	repo.user.LockedUntil = &past
+	_, _, err = s.Authenticate(ctx, "stym@email.com", "Passw0rd123")
+	require.NoError(t, err)
+	require.Nil(t, repo.user.LockedUntil)
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"job-portal-api/internal/auth"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/totp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// totpSkew is how many time steps a code may be off by, for phones whose clock drifted.
+	totpSkew = 1
+	// recoveryCodeCount is the number of recovery codes handed out when two-factor authentication is enabled.
+	recoveryCodeCount = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// BeginTOTPEnrollment generates a new authenticator app secret for the user. Two-factor authentication is only
+// enabled once ConfirmTOTPEnrollment receives a code generated from it, so starting over is harmless.
+func (s *Store) BeginTOTPEnrollment(ctx context.Context, userID string) (models.TOTPEnrollment, error) {
+	u, err := s.viewUser(ctx, userID)
+	if err != nil {
+		return models.TOTPEnrollment{}, err
+	}
+	if u.TOTPEnabledAt != nil {
+		return models.TOTPEnrollment{}, errMFAAlreadyEnabled
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return models.TOTPEnrollment{}, err
+	}
+	err = s.UserRepo.SetTOTPSecret(ctx, u.ID, secret)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Enabled concurrently
+		return models.TOTPEnrollment{}, errMFAAlreadyEnabled
+	}
+	if err != nil {
+		return models.TOTPEnrollment{}, err
+	}
+	return models.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.Accounts.TOTPIssuer, u.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment enables two-factor authentication for the user if code was generated from the secret of
+// BeginTOTPEnrollment. It returns the recovery codes, which are shown this once and only stored hashed.
+func (s *Store) ConfirmTOTPEnrollment(ctx context.Context, userID string, code string) ([]string, error) {
+	u, err := s.viewUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.TOTPEnabledAt != nil {
+		return nil, errMFAAlreadyEnabled
+	}
+	if u.TOTPSecret == "" {
+		return nil, errMFANotEnrolled
+	}
+
+	step, ok := totp.Validate(u.TOTPSecret, strings.TrimSpace(code), time.Now(), totpSkew)
+	if !ok {
+		return nil, errMFACodeMismatch
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		codes[i], err = newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = hashToken(normalizeRecoveryCode(codes[i]))
+	}
+	err = s.UserRepo.EnableTOTP(ctx, u.ID, step, hashes)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errMFAAlreadyEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTOTP turns two-factor authentication off for the user. It takes a code from the authenticator app or a
+// recovery code, so that a stolen session alone can't remove the second factor.
+func (s *Store) DisableTOTP(ctx context.Context, userID string, code string) error {
+	u, err := s.viewUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.TOTPEnabledAt == nil {
+		return errMFANotEnabled
+	}
+
+	ok, err := s.checkSecondFactor(ctx, u, code, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errMFACodeMismatch
+	}
+	return s.UserRepo.DisableTOTP(ctx, u.ID)
+}
+
+// VerifyMFA completes a login started by Authenticate on an account with two-factor authentication. code is the
+// current code of the authenticator app or an unused recovery code. Wrong codes count as failed logins and lock
+// the account like wrong passwords do.
+func (s *Store) VerifyMFA(ctx context.Context, mfaToken string, code string) (auth.Claims, error) {
+	now := time.Now()
+	p, err := s.Accounts.Tokens.verify(mfaToken, models.TokenPurposeMFAChallenge, now)
+	if err != nil {
+		return auth.Claims{}, errInvalidMFAToken
+	}
+	u, err := s.UserRepo.ViewUserById(ctx, p.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return auth.Claims{}, errInvalidMFAToken
+	}
+	if err != nil {
+		return auth.Claims{}, err
+	}
+	// Turned off since the password was checked, log in again
+	if u.TOTPEnabledAt == nil {
+		return auth.Claims{}, errInvalidMFAToken
+	}
+	if u.LockedUntil != nil && u.LockedUntil.After(now) {
+		return auth.Claims{}, accountLocked(u.LockedUntil.Sub(now))
+	}
+
+	ok, err := s.checkSecondFactor(ctx, u, code, now)
+	if err != nil {
+		return auth.Claims{}, err
+	}
+	if !ok {
+		_, err = s.UserRepo.RecordFailedLogin(ctx, u.ID, func(failures int) time.Time {
+			return s.Lockout.LockUntil(now, failures)
+		})
+		if err != nil {
+			return auth.Claims{}, err
+		}
+		return auth.Claims{}, errInvalidMFACode
+	}
+
+	if u.FailedLogins > 0 || u.LockedUntil != nil {
+		err = s.UserRepo.ResetFailedLogins(ctx, u.ID)
+		if err != nil {
+			return auth.Claims{}, err
+		}
+	}
+	return auth.NewClaims(strconv.FormatUint(uint64(u.ID), 10), u.Role), nil
+}
+
+// issueMFAChallenge returns the token a user with two-factor authentication sends back with their code.
+func (s *Store) issueMFAChallenge(u models.User, now time.Time) (string, error) {
+	return s.Accounts.Tokens.issue(models.TokenPurposeMFAChallenge, u.ID, now.Add(s.Accounts.MFAChallengeTTL))
+}
+
+// checkSecondFactor reports whether code is a valid code of the authenticator app of the user or one of their
+// unused recovery codes, and uses it up.
+func (s *Store) checkSecondFactor(ctx context.Context, u models.User, code string, now time.Time) (bool, error) {
+	code = strings.TrimSpace(code)
+	if step, ok := totp.Validate(u.TOTPSecret, code, now, totpSkew); ok {
+		// A code already used is refused, even within its time step
+		return s.UserRepo.UseTOTPStep(ctx, u.ID, step)
+	}
+
+	err := s.UserRepo.UseRecoveryCode(ctx, u.ID, hashToken(normalizeRecoveryCode(code)))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *Store) viewUser(ctx context.Context, userID string) (models.User, error) {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return models.User{}, fmt.Errorf("parsing user id %q: %w", userID, err)
+	}
+	return s.UserRepo.ViewUserById(ctx, uint(uid))
+}
+
+// newRecoveryCode returns a random code of 40 bits written as two groups of four characters, like abcd-efgh.
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 5)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("generating recovery code: %w", err)
+	}
+	c := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+	return c[:4] + "-" + c[4:], nil
+}
+
+// normalizeRecoveryCode lets users type recovery codes without the dash, in any case.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
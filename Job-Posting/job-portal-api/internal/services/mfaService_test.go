@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/totp"
+)
+
+// enableTOTP enrols the authenticator app of the user and returns its secret and the recovery codes.
+func enableTOTP(t *testing.T, s *Store, userID string) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+	enrollment, err := s.BeginTOTPEnrollment(ctx, userID)
+	require.NoError(t, err)
+	code, err := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	codes, err := s.ConfirmTOTPEnrollment(ctx, userID, code)
+	require.NoError(t, err)
+	return enrollment.Secret, codes
+}
+
+func TestTOTPEnrollment(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newAccountStore(t)
+	u, err := createRecruiter(ctx, s, models.NewUser{Name: "stym", Email: "stym@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	userID := strconv.FormatUint(uint64(u.ID), 10)
+
+	// A code is needed to confirm, and it has to come from the secret handed out
+	_, err = s.ConfirmTOTPEnrollment(ctx, userID, "123456")
+	require.ErrorIs(t, err, errMFANotEnrolled)
+	require.ErrorIs(t, s.DisableTOTP(ctx, userID, "123456"), errMFANotEnabled)
+
+	enrollment, err := s.BeginTOTPEnrollment(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, enrollment.Secret, 32)
+	require.True(t, strings.HasPrefix(enrollment.ProvisioningURI, "otpauth://totp/Job%20Portal:stym@email.com?"), enrollment.ProvisioningURI)
+	require.Contains(t, enrollment.ProvisioningURI, "secret="+enrollment.Secret)
+
+	code, err := totp.Code(enrollment.Secret, totp.Step(time.Now())-5)
+	require.NoError(t, err)
+	_, err = s.ConfirmTOTPEnrollment(ctx, userID, code)
+	require.ErrorIs(t, err, errMFACodeMismatch)
+
+	code, err = totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	codes, err := s.ConfirmTOTPEnrollment(ctx, userID, code)
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	require.Regexp(t, `^[a-z2-7]{4}-[a-z2-7]{4}$`, codes[0])
+
+	_, err = s.BeginTOTPEnrollment(ctx, userID)
+	require.ErrorIs(t, err, errMFAAlreadyEnabled)
+
+	// Turning it off takes a code too
+	require.ErrorIs(t, s.DisableTOTP(ctx, userID, "aaaa-aaaa"), errMFACodeMismatch)
+	require.NoError(t, s.DisableTOTP(ctx, userID, codes[0]))
+	claims, mfaToken, err := s.Authenticate(ctx, "stym@email.com", "Passw0rd123")
+	require.NoError(t, err)
+	require.Empty(t, mfaToken)
+	require.Equal(t, userID, claims.Subject)
+}
+
+func TestVerifyMFA(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newAccountStore(t)
+	u, err := createRecruiter(ctx, s, models.NewUser{Name: "stym", Email: "stym@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	userID := strconv.FormatUint(uint64(u.ID), 10)
+	secret, codes := enableTOTP(t, s, userID)
+
+	// The password alone only gets an mfa token
+	claims, mfaToken, err := s.Authenticate(ctx, "stym@email.com", "Passw0rd123")
+	require.NoError(t, err)
+	require.Empty(t, claims.Subject)
+	require.NotEmpty(t, mfaToken)
+
+	_, err = s.VerifyMFA(ctx, "forged", codes[0])
+	require.ErrorIs(t, err, errInvalidMFAToken)
+	// Other signed tokens are not mfa tokens
+	reset, err := s.Accounts.Tokens.issue(models.TokenPurposeResetPassword, u.ID, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	_, err = s.VerifyMFA(ctx, reset, codes[0])
+	require.ErrorIs(t, err, errInvalidMFAToken)
+
+	// The code used to confirm the enrolment can't be replayed, the next one works once
+	step := totp.Step(time.Now())
+	used, err := totp.Code(secret, step)
+	require.NoError(t, err)
+	_, err = s.VerifyMFA(ctx, mfaToken, used)
+	require.ErrorIs(t, err, errInvalidMFACode)
+	next, err := totp.Code(secret, step+1)
+	require.NoError(t, err)
+	claims, err = s.VerifyMFA(ctx, mfaToken, next)
+	require.NoError(t, err)
+	require.Equal(t, userID, claims.Subject)
+	require.Equal(t, []string{models.RoleRecruiter}, claims.Roles)
+	_, err = s.VerifyMFA(ctx, mfaToken, next)
+	require.ErrorIs(t, err, errInvalidMFACode)
+
+	// Recovery codes can be typed without the dash, in any case, and work once
+	recovery := strings.ToUpper(strings.ReplaceAll(codes[1], "-", ""))
+	claims, err = s.VerifyMFA(ctx, mfaToken, recovery)
+	require.NoError(t, err)
+	require.Equal(t, userID, claims.Subject)
+	_, err = s.VerifyMFA(ctx, mfaToken, codes[1])
+	require.ErrorIs(t, err, errInvalidMFACode)
+}
+
+func TestVerifyMFALockout(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newAccountStore(t)
+	u, err := createRecruiter(ctx, s, models.NewUser{Name: "stym", Email: "stym@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	_, codes := enableTOTP(t, s, strconv.FormatUint(uint64(u.ID), 10))
+
+	// Logging in again with the right password doesn't clear the failed codes
+	for i := 0; i < s.Lockout.Threshold; i++ {
+		_, mfaToken, err := s.Authenticate(ctx, "stym@email.com", "Passw0rd123")
+		require.NoError(t, err)
+		_, err = s.VerifyMFA(ctx, mfaToken, "aaaa-aaaa")
+		require.ErrorIs(t, err, errInvalidMFACode)
+	}
+
+	_, _, err = s.Authenticate(ctx, "stym@email.com", "Passw0rd123")
+	require.ErrorIs(t, err, ErrTooManyRequests)
+	stored, err := s.UserRepo.ViewUserById(ctx, u.ID)
+	require.NoError(t, err)
+	require.Equal(t, s.Lockout.Threshold, stored.FailedLogins)
+
+	// A valid code doesn't get through either while locked
+	mfaToken, err := s.issueMFAChallenge(stored, time.Now())
+	require.NoError(t, err)
+	_, err = s.VerifyMFA(ctx, mfaToken, codes[0])
+	require.ErrorIs(t, err, ErrTooManyRequests)
+}
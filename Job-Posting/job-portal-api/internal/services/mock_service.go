@@ -1,12 +1,19 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: services.go
+//
+// Generated by this command:
+//
+//	mockgen -source services.go -destination mock_service.go -package services
+//
+// Package services is a generated GoMock package.
 package services
 
 import (
-	"context"
-
+	context "context"
+	auth "job-portal-api/internal/auth"
 	models "job-portal-api/internal/models"
 	reflect "reflect"
 
-	v5 "github.com/golang-jwt/jwt/v5"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -33,33 +40,201 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
-// Authenticate mocks base method.
-func (m *MockService) Authenticate(ctx context.Context, email, password string) (v5.RegisteredClaims, error) {
+// AllJob mocks base method.
+func (m *MockService) AllJob(ctx context.Context, f models.JobFilter, userId string) (models.JobPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Authenticate", ctx, email, password)
-	ret0, _ := ret[0].(v5.RegisteredClaims)
+	ret := m.ctrl.Call(m, "AllJob", ctx, f, userId)
+	ret0, _ := ret[0].(models.JobPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AllJob indicates an expected call of AllJob.
+func (mr *MockServiceMockRecorder) AllJob(ctx, f, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllJob", reflect.TypeOf((*MockService)(nil).AllJob), ctx, f, userId)
+}
+
+// ApplicationTimeline mocks base method.
+func (m *MockService) ApplicationTimeline(ctx context.Context, applicationID uint64, userId string) ([]models.ApplicationStatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplicationTimeline", ctx, applicationID, userId)
+	ret0, _ := ret[0].([]models.ApplicationStatusChange)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// ApplicationTimeline indicates an expected call of ApplicationTimeline.
+func (mr *MockServiceMockRecorder) ApplicationTimeline(ctx, applicationID, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplicationTimeline", reflect.TypeOf((*MockService)(nil).ApplicationTimeline), ctx, applicationID, userId)
+}
+
+// Apply mocks base method.
+func (m *MockService) Apply(ctx context.Context, jobID uint64, na models.NewApplication, userId string) (models.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", ctx, jobID, na, userId)
+	ret0, _ := ret[0].(models.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockServiceMockRecorder) Apply(ctx, jobID, na, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockService)(nil).Apply), ctx, jobID, na, userId)
+}
+
+// Authenticate mocks base method.
+func (m *MockService) Authenticate(ctx context.Context, email, password string) (auth.Claims, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, email, password)
+	ret0, _ := ret[0].(auth.Claims)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // Authenticate indicates an expected call of Authenticate.
-func (mr *MockServiceMockRecorder) Authenticate(ctx, email, password interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) Authenticate(ctx, email, password any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockService)(nil).Authenticate), ctx, email, password)
 }
 
-// AutoMigrate mocks base method.
-func (m *MockService) AutoMigrate() error {
+// AuthenticateAPIKey mocks base method.
+func (m *MockService) AuthenticateAPIKey(ctx context.Context, key string) (auth.Claims, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AutoMigrate")
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "AuthenticateAPIKey", ctx, key)
+	ret0, _ := ret[0].(auth.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateAPIKey indicates an expected call of AuthenticateAPIKey.
+func (mr *MockServiceMockRecorder) AuthenticateAPIKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateAPIKey", reflect.TypeOf((*MockService)(nil).AuthenticateAPIKey), ctx, key)
+}
+
+// BeginOIDCLogin mocks base method.
+func (m *MockService) BeginOIDCLogin(ctx context.Context) (models.OIDCLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginOIDCLogin", ctx)
+	ret0, _ := ret[0].(models.OIDCLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginOIDCLogin indicates an expected call of BeginOIDCLogin.
+func (mr *MockServiceMockRecorder) BeginOIDCLogin(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginOIDCLogin", reflect.TypeOf((*MockService)(nil).BeginOIDCLogin), ctx)
+}
+
+// BeginTOTPEnrollment mocks base method.
+func (m *MockService) BeginTOTPEnrollment(ctx context.Context, userID string) (models.TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginTOTPEnrollment", ctx, userID)
+	ret0, _ := ret[0].(models.TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginTOTPEnrollment indicates an expected call of BeginTOTPEnrollment.
+func (mr *MockServiceMockRecorder) BeginTOTPEnrollment(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTOTPEnrollment", reflect.TypeOf((*MockService)(nil).BeginTOTPEnrollment), ctx, userID)
+}
+
+// CloseJob mocks base method.
+func (m *MockService) CloseJob(ctx context.Context, jobID uint64, userId string) (models.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseJob", ctx, jobID, userId)
+	ret0, _ := ret[0].(models.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseJob indicates an expected call of CloseJob.
+func (mr *MockServiceMockRecorder) CloseJob(ctx, jobID, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseJob", reflect.TypeOf((*MockService)(nil).CloseJob), ctx, jobID, userId)
+}
+
+// CompleteOIDCLogin mocks base method.
+func (m *MockService) CompleteOIDCLogin(ctx context.Context, code, codeVerifier, nonce string) (auth.Claims, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteOIDCLogin", ctx, code, codeVerifier, nonce)
+	ret0, _ := ret[0].(auth.Claims)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CompleteOIDCLogin indicates an expected call of CompleteOIDCLogin.
+func (mr *MockServiceMockRecorder) CompleteOIDCLogin(ctx, code, codeVerifier, nonce any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteOIDCLogin", reflect.TypeOf((*MockService)(nil).CompleteOIDCLogin), ctx, code, codeVerifier, nonce)
+}
+
+// ConfirmTOTPEnrollment mocks base method.
+func (m *MockService) ConfirmTOTPEnrollment(ctx context.Context, userID, code string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTOTPEnrollment", ctx, userID, code)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// AutoMigrate indicates an expected call of AutoMigrate.
-func (mr *MockServiceMockRecorder) AutoMigrate() *gomock.Call {
+// ConfirmTOTPEnrollment indicates an expected call of ConfirmTOTPEnrollment.
+func (mr *MockServiceMockRecorder) ConfirmTOTPEnrollment(ctx, userID, code any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AutoMigrate", reflect.TypeOf((*MockService)(nil).AutoMigrate))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTOTPEnrollment", reflect.TypeOf((*MockService)(nil).ConfirmTOTPEnrollment), ctx, userID, code)
+}
+
+// CreatCompanies mocks base method.
+func (m *MockService) CreatCompanies(ctx context.Context, nc models.NewComapanies, UserId uint) (models.Companies, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatCompanies", ctx, nc, UserId)
+	ret0, _ := ret[0].(models.Companies)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatCompanies indicates an expected call of CreatCompanies.
+func (mr *MockServiceMockRecorder) CreatCompanies(ctx, nc, UserId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatCompanies", reflect.TypeOf((*MockService)(nil).CreatCompanies), ctx, nc, UserId)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockService) CreateAPIKey(ctx context.Context, companyID uint, nk models.NewAPIKey, userID string) (models.CreatedAPIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, companyID, nk, userID)
+	ret0, _ := ret[0].(models.CreatedAPIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockServiceMockRecorder) CreateAPIKey(ctx, companyID, nk, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockService)(nil).CreateAPIKey), ctx, companyID, nk, userID)
+}
+
+// CreateJob mocks base method.
+func (m *MockService) CreateJob(ctx context.Context, newJob models.NewJob, companyId uint, userId string) (models.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJob", ctx, newJob, companyId, userId)
+	ret0, _ := ret[0].(models.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJob indicates an expected call of CreateJob.
+func (mr *MockServiceMockRecorder) CreateJob(ctx, newJob, companyId, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockService)(nil).CreateJob), ctx, newJob, companyId, userId)
 }
 
 // CreateUser mocks base method.
@@ -72,100 +247,419 @@ func (m *MockService) CreateUser(ctx context.Context, nu models.NewUser) (models
 }
 
 // CreateUser indicates an expected call of CreateUser.
-func (mr *MockServiceMockRecorder) CreateUser(ctx, nu interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) CreateUser(ctx, nu any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockService)(nil).CreateUser), ctx, nu)
 }
 
-// Add mock implementations for other methods as needed...
+// DeleteCompany mocks base method.
+func (m *MockService) DeleteCompany(ctx context.Context, companyId uint, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCompany", ctx, companyId, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCompany indicates an expected call of DeleteCompany.
+func (mr *MockServiceMockRecorder) DeleteCompany(ctx, companyId, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCompany", reflect.TypeOf((*MockService)(nil).DeleteCompany), ctx, companyId, userId)
+}
 
-func (m *MockService) CreatCompanies(ctx context.Context, ni models.NewComapanies, userId uint) (models.Companies, error) {
+// DeleteJob mocks base method.
+func (m *MockService) DeleteJob(ctx context.Context, jobID uint64, userId string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateCompanies", ctx, ni, userId)
-	ret0, _ := ret[0].(models.Companies)
+	ret := m.ctrl.Call(m, "DeleteJob", ctx, jobID, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteJob indicates an expected call of DeleteJob.
+func (mr *MockServiceMockRecorder) DeleteJob(ctx, jobID, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteJob", reflect.TypeOf((*MockService)(nil).DeleteJob), ctx, jobID, userId)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockService) DisableTOTP(ctx context.Context, userID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockServiceMockRecorder) DisableTOTP(ctx, userID, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockService)(nil).DisableTOTP), ctx, userID, code)
+}
+
+// ExpireJobs mocks base method.
+func (m *MockService) ExpireJobs(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireJobs", ctx)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-func (mr *MockServiceMockRecorder) CreatCompanies(ctx, ni, userId interface{}) *gomock.Call {
+// ExpireJobs indicates an expected call of ExpireJobs.
+func (mr *MockServiceMockRecorder) ExpireJobs(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCompanies", reflect.TypeOf((*MockService)(nil).CreatCompanies), ctx, ni, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireJobs", reflect.TypeOf((*MockService)(nil).ExpireJobs), ctx)
 }
 
-func (m *MockService) ViewCompanies(ctx context.Context, companyId string) ([]models.Companies, error) {
+// IssueRefreshToken mocks base method.
+func (m *MockService) IssueRefreshToken(ctx context.Context, userID string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ViewCompanies", ctx, companyId)
-	ret0, _ := ret[0].([]models.Companies)
+	ret := m.ctrl.Call(m, "IssueRefreshToken", ctx, userID)
+	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-func (mr *MockServiceMockRecorder) ViewCompanies(ctx, companyId interface{}) *gomock.Call {
+// IssueRefreshToken indicates an expected call of IssueRefreshToken.
+func (mr *MockServiceMockRecorder) IssueRefreshToken(ctx, userID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewCompanies", reflect.TypeOf((*MockService)(nil).ViewCompanies), ctx, companyId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueRefreshToken", reflect.TypeOf((*MockService)(nil).IssueRefreshToken), ctx, userID)
 }
 
-func (m *MockService) ViewCompaniesById(ctx context.Context, companyById uint, userId string) ([]models.Companies, error) {
+// JobApplications mocks base method.
+func (m *MockService) JobApplications(ctx context.Context, jobID uint64, userId string) ([]models.Application, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "api/companies/:companyID", ctx, companyById, userId)
-	ret0, _ := ret[0].([]models.Companies)
+	ret := m.ctrl.Call(m, "JobApplications", ctx, jobID, userId)
+	ret0, _ := ret[0].([]models.Application)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-func (mr *MockServiceMockRecorder) ViewCompaniesById(ctx, companyById, userId interface{}) *gomock.Call {
+// JobApplications indicates an expected call of JobApplications.
+func (mr *MockServiceMockRecorder) JobApplications(ctx, jobID, userId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "api/companies/:companyID", reflect.TypeOf((*MockService)(nil).ViewCompaniesById), ctx, companyById, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JobApplications", reflect.TypeOf((*MockService)(nil).JobApplications), ctx, jobID, userId)
 }
 
-func (m *MockService) CreateJob(ctx context.Context, newJob models.Job, userId string) (models.Job, error) {
+// JobsByID mocks base method.
+func (m *MockService) JobsByID(ctx context.Context, jobID uint64, userId string) (models.Job, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateJob", ctx, newJob, userId)
+	ret := m.ctrl.Call(m, "JobsByID", ctx, jobID, userId)
 	ret0, _ := ret[0].(models.Job)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-func (mr *MockServiceMockRecorder) CreateJob(ctx, newJob, userId interface{}) *gomock.Call {
+// JobsByID indicates an expected call of JobsByID.
+func (mr *MockServiceMockRecorder) JobsByID(ctx, jobID, userId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockService)(nil).CreateJob), ctx, newJob, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JobsByID", reflect.TypeOf((*MockService)(nil).JobsByID), ctx, jobID, userId)
 }
 
-func (m *MockService) AllJob(ctx context.Context, userId string) ([]models.Job, error) {
+// ListAPIKeys mocks base method.
+func (m *MockService) ListAPIKeys(ctx context.Context, companyID uint, userID string) ([]models.APIKey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AllJob", ctx, userId)
-	ret0, _ := ret[0].([]models.Job)
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx, companyID, userID)
+	ret0, _ := ret[0].([]models.APIKey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-func (mr *MockServiceMockRecorder) AllJob(ctx, userId interface{}) *gomock.Call {
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockServiceMockRecorder) ListAPIKeys(ctx, companyID, userID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AllJob", reflect.TypeOf((*MockService)(nil).AllJob), ctx, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockService)(nil).ListAPIKeys), ctx, companyID, userID)
 }
 
+// ListJobs mocks base method.
 func (m *MockService) ListJobs(ctx context.Context, companyId uint, userId string) ([]models.Job, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "api/companies/:companyID/list-jobs", ctx, companyId, userId)
+	ret := m.ctrl.Call(m, "ListJobs", ctx, companyId, userId)
 	ret0, _ := ret[0].([]models.Job)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-func (mr *MockServiceMockRecorder) ListJobs(ctx, companyId, userId interface{}) *gomock.Call {
+// ListJobs indicates an expected call of ListJobs.
+func (mr *MockServiceMockRecorder) ListJobs(ctx, companyId, userId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "api/companies/:companyID/list-jobs", reflect.TypeOf((*MockService)(nil).ListJobs), ctx, companyId, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobs", reflect.TypeOf((*MockService)(nil).ListJobs), ctx, companyId, userId)
 }
 
-func (m *MockService) JobsById(ctx context.Context, jobById uint, userId string) ([]models.Job, error) {
+// Logout mocks base method.
+func (m *MockService) Logout(ctx context.Context, claims auth.Claims, refreshToken string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "api/jobs/:jobID", ctx, jobById, userId)
-	ret0, _ := ret[0].([]models.Job)
+	ret := m.ctrl.Call(m, "Logout", ctx, claims, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockServiceMockRecorder) Logout(ctx, claims, refreshToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockService)(nil).Logout), ctx, claims, refreshToken)
+}
+
+// MyApplications mocks base method.
+func (m *MockService) MyApplications(ctx context.Context, userId string) ([]models.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MyApplications", ctx, userId)
+	ret0, _ := ret[0].([]models.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MyApplications indicates an expected call of MyApplications.
+func (mr *MockServiceMockRecorder) MyApplications(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MyApplications", reflect.TypeOf((*MockService)(nil).MyApplications), ctx, userId)
+}
+
+// PublishJob mocks base method.
+func (m *MockService) PublishJob(ctx context.Context, jobID uint64, userId string) (models.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishJob", ctx, jobID, userId)
+	ret0, _ := ret[0].(models.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishJob indicates an expected call of PublishJob.
+func (mr *MockServiceMockRecorder) PublishJob(ctx, jobID, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishJob", reflect.TypeOf((*MockService)(nil).PublishJob), ctx, jobID, userId)
+}
+
+// Refresh mocks base method.
+func (m *MockService) Refresh(ctx context.Context, refreshToken string) (auth.Claims, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, refreshToken)
+	ret0, _ := ret[0].(auth.Claims)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockServiceMockRecorder) Refresh(ctx, refreshToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockService)(nil).Refresh), ctx, refreshToken)
+}
+
+// RequestPasswordReset mocks base method.
+func (m *MockService) RequestPasswordReset(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *MockServiceMockRecorder) RequestPasswordReset(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockService)(nil).RequestPasswordReset), ctx, email)
+}
+
+// ResetPassword mocks base method.
+func (m *MockService) ResetPassword(ctx context.Context, token, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, token, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockServiceMockRecorder) ResetPassword(ctx, token, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockService)(nil).ResetPassword), ctx, token, password)
+}
+
+// RestoreCompany mocks base method.
+func (m *MockService) RestoreCompany(ctx context.Context, companyId uint) (models.Companies, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCompany", ctx, companyId)
+	ret0, _ := ret[0].(models.Companies)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreCompany indicates an expected call of RestoreCompany.
+func (mr *MockServiceMockRecorder) RestoreCompany(ctx, companyId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCompany", reflect.TypeOf((*MockService)(nil).RestoreCompany), ctx, companyId)
+}
+
+// RestoreJob mocks base method.
+func (m *MockService) RestoreJob(ctx context.Context, jobID uint64) (models.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreJob", ctx, jobID)
+	ret0, _ := ret[0].(models.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreJob indicates an expected call of RestoreJob.
+func (mr *MockServiceMockRecorder) RestoreJob(ctx, jobID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreJob", reflect.TypeOf((*MockService)(nil).RestoreJob), ctx, jobID)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockService) RevokeAPIKey(ctx context.Context, companyID, keyID uint, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, companyID, keyID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockServiceMockRecorder) RevokeAPIKey(ctx, companyID, keyID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockService)(nil).RevokeAPIKey), ctx, companyID, keyID, userID)
+}
+
+// Search mocks base method.
+func (m *MockService) Search(ctx context.Context, sq models.SearchQuery, userId string) (models.SearchPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, sq, userId)
+	ret0, _ := ret[0].(models.SearchPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockServiceMockRecorder) Search(ctx, sq, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockService)(nil).Search), ctx, sq, userId)
+}
+
+// SendVerificationEmail mocks base method.
+func (m *MockService) SendVerificationEmail(ctx context.Context, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendVerificationEmail", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendVerificationEmail indicates an expected call of SendVerificationEmail.
+func (mr *MockServiceMockRecorder) SendVerificationEmail(ctx, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendVerificationEmail", reflect.TypeOf((*MockService)(nil).SendVerificationEmail), ctx, userId)
+}
+
+// SetUserRole mocks base method.
+func (m *MockService) SetUserRole(ctx context.Context, userID uint, ur models.UserRole) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, userID, ur)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockServiceMockRecorder) SetUserRole(ctx, userID, ur any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockService)(nil).SetUserRole), ctx, userID, ur)
+}
+
+// TransitionApplication mocks base method.
+func (m *MockService) TransitionApplication(ctx context.Context, applicationID uint64, nc models.NewStatusChange, userId string) (models.Application, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransitionApplication", ctx, applicationID, nc, userId)
+	ret0, _ := ret[0].(models.Application)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransitionApplication indicates an expected call of TransitionApplication.
+func (mr *MockServiceMockRecorder) TransitionApplication(ctx, applicationID, nc, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitionApplication", reflect.TypeOf((*MockService)(nil).TransitionApplication), ctx, applicationID, nc, userId)
+}
+
+// UpdateCompany mocks base method.
+func (m *MockService) UpdateCompany(ctx context.Context, companyId uint, uc models.UpdateCompany, userId string) (models.Companies, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCompany", ctx, companyId, uc, userId)
+	ret0, _ := ret[0].(models.Companies)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCompany indicates an expected call of UpdateCompany.
+func (mr *MockServiceMockRecorder) UpdateCompany(ctx, companyId, uc, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCompany", reflect.TypeOf((*MockService)(nil).UpdateCompany), ctx, companyId, uc, userId)
+}
+
+// UpdateJob mocks base method.
+func (m *MockService) UpdateJob(ctx context.Context, jobID uint64, uj models.UpdateJob, userId string) (models.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateJob", ctx, jobID, uj, userId)
+	ret0, _ := ret[0].(models.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateJob indicates an expected call of UpdateJob.
+func (mr *MockServiceMockRecorder) UpdateJob(ctx, jobID, uj, userId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateJob", reflect.TypeOf((*MockService)(nil).UpdateJob), ctx, jobID, uj, userId)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockService) VerifyEmail(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockServiceMockRecorder) VerifyEmail(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockService)(nil).VerifyEmail), ctx, token)
+}
+
+// VerifyMFA mocks base method.
+func (m *MockService) VerifyMFA(ctx context.Context, mfaToken, code string) (auth.Claims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyMFA", ctx, mfaToken, code)
+	ret0, _ := ret[0].(auth.Claims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyMFA indicates an expected call of VerifyMFA.
+func (mr *MockServiceMockRecorder) VerifyMFA(ctx, mfaToken, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyMFA", reflect.TypeOf((*MockService)(nil).VerifyMFA), ctx, mfaToken, code)
+}
+
+// ViewCompanies mocks base method.
+func (m *MockService) ViewCompanies(ctx context.Context, companyId string) ([]models.Companies, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ViewCompanies", ctx, companyId)
+	ret0, _ := ret[0].([]models.Companies)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ViewCompanies indicates an expected call of ViewCompanies.
+func (mr *MockServiceMockRecorder) ViewCompanies(ctx, companyId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewCompanies", reflect.TypeOf((*MockService)(nil).ViewCompanies), ctx, companyId)
+}
+
+// ViewCompaniesById mocks base method.
+func (m *MockService) ViewCompaniesById(ctx context.Context, companybyid uint, userId string) ([]models.Companies, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ViewCompaniesById", ctx, companybyid, userId)
+	ret0, _ := ret[0].([]models.Companies)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-func (mr *MockServiceMockRecorder) JobsById(ctx, jobById, userId interface{}) *gomock.Call {
+// ViewCompaniesById indicates an expected call of ViewCompaniesById.
+func (mr *MockServiceMockRecorder) ViewCompaniesById(ctx, companybyid, userId any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "api/jobs/:jobID", reflect.TypeOf((*MockService)(nil).JobsById), ctx, jobById, userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewCompaniesById", reflect.TypeOf((*MockService)(nil).ViewCompaniesById), ctx, companybyid, userId)
 }
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"job-portal-api/internal/auth"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/oidc"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// SSO configures the single sign-on with an OpenID Connect provider, which is disabled when Provider is nil. Users
+// the provider vouches for are matched to accounts by email address, so only providers that verify addresses can be
+// trusted, and only accounts whose address was verified are matched. Users without an account get one with
+// DefaultRole. When AllowedDomains is set, only addresses in these
+// domains can log in this way.
+type SSO struct {
+	Provider       *oidc.Provider
+	DefaultRole    string
+	AllowedDomains []string
+}
+
+// BeginOIDCLogin starts a login at the single sign-on provider. The state, nonce and code verifier it returns have
+// to be passed back to CompleteOIDCLogin.
+func (s *Store) BeginOIDCLogin(ctx context.Context) (models.OIDCLogin, error) {
+	p := s.Accounts.SSO.Provider
+	if p == nil {
+		return models.OIDCLogin{}, errOIDCDisabled
+	}
+
+	var values [3]string
+	for i := range values {
+		v, err := oidc.RandomValue()
+		if err != nil {
+			return models.OIDCLogin{}, err
+		}
+		values[i] = v
+	}
+	login := models.OIDCLogin{State: values[0], Nonce: values[1], CodeVerifier: values[2]}
+
+	authURL, err := p.AuthCodeURL(ctx, login.State, login.Nonce, login.CodeVerifier)
+	if err != nil {
+		return models.OIDCLogin{}, err
+	}
+	login.AuthorizationURL = authURL
+	return login, nil
+}
+
+// CompleteOIDCLogin redeems the code the single sign-on provider sent the user back with, and logs in the account
+// of the user, linking or creating it on the first login. Like Authenticate, it returns an mfa token to pass to
+// VerifyMFA instead of claims when the user has two-factor authentication.
+func (s *Store) CompleteOIDCLogin(ctx context.Context, code string, codeVerifier string, nonce string) (auth.Claims,
+	string, error) {
+
+	p := s.Accounts.SSO.Provider
+	if p == nil {
+		return auth.Claims{}, "", errOIDCDisabled
+	}
+	id, err := p.Exchange(ctx, code, codeVerifier, nonce)
+	if errors.Is(err, oidc.ErrLoginFailed) {
+		log.Info().Err(err).Msg("single sign-on refused")
+		return auth.Claims{}, "", ErrOIDCLoginFailed
+	}
+	if err != nil {
+		return auth.Claims{}, "", err
+	}
+
+	u, err := s.oidcUser(ctx, id)
+	if err != nil {
+		return auth.Claims{}, "", err
+	}
+	if u.TOTPEnabledAt != nil {
+		mfaToken, err := s.issueMFAChallenge(u, time.Now())
+		if err != nil {
+			return auth.Claims{}, "", err
+		}
+		return auth.Claims{}, mfaToken, nil
+	}
+	return auth.NewClaims(strconv.FormatUint(uint64(u.ID), 10), u.Role), "", nil
+}
+
+// oidcUser returns the account of the user the provider vouched for. An account already linked to them is used
+// as is. Otherwise the account with their email address is linked to them, or one is created for them. An account
+// whose address was never verified isn't linked, as whoever registered it may not own the address and could keep
+// logging in with their password next to its owner.
+func (s *Store) oidcUser(ctx context.Context, id oidc.Identity) (models.User, error) {
+	if !s.ssoDomainAllowed(id.Email) {
+		return models.User{}, errOIDCDomainNotAllowed
+	}
+
+	u, err := s.UserRepo.ViewUserByOIDC(ctx, id.Issuer, id.Subject)
+	if err == nil {
+		return u, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.User{}, err
+	}
+
+	// Whoever controls the address owns the account, which only holds when the provider checked they do
+	if !id.EmailVerified || id.Email == "" {
+		return models.User{}, errOIDCEmailNotVerified
+	}
+
+	// Addresses are neither unique nor stored in one case, so when several accounts match it is unclear which one
+	// is the user's
+	email := strings.ToLower(strings.TrimSpace(id.Email))
+	users, err := s.UserRepo.ViewUsersByEmail(ctx, email)
+	if err != nil {
+		return models.User{}, err
+	}
+	if len(users) > 1 {
+		log.Info().Str("issuer", id.Issuer).Int("accounts", len(users)).Msg("single sign-on address matches several accounts")
+		return models.User{}, errOIDCAccountAmbiguous
+	}
+	if len(users) == 1 {
+		u = users[0]
+		if u.OIDCSubject != nil {
+			return models.User{}, errOIDCAccountLinked
+		}
+		if u.EmailVerifiedAt == nil {
+			return models.User{}, errOIDCAccountUnverified
+		}
+		err = s.UserRepo.LinkOIDC(ctx, u.ID, id.Issuer, id.Subject)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Linked concurrently
+			return models.User{}, errOIDCAccountLinked
+		}
+		if err != nil {
+			return models.User{}, err
+		}
+		log.Info().Uint("user id", u.ID).Str("issuer", id.Issuer).Msg("account linked to single sign-on")
+		return u, nil
+	}
+
+	// The account has no password, the user can set one through the password reset if they want to. Names are
+	// unique, so the email address stands in for it.
+	now := time.Now()
+	u, err = s.UserRepo.CreateUser(ctx, models.User{
+		Name:            email,
+		Email:           email,
+		Role:            s.Accounts.SSO.DefaultRole,
+		EmailVerifiedAt: &now,
+		OIDCIssuer:      &id.Issuer,
+		OIDCSubject:     &id.Subject,
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.User{}, newError(ErrConflict, "a user named %s already exists", email)
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+	log.Info().Uint("user id", u.ID).Str("issuer", id.Issuer).Msg("account created by single sign-on")
+	return u, nil
+}
+
+// ssoDomainAllowed reports whether users with the given email address may log in with single sign-on.
+func (s *Store) ssoDomainAllowed(email string) bool {
+	allowed := s.Accounts.SSO.AllowedDomains
+	if len(allowed) == 0 {
+		return true
+	}
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return false
+	}
+	domain := email[at+1:]
+	for _, d := range allowed {
+		if strings.EqualFold(d, domain) {
+			return true
+		}
+	}
+	return false
+}
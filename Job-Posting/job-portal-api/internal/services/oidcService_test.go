@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"job-portal-api/internal/auth"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/oidc"
+	"job-portal-api/internal/oidc/oidctest"
+)
+
+// newSSOStore returns a Store whose single sign-on goes to a stub provider.
+func newSSOStore(t *testing.T) (*Store, *oidctest.Server) {
+	t.Helper()
+	s, _ := newAccountStore(t)
+	idp := oidctest.New(t, "job-portal")
+	s.Accounts.SSO = SSO{
+		Provider:    oidc.New(idp.Config("http://localhost:8081/api/oidc/callback"), idp.Client()),
+		DefaultRole: models.RoleRecruiter,
+	}
+	return s, idp
+}
+
+// ssoLogin logs in at the stub provider as u and completes the login.
+func ssoLogin(t *testing.T, s *Store, idp *oidctest.Server, u oidctest.User) (auth.Claims, string, error) {
+	t.Helper()
+	ctx := context.Background()
+	idp.SetUser(u)
+	login, err := s.BeginOIDCLogin(ctx)
+	require.NoError(t, err)
+	code, state, err := idp.Authorize(login.AuthorizationURL)
+	require.NoError(t, err)
+	require.Equal(t, login.State, state)
+	return s.CompleteOIDCLogin(ctx, code, login.CodeVerifier, login.Nonce)
+}
+
+func TestOIDCLoginProvisions(t *testing.T) {
+	ctx := context.Background()
+	s, idp := newSSOStore(t)
+	alice := oidctest.User{Subject: "alice", Email: "alice@example.com", EmailVerified: true}
+
+	claims, mfaToken, err := ssoLogin(t, s, idp, alice)
+	require.NoError(t, err)
+	require.Empty(t, mfaToken)
+	require.Equal(t, []string{models.RoleRecruiter}, claims.Roles)
+
+	u, err := s.UserRepo.ViewUserByEmail(ctx, "alice@example.com")
+	require.NoError(t, err)
+	require.Equal(t, strconv.FormatUint(uint64(u.ID), 10), claims.Subject)
+	require.NotNil(t, u.EmailVerifiedAt)
+	require.Empty(t, u.PasswordHash)
+
+	// The next login finds the same account by subject, even once the address changed at the provider
+	alice.Email = "alice@new.example.com"
+	again, _, err := ssoLogin(t, s, idp, alice)
+	require.NoError(t, err)
+	require.Equal(t, claims.Subject, again.Subject)
+
+	// The account has no password to log in with
+	_, _, err = s.Authenticate(ctx, "alice@example.com", "")
+	require.ErrorIs(t, err, errInvalidCredentials)
+}
+
+func TestOIDCLoginLinks(t *testing.T) {
+	ctx := context.Background()
+	s, idp := newSSOStore(t)
+	u, err := s.CreateUser(ctx, models.NewUser{Name: "stym", Email: "stym@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+
+	// An address the provider didn't verify isn't enough to take the account over
+	_, _, err = ssoLogin(t, s, idp, oidctest.User{Subject: "stym", Email: "stym@email.com"})
+	require.ErrorIs(t, err, errOIDCEmailNotVerified)
+
+	// Nor is an account whose address was never verified, whoever registered it may not own the address
+	_, _, err = ssoLogin(t, s, idp, oidctest.User{Subject: "stym", Email: "stym@email.com", EmailVerified: true})
+	require.ErrorIs(t, err, errOIDCAccountUnverified)
+	stored, err := s.UserRepo.ViewUserById(ctx, u.ID)
+	require.NoError(t, err)
+	require.Nil(t, stored.OIDCSubject)
+	require.Nil(t, stored.EmailVerifiedAt)
+
+	// Once verified, the account is linked whatever the case of the address
+	require.NoError(t, s.UserRepo.MarkEmailVerified(ctx, u.ID, time.Now()))
+	claims, _, err := ssoLogin(t, s, idp, oidctest.User{Subject: "stym", Email: "Stym@Email.com", EmailVerified: true})
+	require.NoError(t, err)
+	require.Equal(t, strconv.FormatUint(uint64(u.ID), 10), claims.Subject)
+	// The role of the account is kept
+	require.Equal(t, []string{models.RoleCandidate}, claims.Roles)
+
+	// Another user of the provider with the same address can't log in to it
+	_, _, err = ssoLogin(t, s, idp, oidctest.User{Subject: "impostor", Email: "stym@email.com", EmailVerified: true})
+	require.ErrorIs(t, err, errOIDCAccountLinked)
+}
+
+func TestOIDCLoginAmbiguous(t *testing.T) {
+	ctx := context.Background()
+	s, idp := newSSOStore(t)
+
+	// Addresses aren't unique, so it's unclear which account the user owns
+	for _, nu := range []models.NewUser{
+		{Name: "stym", Email: "stym@email.com", Password: "Passw0rd123"},
+		{Name: "other", Email: "STYM@email.com", Password: "Passw0rd123"},
+	} {
+		u, err := s.CreateUser(ctx, nu)
+		require.NoError(t, err)
+		require.NoError(t, s.UserRepo.MarkEmailVerified(ctx, u.ID, time.Now()))
+	}
+
+	_, _, err := ssoLogin(t, s, idp, oidctest.User{Subject: "stym", Email: "stym@email.com", EmailVerified: true})
+	require.ErrorIs(t, err, errOIDCAccountAmbiguous)
+}
+
+func TestOIDCLoginMFA(t *testing.T) {
+	ctx := context.Background()
+	s, idp := newSSOStore(t)
+	u, err := createRecruiter(ctx, s, models.NewUser{Name: "stym", Email: "stym@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	require.NoError(t, s.UserRepo.MarkEmailVerified(ctx, u.ID, time.Now()))
+	enableTOTP(t, s, strconv.FormatUint(uint64(u.ID), 10))
+
+	claims, mfaToken, err := ssoLogin(t, s, idp, oidctest.User{Subject: "stym", Email: "stym@email.com", EmailVerified: true})
+	require.NoError(t, err)
+	require.Empty(t, claims.Subject)
+	require.NotEmpty(t, mfaToken)
+}
+
+func TestOIDCLoginErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled", func(t *testing.T) {
+		s, _ := newAccountStore(t)
+		_, err := s.BeginOIDCLogin(ctx)
+		require.ErrorIs(t, err, errOIDCDisabled)
+		_, _, err = s.CompleteOIDCLogin(ctx, "code", "verifier", "nonce")
+		require.ErrorIs(t, err, errOIDCDisabled)
+	})
+
+	t.Run("domain not allowed", func(t *testing.T) {
+		s, idp := newSSOStore(t)
+		s.Accounts.SSO.AllowedDomains = []string{"example.com"}
+		_, _, err := ssoLogin(t, s, idp, oidctest.User{Subject: "eve", Email: "eve@evil.example", EmailVerified: true})
+		require.ErrorIs(t, err, errOIDCDomainNotAllowed)
+		_, _, err = ssoLogin(t, s, idp, oidctest.User{Subject: "bob", Email: "bob@EXAMPLE.com", EmailVerified: true})
+		require.NoError(t, err)
+	})
+
+	t.Run("wrong verifier", func(t *testing.T) {
+		s, idp := newSSOStore(t)
+		login, err := s.BeginOIDCLogin(ctx)
+		require.NoError(t, err)
+		code, _, err := idp.Authorize(login.AuthorizationURL)
+		require.NoError(t, err)
+		_, _, err = s.CompleteOIDCLogin(ctx, code, "guessed", login.Nonce)
+		require.ErrorIs(t, err, ErrOIDCLoginFailed)
+		require.ErrorIs(t, err, ErrUnauthenticated)
+	})
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/repository"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// Pipeline is the state machine applications move through. It maps every stage to the stages an application may
+// be moved to next. Stages without any next stage are final.
+type Pipeline map[string][]string
+
+// DefaultPipeline is the hiring pipeline used unless the Store is given another one. Candidates move forward one
+// stage at a time and can be rejected from any stage that isn't final.
+func DefaultPipeline() Pipeline {
+	return Pipeline{
+		models.ApplicationStatusApplied:   {models.ApplicationStatusScreening, models.ApplicationStatusRejected},
+		models.ApplicationStatusScreening: {models.ApplicationStatusInterview, models.ApplicationStatusRejected},
+		models.ApplicationStatusInterview: {models.ApplicationStatusOffer, models.ApplicationStatusRejected},
+		models.ApplicationStatusOffer:     {models.ApplicationStatusHired, models.ApplicationStatusRejected},
+		models.ApplicationStatusHired:     nil,
+		models.ApplicationStatusRejected:  nil,
+	}
+}
+
+// CanTransition reports whether an application in stage from may be moved to stage to.
+func (p Pipeline) CanTransition(from, to string) bool {
+	for _, next := range p[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionApplication moves an application to another stage of the pipeline and records who did it.
+// Only the owner of the company that posted the job may move its applications.
+func (s *Store) TransitionApplication(ctx context.Context, applicationID uint64, nc models.NewStatusChange, userID string) (models.Application, error) {
+	application, err := s.applicationForOwner(ctx, applicationID, userID)
+	if err != nil {
+		return models.Application{}, err
+	}
+
+	if !s.Pipeline.CanTransition(application.Status, nc.Status) {
+		return models.Application{}, newError(ErrInvalidTransition, "an application can't be moved from %s to %s", application.Status, nc.Status)
+	}
+
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return models.Application{}, fmt.Errorf("parsing user id %q: %w", userID, err)
+	}
+
+	application, err = s.UserRepo.TransitionApplication(ctx, models.ApplicationStatusChange{
+		ApplicationID: application.ID,
+		FromStatus:    application.Status,
+		ToStatus:      nc.Status,
+		ChangedBy:     uint(uid),
+		Note:          nc.Note,
+	})
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return models.Application{}, newError(ErrConflict, "application was updated by someone else, please retry")
+	}
+	if err != nil {
+		return models.Application{}, err
+	}
+	return application, nil
+}
+
+// ApplicationTimeline returns the status changes of an application, oldest first. It is visible to the candidate
+// who applied and to the owner of the company that posted the job. The candidate only sees when their application
+// moved, not who moved it nor the notes the recruiters wrote for each other.
+func (s *Store) ApplicationTimeline(ctx context.Context, applicationID uint64, userID string) ([]models.ApplicationStatusChange, error) {
+	application, err := s.UserRepo.ViewApplicationById(ctx, uint(applicationID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errApplicationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if strconv.FormatUint(uint64(application.UserID), 10) != userID {
+		_, err = s.applicationForOwner(ctx, applicationID, userID)
+		if err != nil {
+			return nil, err
+		}
+		return s.UserRepo.ViewApplicationTimeline(ctx, application.ID)
+	}
+
+	timeline, err := s.UserRepo.ViewApplicationTimeline(ctx, application.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range timeline {
+		timeline[i].ChangedBy = 0
+		timeline[i].Note = ""
+	}
+	return timeline, nil
+}
+
+// applicationForOwner loads an application after making sure the user owns the company that posted the job.
+func (s *Store) applicationForOwner(ctx context.Context, applicationID uint64, userID string) (models.Application, error) {
+	application, err := s.UserRepo.ViewApplicationById(ctx, uint(applicationID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Application{}, errApplicationNotFound
+	}
+	if err != nil {
+		return models.Application{}, err
+	}
+
+	job, err := s.UserRepo.ViewJobDetailsBy(ctx, uint64(application.JobID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Application{}, errJobNotFound
+	}
+	if err != nil {
+		return models.Application{}, err
+	}
+	err = s.checkCompanyOwner(ctx, job.CompanyID, userID)
+	if err != nil {
+		return models.Application{}, err
+	}
+	return application, nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"job-portal-api/internal/models"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultPipeline(t *testing.T) {
+	p := DefaultPipeline()
+
+	tt := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{models.ApplicationStatusApplied, models.ApplicationStatusScreening, true},
+		{models.ApplicationStatusScreening, models.ApplicationStatusInterview, true},
+		{models.ApplicationStatusInterview, models.ApplicationStatusOffer, true},
+		{models.ApplicationStatusOffer, models.ApplicationStatusHired, true},
+		{models.ApplicationStatusInterview, models.ApplicationStatusRejected, true},
+		{models.ApplicationStatusApplied, models.ApplicationStatusHired, false},
+		{models.ApplicationStatusOffer, models.ApplicationStatusScreening, false},
+		{models.ApplicationStatusHired, models.ApplicationStatusRejected, false},
+		{models.ApplicationStatusRejected, models.ApplicationStatusScreening, false},
+		{models.ApplicationStatusApplied, "unknown", false},
+	}
+	for _, tc := range tt {
+		t.Run(tc.from+"->"+tc.to, func(t *testing.T) {
+			require.Equal(t, tc.allowed, p.CanTransition(tc.from, tc.to))
+		})
+	}
+}
+
+func TestApplicationTimeline(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newAccountStore(t)
+
+	recruiter, err := createRecruiter(ctx, s, models.NewUser{Name: "stym", Email: "stym@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	require.NoError(t, s.UserRepo.MarkEmailVerified(ctx, recruiter.ID, time.Now()))
+	recruiterID := strconv.FormatUint(uint64(recruiter.ID), 10)
+	c, err := s.CreatCompanies(ctx, models.NewComapanies{CompanyName: "tek", Location: "banglore"}, recruiter.ID)
+	require.NoError(t, err)
+	job, err := s.CreateJob(ctx, models.NewJob{Title: "dev"}, c.ID, recruiterID)
+	require.NoError(t, err)
+	_, err = s.PublishJob(ctx, uint64(job.ID), recruiterID)
+	require.NoError(t, err)
+
+	candidate, err := s.CreateUser(ctx, models.NewUser{Name: "alice", Email: "alice@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	candidateID := strconv.FormatUint(uint64(candidate.ID), 10)
+	application, err := s.Apply(ctx, uint64(job.ID), models.NewApplication{ResumeURL: "https://cv.example/alice"}, candidateID)
+	require.NoError(t, err)
+	_, err = s.TransitionApplication(ctx, uint64(application.ID),
+		models.NewStatusChange{Status: models.ApplicationStatusScreening, Note: "salary expectations too high"}, recruiterID)
+	require.NoError(t, err)
+
+	timeline, err := s.ApplicationTimeline(ctx, uint64(application.ID), recruiterID)
+	require.NoError(t, err)
+	require.Len(t, timeline, 2)
+	require.Equal(t, recruiter.ID, timeline[1].ChangedBy)
+	require.Equal(t, "salary expectations too high", timeline[1].Note)
+
+	// The candidate sees how the application moved, not the notes of the recruiters
+	timeline, err = s.ApplicationTimeline(ctx, uint64(application.ID), candidateID)
+	require.NoError(t, err)
+	require.Len(t, timeline, 2)
+	require.Equal(t, models.ApplicationStatusScreening, timeline[1].ToStatus)
+	require.Zero(t, timeline[1].ChangedBy)
+	require.Empty(t, timeline[1].Note)
+
+	// Nor can anyone else read it
+	other, err := s.CreateUser(ctx, models.NewUser{Name: "bob", Email: "bob@email.com", Password: "Passw0rd123"})
+	require.NoError(t, err)
+	_, err = s.ApplicationTimeline(ctx, uint64(application.ID), strconv.FormatUint(uint64(other.ID), 10))
+	require.ErrorIs(t, err, errNotCompanyOwner)
+}
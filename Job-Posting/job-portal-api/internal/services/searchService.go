@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+	"job-portal-api/internal/models"
+)
+
+// DefaultSearchPageSize is the number of search results returned per page when the client doesn't ask for a limit.
+const DefaultSearchPageSize = 20
+
+// Search runs a full-text search over jobs and the names of the companies that posted them.
+func (s *Store) Search(ctx context.Context, sq models.SearchQuery, userId string) (models.SearchPage, error) {
+	if sq.Limit <= 0 {
+		sq.Limit = DefaultSearchPageSize
+	}
+	if sq.Page <= 0 {
+		sq.Page = 1
+	}
+
+	page, err := s.UserRepo.SearchJobs(ctx, sq.Query, sq.Limit, (sq.Page-1)*sq.Limit)
+	if err != nil {
+		return models.SearchPage{}, err
+	}
+	page.Page = sq.Page
+	return page, nil
+}
@@ -3,35 +3,77 @@ package services
 import (
 	"context"
 	"errors"
+	"job-portal-api/internal/auth"
 	"job-portal-api/internal/models"
 	"job-portal-api/internal/repository"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
-//go:generate mockgen -source service.go -destination mockmodels/service_mock.go -package mockmodels
+//go:generate mockgen -source services.go -destination mock_service.go -package services
 
 type Service interface {
 	CreatCompanies(ctx context.Context, nc models.NewComapanies, UserId uint) (models.Companies, error)
 	ViewCompanies(ctx context.Context, companyId string) ([]models.Companies, error)
 	ViewCompaniesById(ctx context.Context, companybyid uint, userId string) ([]models.Companies, error)
+	UpdateCompany(ctx context.Context, companyId uint, uc models.UpdateCompany, userId string) (models.Companies, error)
+	DeleteCompany(ctx context.Context, companyId uint, userId string) error
+	RestoreCompany(ctx context.Context, companyId uint) (models.Companies, error)
 	CreateUser(ctx context.Context, nu models.NewUser) (models.User, error)
-	CreateJob(ctx context.Context, newJob models.Job, userId string) (models.Job, error)
-	AllJob(ctx context.Context, userId string) ([]models.Job, error)
+	SetUserRole(ctx context.Context, userID uint, ur models.UserRole) (models.User, error)
+	CreateJob(ctx context.Context, newJob models.NewJob, companyId uint, userId string) (models.Job, error)
+	AllJob(ctx context.Context, f models.JobFilter, userId string) (models.JobPage, error)
+	Search(ctx context.Context, sq models.SearchQuery, userId string) (models.SearchPage, error)
 	ListJobs(ctx context.Context, companyId uint, userId string) ([]models.Job, error)
-	Authenticate(ctx context.Context, email, password string) (jwt.RegisteredClaims,
+	JobsByID(ctx context.Context, jobID uint64, userId string) (models.Job, error)
+	PublishJob(ctx context.Context, jobID uint64, userId string) (models.Job, error)
+	CloseJob(ctx context.Context, jobID uint64, userId string) (models.Job, error)
+	ExpireJobs(ctx context.Context) (int64, error)
+	UpdateJob(ctx context.Context, jobID uint64, uj models.UpdateJob, userId string) (models.Job, error)
+	DeleteJob(ctx context.Context, jobID uint64, userId string) error
+	RestoreJob(ctx context.Context, jobID uint64) (models.Job, error)
+	Authenticate(ctx context.Context, email, password string) (auth.Claims, string,
 		error)
+	Apply(ctx context.Context, jobID uint64, na models.NewApplication, userId string) (models.Application, error)
+	MyApplications(ctx context.Context, userId string) ([]models.Application, error)
+	JobApplications(ctx context.Context, jobID uint64, userId string) ([]models.Application, error)
+	TransitionApplication(ctx context.Context, applicationID uint64, nc models.NewStatusChange, userId string) (models.Application, error)
+	ApplicationTimeline(ctx context.Context, applicationID uint64, userId string) ([]models.ApplicationStatusChange, error)
+	IssueRefreshToken(ctx context.Context, userID string) (string, error)
+	Refresh(ctx context.Context, refreshToken string) (auth.Claims, string, error)
+	Logout(ctx context.Context, claims auth.Claims, refreshToken string) error
+	SendVerificationEmail(ctx context.Context, userId string) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, password string) error
+	BeginTOTPEnrollment(ctx context.Context, userID string) (models.TOTPEnrollment, error)
+	ConfirmTOTPEnrollment(ctx context.Context, userID string, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID string, code string) error
+	VerifyMFA(ctx context.Context, mfaToken string, code string) (auth.Claims, error)
+	CreateAPIKey(ctx context.Context, companyID uint, nk models.NewAPIKey, userID string) (models.CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context, companyID uint, userID string) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, companyID uint, keyID uint, userID string) error
+	AuthenticateAPIKey(ctx context.Context, key string) (auth.Claims, error)
+	BeginOIDCLogin(ctx context.Context) (models.OIDCLogin, error)
+	CompleteOIDCLogin(ctx context.Context, code string, codeVerifier string, nonce string) (auth.Claims, string, error)
 }
 
 type Store struct {
 	UserRepo repository.UserRepo
+	Pipeline Pipeline
+	Lockout  LockoutPolicy
+	Accounts Accounts
 }
 
-func NewStore(userRepo repository.UserRepo) (Service, error) {
+func NewStore(userRepo repository.UserRepo, accounts Accounts) (Service, error) {
 	if userRepo == nil {
 		return nil, errors.New("interface cannot be null")
 	}
+	if accounts.Mailer == nil || accounts.Tokens == nil {
+		return nil, errors.New("accounts need a mailer and a token signer")
+	}
 	return &Store{
 		UserRepo: userRepo,
+		Pipeline: DefaultPipeline(),
+		Lockout:  DefaultLockoutPolicy(),
+		Accounts: accounts,
 	}, nil
 }
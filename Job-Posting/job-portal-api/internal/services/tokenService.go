@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"job-portal-api/internal/auth"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/repository"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshTokenTTL is how long a refresh token can be used to obtain new access tokens.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// IssueRefreshToken creates a new refresh token for the user and returns it in plain text.
+// Only its hash is stored, so the plain token can't be recovered afterwards.
+func (s *Store) IssueRefreshToken(ctx context.Context, userID string) (string, error) {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("parsing user id %q: %w", userID, err)
+	}
+
+	plain, rt, err := newRefreshToken(uint(uid))
+	if err != nil {
+		return "", err
+	}
+	_, err = s.UserRepo.CreateRefreshToken(ctx, rt)
+	if err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+// Refresh exchanges a refresh token for the claims of a new access token and a new refresh token.
+// The presented refresh token is revoked. If an already revoked token is presented, it is treated as stolen and
+// every refresh token of the user is revoked.
+func (s *Store) Refresh(ctx context.Context, refreshToken string) (auth.Claims, string, error) {
+	rt, err := s.UserRepo.FindRefreshToken(ctx, hashToken(refreshToken))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return auth.Claims{}, "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return auth.Claims{}, "", err
+	}
+
+	if rt.RevokedAt != nil {
+		err = s.UserRepo.RevokeUserRefreshTokens(ctx, rt.UserID)
+		if err != nil {
+			return auth.Claims{}, "", err
+		}
+		return auth.Claims{}, "", ErrInvalidRefreshToken
+	}
+	if time.Now().After(rt.ExpiresAt) {
+		return auth.Claims{}, "", ErrInvalidRefreshToken
+	}
+
+	u, err := s.UserRepo.ViewUserById(ctx, rt.UserID)
+	if err != nil {
+		return auth.Claims{}, "", err
+	}
+
+	plain, next, err := newRefreshToken(u.ID)
+	if err != nil {
+		return auth.Claims{}, "", err
+	}
+	_, err = s.UserRepo.RotateRefreshToken(ctx, rt.ID, next)
+	if errors.Is(err, repository.ErrTokenAlreadyUsed) {
+		return auth.Claims{}, "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return auth.Claims{}, "", err
+	}
+
+	claims := auth.NewClaims(strconv.FormatUint(uint64(u.ID), 10), u.Role)
+	return claims, plain, nil
+}
+
+// Logout revokes the access token described by claims and, if given, the refresh token of the session. The refresh
+// token must belong to the user of the claims and not be revoked yet.
+func (s *Store) Logout(ctx context.Context, claims auth.Claims, refreshToken string) error {
+	expiresAt := time.Now().Add(auth.AccessTokenTTL)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	err := s.UserRepo.RevokeToken(ctx, claims.ID, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	uid, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parsing user id %q: %w", claims.Subject, err)
+	}
+	err = s.UserRepo.RevokeRefreshToken(ctx, uint(uid), hashToken(refreshToken))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errRefreshTokenUnknown
+	}
+	return err
+}
+
+// newRefreshToken generates a random refresh token for the user. It returns the plain token, which is handed to
+// the client, and the record to be stored.
+func newRefreshToken(userID uint) (string, models.RefreshToken, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", models.RefreshToken{}, fmt.Errorf("generating refresh token: %w", err)
+	}
+	plain := base64.RawURLEncoding.EncodeToString(b)
+
+	rt := models.RefreshToken{
+		UserID:    userID,
+		TokenHash: hashToken(plain),
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	}
+	return plain, rt, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
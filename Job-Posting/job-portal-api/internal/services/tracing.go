@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"job-portal-api/internal/auth"
+	"job-portal-api/internal/models"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "job-portal-api/internal/services"
+
+// traced wraps a Service and records a span around every call, as a child of the span in the context of the call.
+type traced struct {
+	next Service
+}
+
+// WithTracing returns a Service that traces every call before passing it on to s.
+func WithTracing(s Service) Service {
+	return traced{next: s}
+}
+
+func startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, "services."+method)
+}
+
+// endSpan ends span and records err on it. Domain errors are caused by the request and don't mark the span as
+// failed, any other error does.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		var de *Error
+		if !errors.As(err, &de) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+func (t traced) CreatCompanies(ctx context.Context, nc models.NewComapanies, UserId uint) (models.Companies, error) {
+	ctx, span := startSpan(ctx, "CreatCompanies")
+	v, err := t.next.CreatCompanies(ctx, nc, UserId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) ViewCompanies(ctx context.Context, companyId string) ([]models.Companies, error) {
+	ctx, span := startSpan(ctx, "ViewCompanies")
+	v, err := t.next.ViewCompanies(ctx, companyId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) ViewCompaniesById(ctx context.Context, companybyid uint, userId string) ([]models.Companies, error) {
+	ctx, span := startSpan(ctx, "ViewCompaniesById")
+	v, err := t.next.ViewCompaniesById(ctx, companybyid, userId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) UpdateCompany(ctx context.Context, companyId uint, uc models.UpdateCompany, userId string) (models.Companies, error) {
+	ctx, span := startSpan(ctx, "UpdateCompany")
+	v, err := t.next.UpdateCompany(ctx, companyId, uc, userId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) DeleteCompany(ctx context.Context, companyId uint, userId string) error {
+	ctx, span := startSpan(ctx, "DeleteCompany")
+	err := t.next.DeleteCompany(ctx, companyId, userId)
+	endSpan(span, err)
+	return err
+}
+
+func (t traced) RestoreCompany(ctx context.Context, companyId uint) (models.Companies, error) {
+	ctx, span := startSpan(ctx, "RestoreCompany")
+	v, err := t.next.RestoreCompany(ctx, companyId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) CreateUser(ctx context.Context, nu models.NewUser) (models.User, error) {
+	ctx, span := startSpan(ctx, "CreateUser")
+	v, err := t.next.CreateUser(ctx, nu)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) SetUserRole(ctx context.Context, userID uint, ur models.UserRole) (models.User, error) {
+	ctx, span := startSpan(ctx, "SetUserRole")
+	v, err := t.next.SetUserRole(ctx, userID, ur)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) CreateJob(ctx context.Context, newJob models.NewJob, companyId uint, userId string) (models.Job, error) {
+	ctx, span := startSpan(ctx, "CreateJob")
+	v, err := t.next.CreateJob(ctx, newJob, companyId, userId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) AllJob(ctx context.Context, f models.JobFilter, userId string) (models.JobPage, error) {
+	ctx, span := startSpan(ctx, "AllJob")
+	v, err := t.next.AllJob(ctx, f, userId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) Search(ctx context.Context, sq models.SearchQuery, userId string) (models.SearchPage, error) {
+	ctx, span := startSpan(ctx, "Search")
+	v, err := t.next.Search(ctx, sq, userId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) ListJobs(ctx context.Context, companyId uint, userId string) ([]models.Job, error) {
+	ctx, span := startSpan(ctx, "ListJobs")
+	v, err := t.next.ListJobs(ctx, companyId, userId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) JobsByID(ctx context.Context, jobID uint64, userId string) (models.Job, error) {
+	ctx, span := startSpan(ctx, "JobsByID")
+	v, err := t.next.JobsByID(ctx, jobID, userId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) PublishJob(ctx context.Context, jobID uint64, userId string) (models.Job, error) {
+	ctx, span := startSpan(ctx, "PublishJob")
+	v, err := t.next.PublishJob(ctx, jobID, userId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) CloseJob(ctx context.Context, jobID uint64, userId string) (models.Job, error) {
+	ctx, span := startSpan(ctx, "CloseJob")
+	v, err := t.next.CloseJob(ctx, jobID, userId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) ExpireJobs(ctx context.Context) (int64, error) {
+	ctx, span := startSpan(ctx, "ExpireJobs")
+	v, err := t.next.ExpireJobs(ctx)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) UpdateJob(ctx context.Context, jobID uint64, uj models.UpdateJob, userId string) (models.Job, error) {
+	ctx, span := startSpan(ctx, "UpdateJob")
+	v, err := t.next.UpdateJob(ctx, jobID, uj, userId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) DeleteJob(ctx context.Context, jobID uint64, userId string) error {
+	ctx, span := startSpan(ctx, "DeleteJob")
+	err := t.next.DeleteJob(ctx, jobID, userId)
+	endSpan(span, err)
+	return err
+}
+
+func (t traced) RestoreJob(ctx context.Context, jobID uint64) (models.Job, error) {
+	ctx, span := startSpan(ctx, "RestoreJob")
+	v, err := t.next.RestoreJob(ctx, jobID)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) Authenticate(ctx context.Context, email, password string) (auth.Claims, string, error) {
+	ctx, span := startSpan(ctx, "Authenticate")
+	v, mfaToken, err := t.next.Authenticate(ctx, email, password)
+	endSpan(span, err)
+	return v, mfaToken, err
+}
+
+func (t traced) Apply(ctx context.Context, jobID uint64, na models.NewApplication, userId string) (models.Application, error) {
+	ctx, span := startSpan(ctx, "Apply")
+	v, err := t.next.Apply(ctx, jobID, na, userId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) MyApplications(ctx context.Context, userId string) ([]models.Application, error) {
+	ctx, span := startSpan(ctx, "MyApplications")
+	v, err := t.next.MyApplications(ctx, userId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) JobApplications(ctx context.Context, jobID uint64, userId string) ([]models.Application, error) {
+	ctx, span := startSpan(ctx, "JobApplications")
+	v, err := t.next.JobApplications(ctx, jobID, userId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) TransitionApplication(ctx context.Context, applicationID uint64, nc models.NewStatusChange, userId string) (models.Application, error) {
+	ctx, span := startSpan(ctx, "TransitionApplication")
+	v, err := t.next.TransitionApplication(ctx, applicationID, nc, userId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) ApplicationTimeline(ctx context.Context, applicationID uint64, userId string) ([]models.ApplicationStatusChange, error) {
+	ctx, span := startSpan(ctx, "ApplicationTimeline")
+	v, err := t.next.ApplicationTimeline(ctx, applicationID, userId)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) IssueRefreshToken(ctx context.Context, userID string) (string, error) {
+	ctx, span := startSpan(ctx, "IssueRefreshToken")
+	v, err := t.next.IssueRefreshToken(ctx, userID)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) Refresh(ctx context.Context, refreshToken string) (auth.Claims, string, error) {
+	ctx, span := startSpan(ctx, "Refresh")
+	v, w, err := t.next.Refresh(ctx, refreshToken)
+	endSpan(span, err)
+	return v, w, err
+}
+
+func (t traced) Logout(ctx context.Context, claims auth.Claims, refreshToken string) error {
+	ctx, span := startSpan(ctx, "Logout")
+	err := t.next.Logout(ctx, claims, refreshToken)
+	endSpan(span, err)
+	return err
+}
+
+func (t traced) SendVerificationEmail(ctx context.Context, userId string) error {
+	ctx, span := startSpan(ctx, "SendVerificationEmail")
+	err := t.next.SendVerificationEmail(ctx, userId)
+	endSpan(span, err)
+	return err
+}
+
+func (t traced) VerifyEmail(ctx context.Context, token string) error {
+	ctx, span := startSpan(ctx, "VerifyEmail")
+	err := t.next.VerifyEmail(ctx, token)
+	endSpan(span, err)
+	return err
+}
+
+func (t traced) RequestPasswordReset(ctx context.Context, email string) error {
+	ctx, span := startSpan(ctx, "RequestPasswordReset")
+	err := t.next.RequestPasswordReset(ctx, email)
+	endSpan(span, err)
+	return err
+}
+
+func (t traced) ResetPassword(ctx context.Context, token string, password string) error {
+	ctx, span := startSpan(ctx, "ResetPassword")
+	err := t.next.ResetPassword(ctx, token, password)
+	endSpan(span, err)
+	return err
+}
+
+func (t traced) BeginTOTPEnrollment(ctx context.Context, userID string) (models.TOTPEnrollment, error) {
+	ctx, span := startSpan(ctx, "BeginTOTPEnrollment")
+	v, err := t.next.BeginTOTPEnrollment(ctx, userID)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) ConfirmTOTPEnrollment(ctx context.Context, userID string, code string) ([]string, error) {
+	ctx, span := startSpan(ctx, "ConfirmTOTPEnrollment")
+	v, err := t.next.ConfirmTOTPEnrollment(ctx, userID, code)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) DisableTOTP(ctx context.Context, userID string, code string) error {
+	ctx, span := startSpan(ctx, "DisableTOTP")
+	err := t.next.DisableTOTP(ctx, userID, code)
+	endSpan(span, err)
+	return err
+}
+
+func (t traced) VerifyMFA(ctx context.Context, mfaToken string, code string) (auth.Claims, error) {
+	ctx, span := startSpan(ctx, "VerifyMFA")
+	v, err := t.next.VerifyMFA(ctx, mfaToken, code)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) CreateAPIKey(ctx context.Context, companyID uint, nk models.NewAPIKey, userID string) (models.CreatedAPIKey, error) {
+	ctx, span := startSpan(ctx, "CreateAPIKey")
+	v, err := t.next.CreateAPIKey(ctx, companyID, nk, userID)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) ListAPIKeys(ctx context.Context, companyID uint, userID string) ([]models.APIKey, error) {
+	ctx, span := startSpan(ctx, "ListAPIKeys")
+	v, err := t.next.ListAPIKeys(ctx, companyID, userID)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) RevokeAPIKey(ctx context.Context, companyID uint, keyID uint, userID string) error {
+	ctx, span := startSpan(ctx, "RevokeAPIKey")
+	err := t.next.RevokeAPIKey(ctx, companyID, keyID, userID)
+	endSpan(span, err)
+	return err
+}
+
+func (t traced) AuthenticateAPIKey(ctx context.Context, key string) (auth.Claims, error) {
+	ctx, span := startSpan(ctx, "AuthenticateAPIKey")
+	v, err := t.next.AuthenticateAPIKey(ctx, key)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) BeginOIDCLogin(ctx context.Context) (models.OIDCLogin, error) {
+	ctx, span := startSpan(ctx, "BeginOIDCLogin")
+	v, err := t.next.BeginOIDCLogin(ctx)
+	endSpan(span, err)
+	return v, err
+}
+
+func (t traced) CompleteOIDCLogin(ctx context.Context, code string, codeVerifier string, nonce string) (auth.Claims, string, error) {
+	ctx, span := startSpan(ctx, "CompleteOIDCLogin")
+	v, mfaToken, err := t.next.CompleteOIDCLogin(ctx, code, codeVerifier, nonce)
+	endSpan(span, err)
+	return v, mfaToken, err
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+	"job-portal-api/internal/models"
+)
+
+func TestWithTracing(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	ctrl := gomock.NewController(t)
+	mock := NewMockService(ctrl)
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+
+	// The wrapped service gets the context of the new span
+	mock.EXPECT().JobsByID(gomock.Any(), uint64(1), "2").Times(1).
+		DoAndReturn(func(ctx context.Context, jobID uint64, userId string) (models.Job, error) {
+			require.NotEqual(t, parent.SpanContext().SpanID(), oteltrace.SpanContextFromContext(ctx).SpanID())
+			return models.Job{}, errJobNotFound
+		})
+	mock.EXPECT().ExpireJobs(gomock.Any()).Times(1).Return(int64(0), errors.New("connection refused"))
+
+	s := WithTracing(mock)
+	_, err := s.JobsByID(ctx, 1, "2")
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = s.ExpireJobs(ctx)
+	require.Error(t, err)
+	parent.End()
+
+	spans := rec.Ended()
+	require.Len(t, spans, 3)
+	require.Equal(t, "services.JobsByID", spans[0].Name())
+	require.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	// A domain error is the caller's fault and doesn't fail the span, other errors do
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+	require.Len(t, spans[0].Events(), 1)
+	require.Equal(t, "services.ExpireJobs", spans[1].Name())
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+}
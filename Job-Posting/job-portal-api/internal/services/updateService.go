@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"job-portal-api/internal/models"
+	"job-portal-api/internal/repository"
+)
+
+// UpdateCompany changes the fields of the company present in uc. Only the owner of the company or an admin may
+// change it.
+func (s *Store) UpdateCompany(ctx context.Context, companyID uint, uc models.UpdateCompany, userID string) (models.Companies, error) {
+	companies, err := s.UserRepo.ViewCompanyById(ctx, companyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Companies{}, errCompanyNotFound
+	}
+	if err != nil {
+		return models.Companies{}, err
+	}
+	err = s.checkCompanyOwner(ctx, companyID, userID)
+	if err != nil {
+		return models.Companies{}, err
+	}
+
+	company := companies[0]
+	var fields []string
+	if uc.CompanyName != nil {
+		company.CompanyName = *uc.CompanyName
+		fields = append(fields, "CompanyName")
+	}
+	if uc.FoundedYear != nil {
+		company.FoundedYear = *uc.FoundedYear
+		fields = append(fields, "FoundedYear")
+	}
+	if uc.Location != nil {
+		company.Location = *uc.Location
+		fields = append(fields, "Location")
+	}
+	if uc.Address != nil {
+		company.Address = *uc.Address
+		fields = append(fields, "Address")
+	}
+	if len(fields) == 0 {
+		return company, nil
+	}
+
+	return s.UserRepo.UpdateCompany(ctx, company, fields)
+}
+
+// DeleteCompany soft deletes the company and its jobs. Only the owner of the company or an admin may delete it.
+func (s *Store) DeleteCompany(ctx context.Context, companyID uint, userID string) error {
+	err := s.checkCompanyOwner(ctx, companyID, userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.UserRepo.DeleteCompany(ctx, companyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errCompanyNotFound
+	}
+	return err
+}
+
+// RestoreCompany brings back a deleted company together with the jobs deleted with it.
+func (s *Store) RestoreCompany(ctx context.Context, companyID uint) (models.Companies, error) {
+	company, err := s.UserRepo.RestoreCompany(ctx, companyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Companies{}, newError(ErrNotFound, "deleted company not found")
+	}
+	if err != nil {
+		return models.Companies{}, err
+	}
+	return company, nil
+}
+
+// UpdateJob changes the fields of the job present in uj. Only the owner of the company that posted the job or an
+// admin may change it. Changing the application deadline of a published job also moves its expiry date.
+func (s *Store) UpdateJob(ctx context.Context, jobID uint64, uj models.UpdateJob, userID string) (models.Job, error) {
+	job, err := s.jobForOwner(ctx, jobID, userID)
+	if err != nil {
+		return models.Job{}, err
+	}
+
+	var fields []string
+	if uj.Title != nil {
+		job.Title = *uj.Title
+		fields = append(fields, "Title")
+	}
+	if uj.Description != nil {
+		job.Description = *uj.Description
+		fields = append(fields, "Description")
+	}
+	if uj.Location != nil {
+		job.Location = *uj.Location
+		fields = append(fields, "Location")
+	}
+	if uj.WorkMode != nil {
+		job.WorkMode = *uj.WorkMode
+		fields = append(fields, "WorkMode")
+	}
+	if uj.EmploymentType != nil {
+		job.EmploymentType = *uj.EmploymentType
+		fields = append(fields, "EmploymentType")
+	}
+	if uj.Seniority != nil {
+		job.Seniority = *uj.Seniority
+		fields = append(fields, "Seniority")
+	}
+	if uj.SalaryMin != nil {
+		job.SalaryMin = *uj.SalaryMin
+		fields = append(fields, "SalaryMin")
+	}
+	if uj.SalaryMax != nil {
+		job.SalaryMax = *uj.SalaryMax
+		fields = append(fields, "SalaryMax")
+	}
+	if uj.SalaryCurrency != nil {
+		job.SalaryCurrency = *uj.SalaryCurrency
+		fields = append(fields, "SalaryCurrency")
+	}
+	if uj.Skills != nil {
+		job.Skills = uj.Skills
+		fields = append(fields, "Skills")
+	}
+	if uj.ApplicationDeadline != nil {
+		job.ApplicationDeadline = uj.ApplicationDeadline
+		fields = append(fields, "ApplicationDeadline")
+		if job.Status == models.JobStatusPublished {
+			job.ExpiresAt = uj.ApplicationDeadline
+			fields = append(fields, "ExpiresAt")
+		}
+	}
+	if len(fields) == 0 {
+		return job, nil
+	}
+
+	// The salary range is checked on the merged job, a request may change only one end of it.
+	if job.SalaryMax != 0 && job.SalaryMax < job.SalaryMin {
+		return models.Job{}, newError(ErrInvalidInput, "salary_max must not be less than salary_min")
+	}
+	if (job.SalaryMin != 0 || job.SalaryMax != 0) && job.SalaryCurrency == "" {
+		return models.Job{}, newError(ErrInvalidInput, "salary_currency is required with a salary")
+	}
+	if uj.ApplicationDeadline != nil && !uj.ApplicationDeadline.After(time.Now()) {
+		return models.Job{}, newError(ErrInvalidInput, "application_deadline must be in the future")
+	}
+
+	return s.UserRepo.UpdateJob(ctx, job, fields)
+}
+
+// DeleteJob soft deletes the job. Only the owner of the company that posted the job or an admin may delete it.
+func (s *Store) DeleteJob(ctx context.Context, jobID uint64, userID string) error {
+	job, err := s.jobForOwner(ctx, jobID, userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.UserRepo.DeleteJob(ctx, job.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errJobNotFound
+	}
+	return err
+}
+
+// RestoreJob brings back a deleted job. A job can't be restored while its company is deleted.
+func (s *Store) RestoreJob(ctx context.Context, jobID uint64) (models.Job, error) {
+	job, err := s.UserRepo.RestoreJob(ctx, uint(jobID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Job{}, newError(ErrNotFound, "deleted job not found")
+	}
+	if errors.Is(err, repository.ErrCompanyDeleted) {
+		return models.Job{}, newError(ErrConflict, "the company of the job is deleted, restore the company first")
+	}
+	if err != nil {
+		return models.Job{}, err
+	}
+	return job, nil
+}
@@ -2,11 +2,16 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"job-portal-api/internal/auth"
 	"job-portal-api/internal/models"
+	"strconv"
+	"time"
 
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // Conn is our main struct, including the database instance for working with data.
@@ -25,26 +30,103 @@ func (s *Store) CreateUser(ctx context.Context, nu models.NewUser) (models.User,
 		Name:         nu.Name,
 		Email:        nu.Email,
 		PasswordHash: string(hashedPass),
+		// Everyone registers as a candidate, an admin makes recruiters with SetUserRole
+		Role: models.RoleCandidate,
 	}
 
 	// We attempt to create the new User record in the database.
 	user, err := s.UserRepo.CreateUser(ctx, u)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.User{}, newError(ErrConflict, "a user with this name or email already exists")
+	}
 	if err != nil {
 		return models.User{}, err
 
 	}
+
+	// The account exists either way, a user whose email didn't go out can ask for another one
+	err = s.sendVerificationEmail(ctx, user)
+	if err != nil {
+		log.Error().Err(err).Uint("user id", user.ID).Msg("sending verification email")
+	}
 	return user, nil
 }
 
 // Authenticate is a method that checks a user's provided email and password against the database.
-func (s *Store) Authenticate(ctx context.Context, email, password string) (jwt.RegisteredClaims,
+// When the user has two-factor authentication, no claims are returned but an mfa token to pass to VerifyMFA.
+func (s *Store) Authenticate(ctx context.Context, email, password string) (auth.Claims, string,
 	error) {
 
 	// We attempt to find the User record where the email
 	// matches the provided email.
-	claims, err := s.UserRepo.CheckEmail(ctx, email, password)
+	u, err := s.UserRepo.ViewUserByEmail(ctx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Spend as long as on a wrong password, so the response time doesn't tell which emails are registered
+		_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return auth.Claims{}, "", errInvalidCredentials
+	}
+	if err != nil {
+		return auth.Claims{}, "", err
+	}
+
+	// A locked account is refused before the password is even checked, so guessing gets no feedback
+	now := time.Now()
+	if u.LockedUntil != nil && u.LockedUntil.After(now) {
+		return auth.Claims{}, "", accountLocked(u.LockedUntil.Sub(now))
+	}
+
+	// Accounts created by single sign-on have no password until the user resets it
+	if u.PasswordHash == "" {
+		_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return auth.Claims{}, "", errInvalidCredentials
+	}
+
+	// We check if the provided password matches the hashed password in the database.
+	err = bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		_, err = s.UserRepo.RecordFailedLogin(ctx, u.ID, func(failures int) time.Time {
+			return s.Lockout.LockUntil(now, failures)
+		})
+		if err != nil {
+			return auth.Claims{}, "", err
+		}
+		return auth.Claims{}, "", errInvalidCredentials
+	}
+	if err != nil {
+		return auth.Claims{}, "", fmt.Errorf("comparing password hash: %w", err)
+	}
+
+	// The failed logins are only reset once the code is right as well, or knowing the password would allow
+	// guessing codes without ever being locked out
+	if u.TOTPEnabledAt != nil {
+		mfaToken, err := s.issueMFAChallenge(u, now)
+		if err != nil {
+			return auth.Claims{}, "", err
+		}
+		return auth.Claims{}, mfaToken, nil
+	}
+
+	if u.FailedLogins > 0 || u.LockedUntil != nil {
+		err = s.UserRepo.ResetFailedLogins(ctx, u.ID)
+		if err != nil {
+			return auth.Claims{}, "", err
+		}
+	}
+	return auth.NewClaims(strconv.FormatUint(uint64(u.ID), 10), u.Role), "", nil
+}
+
+// SetUserRole makes the user a candidate or a recruiter. The role of an admin can't be changed this way. Tokens
+// issued before keep the old role until they expire.
+func (s *Store) SetUserRole(ctx context.Context, userID uint, ur models.UserRole) (models.User, error) {
+	u, err := s.UserRepo.ViewUserById(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.User{}, errUserNotFound
+	}
 	if err != nil {
-		return jwt.RegisteredClaims{}, err
+		return models.User{}, err
+	}
+	if u.Role == models.RoleAdmin {
+		return models.User{}, errAdminRole
 	}
-	return claims, nil
+	return s.UserRepo.UpdateUserRole(ctx, u.ID, ur.Role)
 }
@@ -0,0 +1,110 @@
+// Package totp implements the time-based one-time passwords of RFC 6238, as generated by authenticator apps: six
+// digit HMAC-SHA1 codes that change every thirty seconds.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parameters of the codes. Authenticator apps assume these when the provisioning URI doesn't say otherwise.
+const (
+	Digits = 6
+	Period = 30 * time.Second
+)
+
+// secretSize is the length of a secret in bytes, the 160 bits RFC 4226 recommends for HMAC-SHA1.
+const secretSize = 20
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret returns a random secret, base32 encoded as authenticator apps expect it.
+func NewSecret() (string, error) {
+	b := make([]byte, secretSize)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("generating totp secret: %w", err)
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// Step returns the number of the time step t falls in. Codes are derived from the step, so two codes of the same
+// step are the same code.
+func Step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// Code returns the code of secret for the given time step.
+func Code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("decoding totp secret: %w", err)
+	}
+	return code(key, step, Digits), nil
+}
+
+// Validate reports whether totpCode is the code of secret at t, or of one of the skew steps before or after it to
+// allow for clocks that drift apart. It returns the step that matched, which callers should remember to refuse
+// the same code a second time.
+func Validate(secret string, totpCode string, t time.Time, skew int) (int64, bool) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(totpCode) != Digits {
+		return 0, false
+	}
+
+	// The current step is the likeliest, then the ones closest to it
+	now := Step(t)
+	steps := []int64{now}
+	for i := int64(1); i <= int64(skew); i++ {
+		steps = append(steps, now-i, now+i)
+	}
+	for _, step := range steps {
+		if subtle.ConstantTimeCompare([]byte(code(key, step, Digits)), []byte(totpCode)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// ProvisioningURI returns the otpauth URI that enrols secret in an authenticator app, usually shown as a QR code.
+// The account, typically the email of the user, is displayed under issuer in the app.
+func ProvisioningURI(issuer string, account string, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprint(Digits))
+	v.Set("period", fmt.Sprint(int(Period/time.Second)))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: v.Encode(),
+	}
+	return u.String()
+}
+
+// code is the HOTP value of RFC 4226 for the counter step, truncated to the given number of digits.
+func code(key []byte, step int64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	h := hmac.New(sha1.New, key)
+	h.Write(msg[:])
+	sum := h.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	v := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, v%mod)
+}
@@ -0,0 +1,76 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The SHA1 test vectors of RFC 6238, appendix B.
+func TestCodeRFC6238(t *testing.T) {
+	key := []byte("12345678901234567890")
+	tt := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tc := range tt {
+		require.Equal(t, tc.code, code(key, Step(time.Unix(tc.unix, 0)), 8), "at %d", tc.unix)
+	}
+
+	// With six digits the code is the end of the eight digit one
+	secret := base32.StdEncoding.EncodeToString(key)
+	c, err := Code(secret, Step(time.Unix(59, 0)))
+	require.NoError(t, err)
+	require.Equal(t, "287082", c)
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+
+	current, err := Code(secret, Step(now))
+	require.NoError(t, err)
+	step, ok := Validate(secret, current, now, 1)
+	require.True(t, ok)
+	require.Equal(t, Step(now), step)
+
+	// A code of the previous step is accepted within the skew, not beyond it
+	previous, err := Code(secret, Step(now)-1)
+	require.NoError(t, err)
+	step, ok = Validate(secret, previous, now, 1)
+	require.True(t, ok)
+	require.Equal(t, Step(now)-1, step)
+	_, ok = Validate(secret, previous, now, 0)
+	require.False(t, ok)
+
+	_, ok = Validate(secret, "000000", now.Add(time.Hour), 1)
+	require.False(t, ok)
+	_, ok = Validate(secret, current[:5], now, 1)
+	require.False(t, ok)
+	_, ok = Validate("not base32!", current, now, 1)
+	require.False(t, ok)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("Job Portal", "stym@email.com", "JBSWY3DPEHPK3PXP")
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", u.Scheme)
+	require.Equal(t, "totp", u.Host)
+	require.Equal(t, "/Job Portal:stym@email.com", u.Path)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", u.Query().Get("secret"))
+	require.Equal(t, "Job Portal", u.Query().Get("issuer"))
+	require.Equal(t, "6", u.Query().Get("digits"))
+	require.Equal(t, "30", u.Query().Get("period"))
+}
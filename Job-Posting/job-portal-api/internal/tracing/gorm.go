@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	tracerName = "job-portal-api/internal/tracing"
+	spanKey    = "tracing:span"
+)
+
+// GormPlugin records a span for every statement GORM runs. The span is a child of the span in the context passed
+// to WithContext, so queries show up under the request that made them.
+type GormPlugin struct{}
+
+// Name implements gorm.Plugin.
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize implements gorm.Plugin by registering callbacks around the create, query, update, delete, row and
+// raw processors.
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("tracing:before_create", startSpan("gorm.create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", endSpan),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", startSpan("gorm.query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", endSpan),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", startSpan("gorm.update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", endSpan),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", startSpan("gorm.delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", endSpan),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", startSpan("gorm.row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", endSpan),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", startSpan("gorm.raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", endSpan),
+	)
+}
+
+func startSpan(name string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		_, span := otel.Tracer(tracerName).Start(db.Statement.Context, name, trace.WithSpanKind(trace.SpanKindClient))
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func endSpan(db *gorm.DB) {
+	v, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := v.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBSystemKey.String(db.Dialector.Name()),
+		semconv.DBStatement(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBSQLTable(db.Statement.Table))
+	}
+	// A missing record is an answer, not a failure of the query
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
@@ -0,0 +1,59 @@
+// Package tracing sets up OpenTelemetry tracing for the API: the tracer provider with its exporter, and W3C trace
+// context propagation so that traces started by a gateway continue through the API.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"job-portal-api/internal/config"
+)
+
+// Setup installs the global tracer provider and propagator described by cfg. The returned function flushes the
+// spans that haven't been exported yet and stops the provider, it must be called before the process exits.
+func Setup(ctx context.Context, cfg config.Tracing) (shutdown func(context.Context) error, err error) {
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName))),
+		// Follow the sampling decision of the caller and sample new traces at the configured ratio
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tp.Shutdown, nil
+}
+
+// newExporter returns the exporter selected by cfg.Exporter, or nil when spans are not exported.
+func newExporter(ctx context.Context, cfg config.Tracing) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case config.ExporterNone, "":
+		return nil, nil
+	case config.ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case config.ExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+}
@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"gorm.io/gorm"
+	"job-portal-api/internal/config"
+)
+
+// useRecorder installs a tracer provider that keeps every span in memory until the test ends.
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return rec
+}
+
+func TestSetup(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	for _, exporter := range []string{config.ExporterNone, config.ExporterStdout} {
+		t.Run(exporter, func(t *testing.T) {
+			cfg := config.Default().Tracing
+			cfg.Exporter = exporter
+			shutdown, err := Setup(context.Background(), cfg)
+			require.NoError(t, err)
+			require.NoError(t, shutdown(context.Background()))
+		})
+	}
+
+	cfg := config.Default().Tracing
+	cfg.Exporter = "jaeger"
+	_, err := Setup(context.Background(), cfg)
+	require.Error(t, err)
+}
+
+func TestGormPlugin(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(GormPlugin{}))
+
+	type note struct {
+		ID   uint
+		Body string
+	}
+	require.NoError(t, db.AutoMigrate(&note{}))
+	rec := useRecorder(t)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	require.NoError(t, db.WithContext(ctx).Create(&note{Body: "hello"}).Error)
+	var n note
+	require.NoError(t, db.WithContext(ctx).First(&n, 1).Error)
+	// A missing record doesn't mark the span as failed
+	require.ErrorIs(t, db.WithContext(ctx).First(&n, 2).Error, gorm.ErrRecordNotFound)
+	require.Error(t, db.WithContext(ctx).Exec("SELECT * FROM missing").Error)
+	parent.End()
+
+	spans := rec.Ended()
+	require.Len(t, spans, 5)
+	names := []string{"gorm.create", "gorm.query", "gorm.query", "gorm.raw", "request"}
+	for i, s := range spans {
+		require.Equal(t, names[i], s.Name())
+		if s.Name() != "request" {
+			require.Equal(t, parent.SpanContext().SpanID(), s.Parent().SpanID())
+		}
+	}
+	require.Equal(t, codes.Unset, spans[2].Status().Code)
+	require.Equal(t, codes.Error, spans[3].Status().Code)
+	require.Contains(t, spans[0].Attributes(), semconv.DBSystemKey.String("sqlite"))
+	require.Contains(t, spans[0].Attributes(), semconv.DBSQLTable("notes"))
+}
@@ -0,0 +1,230 @@
+// Package validate checks request bodies and query strings against their validate struct tags and reports every
+// failing field in a form the client can act on.
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Limits of the custom rules.
+const (
+	// MinPasswordLength is the shortest password the password rule accepts.
+	MinPasswordLength = 8
+	// MaxPasswordBytes is the longest password the password rule accepts. bcrypt ignores everything past it.
+	MaxPasswordBytes = 72
+	// MinFoundedYear is the earliest year the founded_year rule accepts.
+	MinFoundedYear = 1800
+)
+
+// v is shared by every request. validator caches the parsed struct tags, so building it once is much cheaper than
+// building it per request, and it is safe for concurrent use.
+var v = newValidator()
+
+func newValidator() *validator.Validate {
+	val := validator.New()
+
+	// Name fields the way the client sees them: by their JSON key, or by their query parameter for query strings.
+	val.RegisterTagNameFunc(func(f reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return f.Name
+	})
+
+	// The rules below only fail to register on a bad tag name, which is a programming error.
+	must(val.RegisterValidation("password", isStrongPassword))
+	must(val.RegisterValidation("founded_year", isFoundedYear))
+	must(val.RegisterValidation("httpurl", isHTTPURL))
+	return val
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// isStrongPassword accepts passwords of at least MinPasswordLength characters and at most MaxPasswordBytes bytes
+// that mix upper case letters, lower case letters and digits.
+func isStrongPassword(fl validator.FieldLevel) bool {
+	p := fl.Field().String()
+	if len([]rune(p)) < MinPasswordLength || len(p) > MaxPasswordBytes {
+		return false
+	}
+	var upper, lower, digit bool
+	for _, r := range p {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+// isFoundedYear accepts years from MinFoundedYear up to the current year.
+func isFoundedYear(fl validator.FieldLevel) bool {
+	y := fl.Field().Int()
+	return y >= MinFoundedYear && y <= int64(time.Now().Year())
+}
+
+// isHTTPURL accepts absolute http and https URLs with a host. The url rule of validator also accepts schemes such
+// as javascript: and file:, which must never end up in a link shown to a recruiter.
+func isHTTPURL(fl validator.FieldLevel) bool {
+	u, err := url.Parse(fl.Field().String())
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// FieldError describes one field that failed validation. Field is the path of the field as the client sent it,
+// Rule is the name of the rule it broke and Message says in words what a valid value looks like.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Errors is the list of fields that failed validation, in the order they appear in the struct.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Field+": "+fe.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Struct validates s against its validate tags. When fields fail it returns Errors listing all of them. Any other
+// error means s can't be validated at all, for example because it is not a struct.
+func Struct(s any) error {
+	err := v.Struct(s)
+	if err == nil {
+		return nil
+	}
+	var ve validator.ValidationErrors
+	if !errors.As(err, &ve) {
+		return err
+	}
+
+	errs := make(Errors, 0, len(ve))
+	for _, fe := range ve {
+		errs = append(errs, FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Message: message(fe),
+		})
+	}
+	return errs
+}
+
+// fieldPath returns the namespace of the field without the name of the top level struct, so a failing skill reads
+// skills[2] rather than NewJob.skills[2].
+func fieldPath(fe validator.FieldError) string {
+	ns := fe.Namespace()
+	if _, rest, ok := strings.Cut(ns, "."); ok {
+		return rest
+	}
+	return ns
+}
+
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_with":
+		return "is required when " + fieldList(fe.Param()) + " is set"
+	case "email":
+		return "must be a valid email address"
+	case "url", "httpurl":
+		return "must be an absolute http or https URL"
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "iso4217":
+		return "must be an ISO 4217 currency code"
+	case "gtefield":
+		return "must be greater than or equal to " + fieldList(fe.Param())
+	case "min", "max", "gte", "gt", "lte", "lt":
+		return bound(fe)
+	case "password":
+		return fmt.Sprintf("must be %d to %d characters long and contain an upper case letter, a lower case letter "+
+			"and a digit", MinPasswordLength, MaxPasswordBytes)
+	case "founded_year":
+		return fmt.Sprintf("must be a year between %d and %d", MinFoundedYear, time.Now().Year())
+	default:
+		return "is invalid"
+	}
+}
+
+// bound describes a size rule. On strings and lists the rule limits the length, on numbers the value itself.
+func bound(fe validator.FieldError) string {
+	var op string
+	switch fe.Tag() {
+	case "min", "gte":
+		op = "at least"
+	case "max", "lte":
+		op = "at most"
+	case "gt":
+		op = "more than"
+	case "lt":
+		op = "less than"
+	}
+
+	switch fe.Kind() {
+	case reflect.String:
+		if fe.Param() == "1" {
+			return fmt.Sprintf("must be %s 1 character long", op)
+		}
+		return fmt.Sprintf("must be %s %s characters long", op, fe.Param())
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if fe.Param() == "1" {
+			return fmt.Sprintf("must contain %s 1 item", op)
+		}
+		return fmt.Sprintf("must contain %s %s items", op, fe.Param())
+	default:
+		return fmt.Sprintf("must be %s %s", op, fe.Param())
+	}
+}
+
+// fieldList turns the struct field names a cross field rule refers to into their snake case JSON names, which is
+// how every request struct in this API names its fields.
+func fieldList(param string) string {
+	names := strings.Fields(param)
+	for i, n := range names {
+		names[i] = snakeCase(n)
+	}
+	return strings.Join(names, " or ")
+}
+
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
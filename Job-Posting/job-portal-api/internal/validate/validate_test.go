@@ -0,0 +1,81 @@
+package validate
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStruct(t *testing.T) {
+	type item struct {
+		Name string `json:"name" validate:"required"`
+	}
+	type request struct {
+		Password    string   `json:"password" validate:"required,password"`
+		FoundedYear int      `json:"founded_year" validate:"omitempty,founded_year"`
+		Website     string   `json:"website" validate:"omitempty,httpurl"`
+		Tags        []string `json:"tags" validate:"dive,min=2"`
+		Items       []item   `json:"items" validate:"dive"`
+		Query       string   `form:"q" validate:"max=3"`
+	}
+
+	tt := []struct {
+		name     string
+		req      request
+		expected Errors
+	}{
+		{
+			name: "OK",
+			req:  request{Password: "Passw0rd", FoundedYear: 1981, Website: "https://example.com"},
+		},
+		{
+			name: "Fail_WeakPassword",
+			req:  request{Password: "password1"},
+			expected: Errors{{Field: "password", Rule: "password",
+				Message: "must be 8 to 72 characters long and contain an upper case letter, a lower case letter and a digit"}},
+		},
+		{
+			name:     "Fail_FoundedYearInFuture",
+			req:      request{Password: "Passw0rd", FoundedYear: 3000},
+			expected: Errors{{Field: "founded_year", Rule: "founded_year", Message: "must be a year between 1800 and " + currentYear()}},
+		},
+		{
+			name:     "Fail_URLScheme",
+			req:      request{Password: "Passw0rd", Website: "javascript:alert(1)"},
+			expected: Errors{{Field: "website", Rule: "httpurl", Message: "must be an absolute http or https URL"}},
+		},
+		{
+			name: "Fail_NestedFields",
+			req:  request{Password: "Passw0rd", Tags: []string{"go", "c"}, Items: []item{{}}, Query: "abcd"},
+			expected: Errors{
+				{Field: "tags[1]", Rule: "min", Message: "must be at least 2 characters long"},
+				{Field: "items[0].name", Rule: "required", Message: "is required"},
+				{Field: "q", Rule: "max", Message: "must be at most 3 characters long"},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Struct(tc.req)
+			if tc.expected == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.Equal(t, tc.expected, err)
+		})
+	}
+}
+
+func TestStructNotAStruct(t *testing.T) {
+	err := Struct("not a struct")
+	require.Error(t, err)
+	_, ok := err.(Errors)
+	require.False(t, ok)
+}
+
+func currentYear() string {
+	return strconv.Itoa(time.Now().Year())
+}